@@ -0,0 +1,319 @@
+package cachemount
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/containerd/containerd/archive"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/remotes"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/util/convert"
+	"github.com/tonistiigi/buildkit_poc/util/pgzip"
+)
+
+// IDAnnotation is set on the layers of an exported image to the ID of the
+// cache mount they contain
+const IDAnnotation = "buildkit.cachemount.id"
+
+var errNotFound = errors.New("not found")
+
+type Opt struct {
+	CacheAccessor cache.Accessor
+	// ContentStore and Resolver are required for registry references
+	ContentStore content.Store
+	Resolver     remotes.Resolver
+}
+
+// IsLocal returns true if dest is a local directory, other destinations are
+// registry references
+func IsLocal(dest string) bool {
+	return filepath.IsAbs(dest)
+}
+
+// Export writes the contents of the cache mounts ids to dest. A directory
+// gets a tar archive for every cache mount, a registry reference an image
+// with a layer for every cache mount.
+func Export(ctx context.Context, opt Opt, ids []string, dest string) error {
+	if IsLocal(dest) {
+		return exportLocal(ctx, opt, ids, dest)
+	}
+	return exportRegistry(ctx, opt, ids, dest)
+}
+
+// Import seeds the empty cache mounts ids from src. Cache mounts that
+// already have contents are not changed. The cache mounts that are missing
+// in src or fail to import are left empty and returned with their error.
+func Import(ctx context.Context, opt Opt, ids []string, src string) map[string]error {
+	if IsLocal(src) {
+		return importLocal(ctx, opt, ids, src)
+	}
+	return importRegistry(ctx, opt, ids, src)
+}
+
+func exportLocal(ctx context.Context, opt Opt, ids []string, dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrapf(err, "failed to create %s", dir)
+	}
+	for _, id := range ids {
+		if err := withMount(opt, id, func(root string) error {
+			f, err := ioutil.TempFile(dir, ".export-")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(f.Name())
+			err = archive.WriteDiff(ctx, f, "", root)
+			if err1 := f.Close(); err == nil {
+				err = err1
+			}
+			if err != nil {
+				return err
+			}
+			return os.Rename(f.Name(), filepath.Join(dir, fileName(id)))
+		}); err != nil {
+			return errors.Wrapf(err, "failed to export cache mount %s", id)
+		}
+	}
+	return nil
+}
+
+func importLocal(ctx context.Context, opt Opt, ids []string, dir string) map[string]error {
+	errs := map[string]error{}
+	for _, id := range ids {
+		f, err := os.Open(filepath.Join(dir, fileName(id)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				err = errors.Wrapf(errNotFound, "%s not found in %s", id, dir)
+			}
+			errs[id] = err
+			continue
+		}
+		if err := seed(ctx, opt, id, f); err != nil {
+			errs[id] = err
+		}
+		f.Close()
+	}
+	return errs
+}
+
+func exportRegistry(ctx context.Context, opt Opt, ids []string, ref string) error {
+	if opt.ContentStore == nil || opt.Resolver == nil {
+		return errors.New("exporting cache mounts to a registry requires a content store")
+	}
+	var (
+		layers  []ocispec.Descriptor
+		diffIDs []digest.Digest
+	)
+	for _, id := range ids {
+		if err := withMount(opt, id, func(root string) error {
+			desc, diffID, err := writeLayer(ctx, opt.ContentStore, root)
+			if err != nil {
+				return err
+			}
+			desc.Annotations = map[string]string{IDAnnotation: id}
+			layers = append(layers, desc)
+			diffIDs = append(diffIDs, diffID)
+			return nil
+		}); err != nil {
+			return errors.Wrapf(err, "failed to export cache mount %s", id)
+		}
+	}
+
+	configDesc, err := writeJSON(ctx, opt.ContentStore, ocispec.MediaTypeImageConfig, ocispec.Image{
+		Architecture: runtime.GOARCH,
+		OS:           "linux",
+		RootFS:       ocispec.RootFS{Type: "layers", DiffIDs: diffIDs},
+	})
+	if err != nil {
+		return err
+	}
+	desc, err := writeJSON(ctx, opt.ContentStore, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    configDesc,
+		Layers:    layers,
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(convert.Push(ctx, opt.ContentStore, opt.Resolver, ref, desc), "failed to push %s", ref)
+}
+
+func importRegistry(ctx context.Context, opt Opt, ids []string, ref string) map[string]error {
+	errs := map[string]error{}
+	fail := func(err error) map[string]error {
+		for _, id := range ids {
+			errs[id] = err
+		}
+		return errs
+	}
+	if opt.ContentStore == nil || opt.Resolver == nil {
+		return fail(errors.New("importing cache mounts from a registry requires a content store"))
+	}
+	desc, err := convert.Fetch(ctx, opt.ContentStore, opt.Resolver, ref)
+	if err != nil {
+		return fail(errors.Wrapf(err, "failed to fetch %s", ref))
+	}
+	dt, err := content.ReadBlob(ctx, opt.ContentStore, desc.Digest)
+	if err != nil {
+		return fail(errors.Wrapf(err, "failed to read manifest of %s", ref))
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(dt, &manifest); err != nil {
+		return fail(errors.Wrapf(err, "failed to parse manifest of %s", ref))
+	}
+	layers := map[string]ocispec.Descriptor{}
+	for _, l := range manifest.Layers {
+		if id, ok := l.Annotations[IDAnnotation]; ok {
+			layers[id] = l
+		}
+	}
+
+	for _, id := range ids {
+		l, ok := layers[id]
+		if !ok {
+			errs[id] = errors.Wrapf(errNotFound, "%s not found in %s", id, ref)
+			continue
+		}
+		if err := seedLayer(ctx, opt, id, l); err != nil {
+			errs[id] = err
+		}
+	}
+	return errs
+}
+
+func seedLayer(ctx context.Context, opt Opt, id string, desc ocispec.Descriptor) error {
+	rc, err := opt.ContentStore.Reader(ctx, desc.Digest)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	zr, err := gzip.NewReader(rc)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decompress %s", desc.Digest)
+	}
+	defer zr.Close()
+	return seed(ctx, opt, id, zr)
+}
+
+// seed extracts the tar stream r into the cache mount id if it is empty. A
+// failed extraction is removed again so that the cache mount doesn't keep
+// partial contents.
+func seed(ctx context.Context, opt Opt, id string, r io.Reader) error {
+	return withMount(opt, id, func(root string) error {
+		fis, err := ioutil.ReadDir(root)
+		if err != nil {
+			return err
+		}
+		if len(fis) > 0 {
+			return nil
+		}
+		if _, err := archive.Apply(ctx, root, r); err != nil {
+			if err1 := removeContents(root); err1 != nil {
+				return err1
+			}
+			return errors.Wrapf(err, "failed to extract %s", id)
+		}
+		return nil
+	})
+}
+
+func removeContents(root string) error {
+	fis, err := ioutil.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if err := os.RemoveAll(filepath.Join(root, fi.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withMount mounts the cache mount id and calls f with its directory
+func withMount(opt Opt, id string, f func(root string) error) error {
+	ref, err := opt.CacheAccessor.GetCacheMount(id)
+	if err != nil {
+		return err
+	}
+	defer ref.Release()
+	m, err := ref.Mount()
+	if err != nil {
+		return err
+	}
+	lm := snapshot.LocalMounter(m)
+	root, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+	return f(root)
+}
+
+// writeLayer writes the contents of root as a gzip layer to the content
+// store and returns its descriptor and uncompressed digest
+func writeLayer(ctx context.Context, cs content.Store, root string) (ocispec.Descriptor, digest.Digest, error) {
+	cw, err := cs.Writer(ctx, "cachemount-"+filepath.Base(root), 0, "")
+	if err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrap(err, "failed to open writer")
+	}
+	defer cw.Close()
+
+	digester := digest.Canonical.Digester()
+	zw := pgzip.NewWriter(cw)
+	if err := archive.WriteDiff(ctx, io.MultiWriter(zw, digester.Hash()), "", root); err != nil {
+		zw.Close()
+		return ocispec.Descriptor{}, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrap(err, "failed to compress layer")
+	}
+	dgst := cw.Digest()
+	if err := cw.Commit(0, dgst); err != nil && !content.IsExists(err) {
+		return ocispec.Descriptor{}, "", errors.Wrap(err, "failed to commit")
+	}
+	info, err := cs.Info(ctx, dgst)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	return ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerGzip,
+		Digest:    dgst,
+		Size:      info.Size,
+	}, digester.Digest(), nil
+}
+
+func writeJSON(ctx context.Context, cs content.Store, mediaType string, v interface{}) (ocispec.Descriptor, error) {
+	dt, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	if err := content.WriteBlob(ctx, cs, "cachemount-"+desc.Digest.String(), bytes.NewReader(dt), desc.Size, desc.Digest); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to write %s", desc.Digest)
+	}
+	return desc, nil
+}
+
+// fileName returns the name of the archive of the cache mount id in a
+// directory
+func fileName(id string) string {
+	return url.PathEscape(id) + ".tar"
+}
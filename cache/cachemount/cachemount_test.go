@@ -0,0 +1,146 @@
+package cachemount
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/snapshot/naive"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/cache"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "cachemount")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	opt := Opt{CacheAccessor: newManager(t, filepath.Join(tmpdir, "a"))}
+	writeFile(t, opt, "go", "foo", "bar")
+	writeFile(t, opt, "npm", "foo", "baz")
+
+	dir := filepath.Join(tmpdir, "export")
+	assert.NoError(t, Export(ctx, opt, []string{"go", "npm"}, dir))
+
+	// corrupt archives and missing cache mounts leave the cache mount empty
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, fileName("npm")), []byte("invalid"), 0600))
+	opt2 := Opt{CacheAccessor: newManager(t, filepath.Join(tmpdir, "b"))}
+	errs := Import(ctx, opt2, []string{"go", "npm", "missing"}, dir)
+	assert.Equal(t, 2, len(errs))
+	assert.Error(t, errs["npm"])
+	assert.Equal(t, errNotFound, errors.Cause(errs["missing"]))
+	assert.Equal(t, "bar", readFile(t, opt2, "go", "foo"))
+	assert.Equal(t, "", readFile(t, opt2, "npm", "foo"))
+
+	// cache mounts with contents are not replaced
+	writeFile(t, opt2, "go", "foo", "new")
+	errs = Import(ctx, opt2, []string{"go"}, dir)
+	assert.Equal(t, 0, len(errs))
+	assert.Equal(t, "new", readFile(t, opt2, "go", "foo"))
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "cachemount")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	registry, err := content.NewStore(filepath.Join(tmpdir, "registry"))
+	assert.NoError(t, err)
+	r := &testResolver{cs: registry, refs: map[string]ocispec.Descriptor{}}
+
+	opt := Opt{CacheAccessor: newManager(t, filepath.Join(tmpdir, "a")), Resolver: r}
+	opt.ContentStore, err = content.NewStore(filepath.Join(tmpdir, "content-a"))
+	assert.NoError(t, err)
+	writeFile(t, opt, "go", "foo", "bar")
+	assert.NoError(t, Export(ctx, opt, []string{"go"}, "cache"))
+
+	opt2 := Opt{CacheAccessor: newManager(t, filepath.Join(tmpdir, "b")), Resolver: r}
+	opt2.ContentStore, err = content.NewStore(filepath.Join(tmpdir, "content-b"))
+	assert.NoError(t, err)
+	errs := Import(ctx, opt2, []string{"go", "missing"}, "cache")
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, errNotFound, errors.Cause(errs["missing"]))
+	assert.Equal(t, "bar", readFile(t, opt2, "go", "foo"))
+
+	errs = Import(ctx, opt2, []string{"go"}, "unknown")
+	assert.Equal(t, 1, len(errs))
+	assert.Error(t, errs["go"])
+}
+
+func newManager(t *testing.T, root string) cache.Manager {
+	snapshotter, err := naive.NewSnapshotter(filepath.Join(root, "snapshots"))
+	assert.NoError(t, err)
+	cm, err := cache.NewManager(cache.ManagerOpt{
+		Root:        root,
+		Snapshotter: snapshotter,
+	})
+	assert.NoError(t, err)
+	return cm
+}
+
+func writeFile(t *testing.T, opt Opt, id, name, data string) {
+	assert.NoError(t, withMount(opt, id, func(root string) error {
+		return ioutil.WriteFile(filepath.Join(root, name), []byte(data), 0600)
+	}))
+}
+
+func readFile(t *testing.T, opt Opt, id, name string) string {
+	var dt []byte
+	assert.NoError(t, withMount(opt, id, func(root string) error {
+		var err error
+		dt, err = ioutil.ReadFile(filepath.Join(root, name))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}))
+	return string(dt)
+}
+
+// testResolver is a registry backed by a content store
+type testResolver struct {
+	cs   content.Store
+	refs map[string]ocispec.Descriptor
+}
+
+func (r *testResolver) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	desc, ok := r.refs[ref]
+	if !ok {
+		return "", ocispec.Descriptor{}, errors.Errorf("%s not found", ref)
+	}
+	return ref, desc, nil
+}
+
+func (r *testResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		return r.cs.Reader(ctx, desc.Digest)
+	}), nil
+}
+
+func (r *testResolver) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return &testPusher{testResolver: r, ref: ref}, nil
+}
+
+type testPusher struct {
+	*testResolver
+	ref string
+}
+
+func (p *testPusher) Push(ctx context.Context, desc ocispec.Descriptor, rd io.Reader) error {
+	if err := content.WriteBlob(ctx, p.cs, desc.Digest.String(), rd, desc.Size, desc.Digest); err != nil {
+		return err
+	}
+	if desc.MediaType == ocispec.MediaTypeImageManifest {
+		p.refs[p.ref] = desc
+	}
+	return nil
+}
@@ -15,7 +15,11 @@ import (
 	"github.com/tonistiigi/buildkit_poc/snapshot"
 )
 
-const dbFile = "cache.db"
+const (
+	dbFile = "cache.db"
+	// cacheMountsBucket maps the IDs of cache mounts to their snapshots
+	cacheMountsBucket = "_cachemounts"
+)
 
 var (
 	errLocked   = errors.New("locked")
@@ -33,6 +37,7 @@ type Accessor interface {
 	Get(id string) (ImmutableRef, error)
 	New(s ImmutableRef) (MutableRef, error)
 	GetMutable(id string) (MutableRef, error) // Rebase?
+	GetCacheMount(id string) (MutableRef, error)
 }
 
 type Controller interface {
@@ -85,7 +90,70 @@ func (cm *cacheManager) init() error {
 	// compare with the walk from Snapshotter
 	// delete items that are not in db (or implement broken transaction detection)
 	// keep all refs in memory(maybe in future work on disk only or with lru)
-	return nil
+	// only cache mounts are persisted for now
+	return cm.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(cacheMountsBucket))
+		if err != nil {
+			return errors.Wrap(err, "failed to create cache mounts bucket")
+		}
+		if _, err := pruneCacheMounts(b, cm.Snapshotter); err != nil {
+			return err
+		}
+		return b.ForEach(func(k, v []byte) error {
+			cm.records[string(v)] = &cacheRecord{
+				mutable: true,
+				id:      string(v),
+				cm:      cm,
+				refs:    make(map[Mountable]struct{}),
+				size:    sizeUnknown,
+			}
+			return nil
+		})
+	})
+}
+
+// PruneCacheMounts removes the cache mounts whose snapshot is not an active
+// snapshot of sn from the metadata in root and returns their IDs. The daemon
+// must not be running.
+func PruneCacheMounts(root string, sn cdsnapshot.Snapshotter) ([]string, error) {
+	p := filepath.Join(root, dbFile)
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return nil, nil
+	}
+	db, err := bolt.Open(p, 0600, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open database file %s", p)
+	}
+	defer db.Close()
+	var pruned []string
+	err = db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(cacheMountsBucket))
+		if b == nil {
+			return nil
+		}
+		pruned, err = pruneCacheMounts(b, sn)
+		return err
+	})
+	return pruned, err
+}
+
+func pruneCacheMounts(b *bolt.Bucket, sn cdsnapshot.Snapshotter) ([]string, error) {
+	var stale []string
+	if err := b.ForEach(func(k, v []byte) error {
+		info, err := sn.Stat(context.TODO(), string(v))
+		if err != nil || info.Kind != cdsnapshot.KindActive {
+			stale = append(stale, string(k))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, k := range stale {
+		if err := b.Delete([]byte(k)); err != nil {
+			return nil, err
+		}
+	}
+	return stale, nil
 }
 
 func (cm *cacheManager) Close() error {
@@ -199,6 +267,52 @@ func (cm *cacheManager) GetMutable(id string) (MutableRef, error) { // Rebase?
 	return rec.mref(), nil
 }
 
+// GetCacheMount returns the mutable record of the cache mount id. An empty
+// one is created on first use. Cache mounts are kept across restarts and can
+// only be used by one ref at a time.
+func (cm *cacheManager) GetCacheMount(id string) (MutableRef, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var snapshotID string
+	if err := cm.db.View(func(tx *bolt.Tx) error {
+		snapshotID = string(tx.Bucket([]byte(cacheMountsBucket)).Get([]byte(id)))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if rec, ok := cm.records[snapshotID]; ok && rec.mutable {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		if rec.frozen || len(rec.refs) != 0 {
+			return nil, errors.Wrapf(errLocked, "cache mount %s is in use", id)
+		}
+		return rec.mref(), nil
+	}
+
+	snapshotID = generateID()
+	if _, err := cm.Snapshotter.Prepare(context.TODO(), snapshotID, ""); err != nil {
+		return nil, errors.Wrapf(err, "failed to prepare cache mount %s", id)
+	}
+	if err := cm.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(cacheMountsBucket)).Put([]byte(id), []byte(snapshotID))
+	}); err != nil {
+		cm.Snapshotter.Remove(context.TODO(), snapshotID)
+		return nil, errors.Wrapf(err, "failed to save cache mount %s", id)
+	}
+
+	rec := &cacheRecord{
+		mutable: true,
+		id:      snapshotID,
+		cm:      cm,
+		refs:    make(map[Mountable]struct{}),
+		size:    sizeUnknown,
+	}
+	cm.records[snapshotID] = rec
+	return rec.mref(), nil
+}
+
 func (cm *cacheManager) DiskUsage(ctx context.Context) ([]*client.UsageInfo, error) {
 	cm.mu.Lock()
 
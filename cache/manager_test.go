@@ -119,6 +119,28 @@ func TestManager(t *testing.T) {
 
 	checkDiskUsage(t, cm, 0, 2)
 
+	snap, err = cm.Get(snap.ID())
+	assert.NoError(t, err)
+
+	active3, err := cm.New(snap)
+	assert.NoError(t, err)
+
+	err = snap.Release()
+	assert.NoError(t, err)
+
+	checkDiskUsage(t, cm, 2, 1)
+
+	err = active3.Discard(context.TODO())
+	assert.NoError(t, err)
+
+	checkDiskUsage(t, cm, 0, 2)
+
+	_, err = snapshotter.Stat(context.TODO(), active3.ID())
+	assert.Error(t, err)
+
+	err = active3.Discard(context.TODO())
+	assert.Error(t, err)
+
 	err = cm.Close()
 	assert.NoError(t, err)
 }
@@ -137,3 +159,88 @@ func checkDiskUsage(t *testing.T, cm Manager, inuse, unused int) {
 	assert.Equal(t, inuse, inuseActual)
 	assert.Equal(t, unused, unusedActual)
 }
+
+func TestCacheMount(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "cachemanager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	snapshotter, err := naive.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	cm, err := NewManager(ManagerOpt{
+		Root:        tmpdir,
+		Snapshotter: snapshotter,
+	})
+	assert.NoError(t, err)
+
+	active, err := cm.GetCacheMount("go")
+	assert.NoError(t, err)
+
+	_, err = cm.GetCacheMount("go")
+	assert.Error(t, err)
+	assert.Equal(t, errLocked, errors.Cause(err))
+
+	m, err := active.Mount()
+	assert.NoError(t, err)
+	lm := snapshot.LocalMounter(m)
+	target, err := lm.Mount()
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(target, "foo"), []byte("bar"), 0600)
+	assert.NoError(t, err)
+	err = lm.Unmount()
+	assert.NoError(t, err)
+
+	err = active.Release()
+	assert.NoError(t, err)
+	err = active.Release()
+	assert.Error(t, err)
+
+	checkDiskUsage(t, cm, 0, 1)
+
+	err = cm.Close()
+	assert.NoError(t, err)
+
+	// cache mounts are loaded again after a restart
+	cm, err = NewManager(ManagerOpt{
+		Root:        tmpdir,
+		Snapshotter: snapshotter,
+	})
+	assert.NoError(t, err)
+
+	checkDiskUsage(t, cm, 0, 1)
+
+	active2, err := cm.GetCacheMount("go")
+	assert.NoError(t, err)
+	assert.Equal(t, active.ID(), active2.ID())
+
+	m, err = active2.Mount()
+	assert.NoError(t, err)
+	lm = snapshot.LocalMounter(m)
+	target, err = lm.Mount()
+	assert.NoError(t, err)
+	dt, err := ioutil.ReadFile(filepath.Join(target, "foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(dt))
+	err = lm.Unmount()
+	assert.NoError(t, err)
+
+	other, err := cm.GetCacheMount("other")
+	assert.NoError(t, err)
+	assert.NotEqual(t, active2.ID(), other.ID())
+
+	checkDiskUsage(t, cm, 2, 0)
+
+	err = cm.Close()
+	assert.NoError(t, err)
+
+	// cache mounts without a snapshot are pruned
+	err = snapshotter.Remove(context.TODO(), other.ID())
+	assert.NoError(t, err)
+	pruned, err := PruneCacheMounts(tmpdir, snapshotter)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other"}, pruned)
+	pruned, err = PruneCacheMounts(tmpdir, snapshotter)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(pruned))
+}
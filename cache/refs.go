@@ -26,6 +26,10 @@ type MutableRef interface {
 	ID() string
 	Freeze() (ImmutableRef, error)
 	ReleaseAndCommit(ctx context.Context) (ImmutableRef, error)
+	// Release drops the ref without committing, the record stays mutable
+	Release() error
+	// Discard drops the ref and removes the record and its snapshot
+	Discard(ctx context.Context) error
 	Size(ctx context.Context) (int64, error)
 }
 
@@ -178,6 +182,20 @@ func (sr *mutableRef) Freeze() (ImmutableRef, error) {
 	return sri, nil
 }
 
+func (sr *mutableRef) Release() error {
+	sr.cm.mu.Lock()
+	defer sr.cm.mu.Unlock()
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if _, ok := sr.refs[sr]; !ok {
+		return errors.Wrapf(errInvalid, "invalid mutable")
+	}
+	delete(sr.refs, sr)
+	return nil
+}
+
 func (sr *mutableRef) ReleaseAndCommit(ctx context.Context) (ImmutableRef, error) {
 	sr.cm.mu.Lock()
 	defer sr.cm.mu.Unlock()
@@ -215,6 +233,33 @@ func (sr *mutableRef) ReleaseAndCommit(ctx context.Context) (ImmutableRef, error
 	return rec.ref(), nil
 }
 
+func (sr *mutableRef) Discard(ctx context.Context) error {
+	sr.cm.mu.Lock()
+	defer sr.cm.mu.Unlock()
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+
+	if !sr.mutable || sr.frozen {
+		return errors.Wrapf(errInvalid, "invalid mutable")
+	}
+	if _, ok := sr.refs[sr]; !ok || len(sr.refs) != 1 {
+		return errors.Wrapf(errInvalid, "multiple mutable references")
+	}
+
+	if err := sr.cm.Snapshotter.Remove(ctx, sr.id); err != nil {
+		return errors.Wrapf(err, "failed to remove %s", sr.id)
+	}
+
+	delete(sr.refs, sr)
+	delete(sr.cm.records, sr.id)
+
+	if sr.parent != nil {
+		return sr.parent.(*immutableRef).release()
+	}
+	return nil
+}
+
 func generateID() string {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+// ReadContent writes a blob of the daemon's content store, like an image
+// written by an exporter, to w
+func (c *Client) ReadContent(ctx context.Context, dgst digest.Digest, w io.Writer) error {
+	stream, err := c.controlClient().ReadContent(ctx, &controlapi.ReadContentRequest{Digest: dgst.String()})
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", dgst)
+	}
+	v := dgst.Verifier()
+	w = io.MultiWriter(w, v)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			if !v.Verified() {
+				return errors.Errorf("content of %s doesn't match its digest", dgst)
+			}
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", dgst)
+		}
+		if _, err := w.Write(resp.Data); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+
+	"github.com/containerd/containerd/reference"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+type ImageConvertOpt struct {
+	// OCI selects OCI media types instead of Docker v2 ones
+	OCI bool
+	// Compression of the layers, uncompressed, gzip or zstd
+	Compression string
+	// Credentials returns the username and secret for a registry host. The
+	// credentials for the source and target registries are sent to the
+	// daemon with the request.
+	Credentials func(host string) (string, string, error)
+}
+
+// ImageConvert converts the image src and pushes it to dst. Every manifest
+// of a multi-platform image is converted. The digest of the new manifest or
+// index is returned.
+func (c *Client) ImageConvert(ctx context.Context, src, dst string, opt ImageConvertOpt) (string, error) {
+	var auth []*controlapi.RegistryAuth
+	if opt.Credentials != nil {
+		hosts := map[string]struct{}{}
+		for _, ref := range []string{src, dst} {
+			host, err := registryHost(ref)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := hosts[host]; ok {
+				continue
+			}
+			hosts[host] = struct{}{}
+			user, secret, err := opt.Credentials(host)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to get credentials for %s", host)
+			}
+			if secret != "" {
+				auth = append(auth, &controlapi.RegistryAuth{Host: host, Username: user, Secret: secret})
+			}
+		}
+	}
+	resp, err := c.controlClient().ImageConvert(ctx, &controlapi.ImageConvertRequest{
+		Source:      src,
+		Target:      dst,
+		OCI:         opt.OCI,
+		Compression: opt.Compression,
+		Auth:        auth,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to convert image")
+	}
+	return resp.Digest, nil
+}
+
+// registryHost returns the host the docker resolver of the daemon asks
+// credentials for
+func registryHost(ref string) (string, error) {
+	spec, err := reference.Parse(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid reference %s", ref)
+	}
+	host := spec.Hostname()
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	return host, nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+)
+
+// BuildRecord is a finished solve in the build history of the daemon
+type BuildRecord struct {
+	Ref string
+	// Signer is the identity of the trusted key that signed the request
+	Signer      string
+	Frontend    string
+	Exporter    string
+	StartedAt   time.Time
+	CompletedAt time.Time
+	// Error is empty if the solve succeeded
+	Error string
+	// FileAccess are the files the exec steps read, if they were traced
+	FileAccess []*filetrace.Access
+}
+
+// History returns the finished solves, oldest first. Only the solve with the
+// ref is returned if it is set.
+func (c *Client) History(ctx context.Context, ref string) ([]*BuildRecord, error) {
+	resp, err := c.controlClient().History(ctx, &controlapi.HistoryRequest{Ref: ref})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get history")
+	}
+	var recs []*BuildRecord
+	for _, r := range resp.Records {
+		rec := &BuildRecord{
+			Ref:         r.Ref,
+			Signer:      r.Signer,
+			Frontend:    r.Frontend,
+			Exporter:    r.Exporter,
+			StartedAt:   time.Unix(0, r.StartedAt),
+			CompletedAt: time.Unix(0, r.CompletedAt),
+			Error:       r.Error,
+		}
+		for _, a := range r.FileAccess {
+			rec.FileAccess = append(rec.FileAccess, &filetrace.Access{
+				Vertex: digest.Digest(a.Vertex),
+				Mount:  a.Mount,
+				Paths:  a.Paths,
+				Traced: a.Traced,
+			})
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
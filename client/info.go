@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+type Info struct {
+	Exporters []string
+	Frontends []string
+}
+
+func (c *Client) Info(ctx context.Context) (*Info, error) {
+	resp, err := c.controlClient().Info(ctx, &controlapi.InfoRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get info")
+	}
+	return &Info{
+		Exporters: resp.Exporters,
+		Frontends: resp.Frontends,
+	}, nil
+}
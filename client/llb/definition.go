@@ -0,0 +1,72 @@
+package llb
+
+import (
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+)
+
+// DefinitionOp is a marshalled definition used as the input of new
+// operations. Its output is the last op of the definition. The ops are
+// marshalled unchanged so their digests, and the cache keys based on them,
+// stay the same.
+type DefinitionOp struct {
+	ops  [][]byte
+	dgst digest.Digest
+}
+
+func NewDefinitionOp(def [][]byte) (*DefinitionOp, error) {
+	d := &DefinitionOp{ops: def}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	d.dgst = digest.FromBytes(def[len(def)-1])
+	return d, nil
+}
+
+// Validate checks that every op can be parsed and only depends on ops
+// before it
+func (d *DefinitionOp) Validate() error {
+	if len(d.ops) == 0 {
+		return errors.New("invalid empty definition")
+	}
+	seen := make(map[digest.Digest]struct{}, len(d.ops))
+	for i, dt := range d.ops {
+		var op pb.Op
+		if err := (&op).Unmarshal(dt); err != nil {
+			return errors.Wrapf(err, "failed to parse op %d", i)
+		}
+		for _, in := range op.Inputs {
+			if _, ok := seen[digest.Digest(in.Digest)]; !ok {
+				return errors.Errorf("missing input %s of op %d", in.Digest, i)
+			}
+		}
+		seen[digest.FromBytes(dt)] = struct{}{}
+	}
+	return nil
+}
+
+func (d *DefinitionOp) Run(m Meta) *ExecOp {
+	return newExec(m, d, nil)
+}
+
+func (d *DefinitionOp) Marshal() ([][]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	cache := make(map[digest.Digest]struct{})
+	_, list, err := d.recursiveMarshal(nil, cache)
+	return list, err
+}
+
+func (d *DefinitionOp) recursiveMarshal(list [][]byte, cache map[digest.Digest]struct{}) (digest.Digest, [][]byte, error) {
+	for _, dt := range d.ops {
+		dgst := digest.FromBytes(dt)
+		if _, ok := cache[dgst]; ok {
+			continue
+		}
+		list = append(list, dt)
+		cache[dgst] = struct{}{}
+	}
+	return d.dgst, list, nil
+}
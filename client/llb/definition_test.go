@@ -0,0 +1,61 @@
+package llb
+
+import (
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+)
+
+func TestDefinitionOp(t *testing.T) {
+	base := Image("docker.io/library/busybox:latest").Run(Meta{Args: []string{"/bin/true"}, Cwd: "/"})
+	def, err := base.Marshal()
+	assert.NoError(t, err)
+
+	d, err := NewDefinitionOp(def)
+	assert.NoError(t, err)
+
+	// extending the loaded definition is the same as extending the original
+	meta := Meta{Args: []string{"/bin/sh", "-c", "echo foo > /bar"}, Cwd: "/"}
+	expected, err := base.Run(meta).Marshal()
+	assert.NoError(t, err)
+	extended, err := d.Run(meta).Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, extended)
+
+	var op pb.Op
+	assert.NoError(t, (&op).Unmarshal(extended[len(extended)-1]))
+	assert.Equal(t, 1, len(op.Inputs))
+	assert.Equal(t, digest.FromBytes(def[len(def)-1]).String(), op.Inputs[0].Digest)
+
+	dt, err := d.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, def, dt)
+}
+
+func TestInvalidDefinitionOp(t *testing.T) {
+	_, err := NewDefinitionOp(nil)
+	assert.Error(t, err)
+
+	def, err := Image("docker.io/library/busybox:latest").Run(Meta{Args: []string{"/bin/true"}}).Marshal()
+	assert.NoError(t, err)
+	_, err = NewDefinitionOp(def[1:]) // missing the source op
+	assert.Error(t, err)
+}
+
+func TestCacheMount(t *testing.T) {
+	def, err := Image("docker.io/library/golang:latest").Run(Meta{Args: []string{"go", "build"}}).AddCacheMount("/root/.cache/go-build", "go").Marshal()
+	assert.NoError(t, err)
+
+	var op pb.Op
+	assert.NoError(t, (&op).Unmarshal(def[len(def)-1]))
+	assert.Equal(t, 1, len(op.Inputs))
+	mounts := op.GetExec().Mounts
+	assert.Equal(t, 2, len(mounts))
+	assert.Equal(t, pb.BIND, mounts[0].MountType)
+	assert.Equal(t, &pb.Mount{Input: -1, Dest: "/root/.cache/go-build", Output: -1, MountType: pb.CACHE, CacheID: "go"}, mounts[1])
+
+	_, err = Image("docker.io/library/golang:latest").Run(Meta{Args: []string{"go", "build"}}).AddCacheMount("/cache", "").Marshal()
+	assert.Error(t, err)
+}
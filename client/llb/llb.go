@@ -27,17 +27,19 @@ type ExecOp struct {
 }
 
 type Meta struct {
-	Args []string
-	Env  []string
-	Cwd  string
+	Args    []string
+	Env     []string
+	Cwd     string
+	Devices []string
 }
 
 type mount struct {
-	op     *ExecOp
-	dest   string
-	mount  *mount
-	src    *SourceOp
-	output bool
+	op      *ExecOp
+	dest    string
+	mount   *mount
+	src     Op // SourceOp or DefinitionOp
+	output  bool
+	cacheID string // cache mounts have no source and no output
 }
 
 func Source(id string) *SourceOp {
@@ -81,7 +83,11 @@ func Image(ref string) *SourceOp {
 	return Source("docker-image://" + ref) // controversial
 }
 
-func newExec(meta Meta, src *SourceOp, m *mount) *ExecOp {
+func Local(name string) *SourceOp {
+	return Source("local://" + name)
+}
+
+func newExec(meta Meta, src Op, m *mount) *ExecOp {
 	exec := &ExecOp{
 		meta:   meta,
 		mounts: []*mount{},
@@ -99,6 +105,9 @@ func newExec(meta Meta, src *SourceOp, m *mount) *ExecOp {
 
 func (eo *ExecOp) Validate() error {
 	for _, m := range eo.mounts {
+		if m.src == nil && m.mount == nil && m.cacheID == "" {
+			return errors.Errorf("cache mount %s has no ID", m.dest)
+		}
 		if m.src != nil {
 			if err := m.src.Validate(); err != nil {
 				return err
@@ -114,6 +123,14 @@ func (eo *ExecOp) Validate() error {
 	return nil
 }
 
+// AddCacheMount mounts the persistent cache directory id at dest. Its
+// contents are shared with all execs using the same id and are not part of
+// the result.
+func (eo *ExecOp) AddCacheMount(dest, id string) *ExecOp {
+	eo.mounts = append(eo.mounts, &mount{op: eo, dest: dest, cacheID: id})
+	return eo
+}
+
 func (eo *ExecOp) Run(meta Meta) *ExecOp {
 	return newExec(meta, nil, eo.root)
 }
@@ -130,9 +147,10 @@ func (eo *ExecOp) Marshal() ([][]byte, error) {
 func (eo *ExecOp) recursiveMarshal(list [][]byte, cache map[digest.Digest]struct{}) (digest.Digest, [][]byte, error) {
 	peo := &pb.ExecOp{
 		Meta: &pb.Meta{
-			Args: eo.meta.Args,
-			Env:  eo.meta.Env,
-			Cwd:  eo.meta.Cwd,
+			Args:    eo.meta.Args,
+			Env:     eo.meta.Env,
+			Cwd:     eo.meta.Cwd,
+			Devices: eo.meta.Devices,
 		},
 	}
 
@@ -149,6 +167,16 @@ func (eo *ExecOp) recursiveMarshal(list [][]byte, cache map[digest.Digest]struct
 	var outputIndex int64 = 0
 
 	for _, m := range eo.mounts {
+		if m.cacheID != "" {
+			peo.Mounts = append(peo.Mounts, &pb.Mount{
+				Input:     -1,
+				Dest:      m.dest,
+				Output:    -1,
+				MountType: pb.CACHE,
+				CacheID:   m.cacheID,
+			})
+			continue
+		}
 		var dgst digest.Digest
 		var err error
 		var op Op
@@ -211,6 +239,8 @@ func recursiveMarshalAny(op Op, list [][]byte, cache map[digest.Digest]struct{})
 		return op.recursiveMarshal(list, cache)
 	case *SourceOp:
 		return op.recursiveMarshal(list, cache)
+	case *DefinitionOp:
+		return op.recursiveMarshal(list, cache)
 	default:
 		return "", nil, errors.Errorf("invalid operation")
 	}
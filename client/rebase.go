@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+// ImageRebase replaces oldBase with newBase as the base of image and returns
+// the digest of the new manifest. The new image is pushed to target if it is
+// not empty.
+func (c *Client) ImageRebase(ctx context.Context, image, oldBase, newBase, target string) (string, error) {
+	resp, err := c.controlClient().ImageRebase(ctx, &controlapi.ImageRebaseRequest{
+		Image:   image,
+		OldBase: oldBase,
+		NewBase: newBase,
+		Target:  target,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to rebase image")
+	}
+	return resp.Digest, nil
+}
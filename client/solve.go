@@ -2,31 +2,252 @@ package client
 
 import (
 	"context"
+	"crypto"
 	"crypto/rand"
 	"encoding/hex"
 	"io"
+	"time"
 
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
 	"github.com/tonistiigi/buildkit_poc/client/llb"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+	"github.com/tonistiigi/buildkit_poc/util/llbsign"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+	"golang.org/x/sync/errgroup"
 )
 
-func (c *Client) Solve(ctx context.Context, r io.Reader) error {
-	def, err := llb.ReadFrom(r)
-	if err != nil {
-		return errors.Wrap(err, "failed to parse input")
+type SolveOpt struct {
+	// Contexts are tar archives, optionally compressed, uploaded before the
+	// build. They are available to llb.Local sources by name.
+	Contexts map[string]io.Reader
+	// Signer signs the definition, or the frontend and its options, so that
+	// the daemon can verify the origin of the request
+	Signer crypto.Signer
+	// Warnings receives the warnings of the build if set. The channel is
+	// closed when Solve returns.
+	Warnings chan *warnings.Warning
+	// FileAccess enables tracing the files opened by the exec steps of the
+	// build and receives the files read from every mount. The channel is
+	// closed when Solve returns.
+	FileAccess chan *filetrace.Access
+	// Usage receives the resource usage of every exec step if set. The
+	// daemon only reports usage if it runs steps under a cgroup parent. The
+	// channel is closed when Solve returns.
+	Usage chan *resourceusage.Usage
+	// Entitlements grant the build extra privileges, like using host devices
+	Entitlements []string
+	// Frontend selects a frontend of the daemon that builds the request
+	// instead of a definition. The definition reader is not used.
+	Frontend    string
+	FrontendOpt map[string]string
+	// Exporter selects an exporter of the daemon for the result. The result
+	// is only kept in the build cache if empty.
+	Exporter      string
+	ExporterAttrs map[string]string
+	// CacheMounts are the IDs of the cache mounts seeded from
+	// CacheMountsImport before the build and written to CacheMountsExport
+	// after it. Both are a directory of the daemon or a registry reference.
+	// Cache mounts that can't be imported start empty.
+	CacheMounts       []string
+	CacheMountsImport string
+	CacheMountsExport string
+	// EgressAllow narrows down the hosts the exec steps can reach to a
+	// subset of the hosts allowed by the daemon
+	EgressAllow []string
+}
+
+// SolveResponse is the result of a solve
+type SolveResponse struct {
+	// ExporterResponse is returned by the exporter of the request
+	ExporterResponse map[string]string
+	// Signer is the identity of the trusted key that signed the definition,
+	// empty if the daemon didn't verify a signature
+	Signer string
+}
+
+const (
+	uploadChunkSize = 32 * 1024
+	// statusGracePeriod is how long the status stream is still read after
+	// the solve request has returned
+	statusGracePeriod = 5 * time.Second
+)
+
+// Solve builds the definition read from r and returns the response of the
+// exporter
+func (c *Client) Solve(ctx context.Context, r io.Reader, opt SolveOpt) (*SolveResponse, error) {
+	if opt.Warnings != nil {
+		defer close(opt.Warnings)
+	}
+	if opt.FileAccess != nil {
+		defer close(opt.FileAccess)
+	}
+	if opt.Usage != nil {
+		defer close(opt.Usage)
+	}
+
+	var (
+		def [][]byte
+		err error
+	)
+	if opt.Frontend == "" {
+		if def, err = llb.ReadFrom(r); err != nil {
+			return nil, errors.Wrap(err, "failed to parse input")
+		}
+		if len(def) == 0 {
+			return nil, errors.New("invalid empty definition")
+		}
 	}
 
-	if len(def) == 0 {
-		return errors.New("invalid empty definition")
+	var sig []byte
+	if opt.Signer != nil {
+		dgst := llbsign.FrontendDigest(opt.Frontend, opt.FrontendOpt)
+		if opt.Frontend == "" {
+			if dgst, err = llbsign.Digest(def); err != nil {
+				return nil, err
+			}
+		}
+		if sig, err = llbsign.SignDigest(dgst, opt.Signer); err != nil {
+			return nil, errors.Wrap(err, "failed to sign request")
+		}
 	}
 
-	_, err = c.controlClient().Solve(ctx, &controlapi.SolveRequest{
-		Ref:        generateID(),
-		Definition: def,
+	ref := generateID()
+
+	for name, r := range opt.Contexts {
+		if err := c.uploadContext(ctx, ref, name, r); err != nil {
+			return nil, err
+		}
+	}
+
+	statusCtx, cancelStatus := context.WithCancel(ctx)
+	defer cancelStatus()
+
+	var eg errgroup.Group
+	if opt.Warnings != nil || opt.FileAccess != nil || opt.Usage != nil {
+		eg.Go(func() error {
+			return c.readStatus(statusCtx, ref, opt)
+		})
+	}
+
+	var res *SolveResponse
+	eg.Go(func() error {
+		// the daemon ends the status stream when the solve finishes
+		defer time.AfterFunc(statusGracePeriod, cancelStatus)
+		resp, err := c.controlClient().Solve(ctx, &controlapi.SolveRequest{
+			Ref:               ref,
+			Definition:        def,
+			Signature:         sig,
+			Entitlements:      opt.Entitlements,
+			Frontend:          opt.Frontend,
+			FrontendOpt:       opt.FrontendOpt,
+			Exporter:          opt.Exporter,
+			ExporterAttrs:     opt.ExporterAttrs,
+			TraceFileAccess:   opt.FileAccess != nil,
+			CacheMounts:       opt.CacheMounts,
+			CacheMountsImport: opt.CacheMountsImport,
+			CacheMountsExport: opt.CacheMountsExport,
+			EgressAllow:       opt.EgressAllow,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to solve")
+		}
+		res = &SolveResponse{
+			ExporterResponse: resp.ExporterResponse,
+			Signer:           resp.Signer,
+		}
+		return nil
 	})
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readStatus sends the warnings, file accesses and resource usage of the
+// solve to the channels of opt that are not nil
+func (c *Client) readStatus(ctx context.Context, ref string, opt SolveOpt) error {
+	stream, err := c.controlClient().Status(ctx, &controlapi.StatusRequest{Ref: ref})
+	if err != nil {
+		return errors.Wrap(err, "failed to get status")
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "failed to receive status")
+		}
+		for _, w := range resp.Warnings {
+			if opt.Warnings == nil {
+				break
+			}
+			opt.Warnings <- &warnings.Warning{
+				Vertex:   digest.Digest(w.Vertex),
+				Severity: warnings.Severity(w.Severity),
+				Message:  w.Message,
+				Filename: w.Location.GetFilename(),
+				Line:     int(w.Location.GetLine()),
+			}
+		}
+		for _, a := range resp.FileAccess {
+			if opt.FileAccess == nil {
+				break
+			}
+			opt.FileAccess <- &filetrace.Access{
+				Vertex: digest.Digest(a.Vertex),
+				Mount:  a.Mount,
+				Paths:  a.Paths,
+				Traced: a.Traced,
+			}
+		}
+		for _, u := range resp.Usage {
+			if opt.Usage == nil {
+				break
+			}
+			opt.Usage <- &resourceusage.Usage{
+				Vertex: digest.Digest(u.Vertex),
+				CPU:    time.Duration(u.CPUTime),
+				Memory: u.PeakMemory,
+			}
+		}
+	}
+}
+
+func (c *Client) uploadContext(ctx context.Context, ref, name string, r io.Reader) error {
+	stream, err := c.controlClient().UploadContext(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to solve")
+		return errors.Wrap(err, "failed to start upload")
+	}
+	msg := &controlapi.UploadContextRequest{Ref: ref, Name: name}
+	buf := make([]byte, uploadChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			msg.Data = buf[:n]
+			if err := stream.Send(msg); err != nil {
+				return errors.Wrapf(err, "failed to upload context %s", name)
+			}
+			msg = &controlapi.UploadContextRequest{}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read context %s", name)
+		}
+	}
+	if msg.Name != "" { // empty archive, still create the context
+		if err := stream.Send(msg); err != nil {
+			return errors.Wrapf(err, "failed to upload context %s", name)
+		}
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		return errors.Wrapf(err, "failed to upload context %s", name)
 	}
 	return nil
 }
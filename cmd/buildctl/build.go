@@ -2,8 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/client"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+	"github.com/tonistiigi/buildkit_poc/util/llbsign"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
 	"github.com/urfave/cli"
 )
 
@@ -11,12 +23,302 @@ var buildCommand = cli.Command{
 	Name:   "build",
 	Usage:  "build",
 	Action: build,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "definition",
+			Usage: "file containing the LLB definition, stdin by default",
+		},
+		cli.StringSliceFlag{
+			Name:  "context-tar",
+			Usage: "upload a tar archive as local source, [name=]path with - for stdin. Default name is context",
+		},
+		cli.StringFlag{
+			Name:  "sign-key",
+			Usage: "private key for signing the definition or the frontend request",
+		},
+		cli.StringSliceFlag{
+			Name:  "allow",
+			Usage: "allow an extra privilege for the build, e.g. device",
+		},
+		cli.StringSliceFlag{
+			Name:  "egress-allow",
+			Usage: "host the exec steps can reach, *.domain for subdomains. Must be allowed by the daemon",
+		},
+		cli.StringFlag{
+			Name:  "frontend",
+			Usage: "build with a frontend of the daemon instead of a definition",
+		},
+		cli.StringSliceFlag{
+			Name:  "frontend-opt",
+			Usage: "set an option of the frontend, key=value",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "export the result with an exporter of the daemon, type=<name>[,dest=<path>][,key=value...]. dest writes the exported content to a local file",
+		},
+		cli.BoolFlag{
+			Name:  "trace-file-access",
+			Usage: "print the files every exec step read from its mounts",
+		},
+		cli.StringSliceFlag{
+			Name:  "cache-mount",
+			Usage: "ID of a cache mount to import and export",
+		},
+		cli.StringFlag{
+			Name:  "cache-mounts-from",
+			Usage: "daemon directory or registry reference to seed empty cache mounts from",
+		},
+		cli.StringFlag{
+			Name:  "cache-mounts-to",
+			Usage: "daemon directory or registry reference to export cache mounts to after the build",
+		},
+	},
 }
 
 func build(clicontext *cli.Context) error {
-	client, err := resolveClient(clicontext)
+	c, err := resolveClient(clicontext)
 	if err != nil {
 		return err
 	}
-	return client.Solve(context.TODO(), os.Stdin)
+
+	var def io.Reader = os.Stdin
+	stdinUsed := true
+	if clicontext.String("frontend") != "" {
+		if clicontext.IsSet("definition") {
+			return errors.New("a definition can't be used with a frontend")
+		}
+		def, stdinUsed = nil, false
+	} else if p := clicontext.String("definition"); p != "" && p != "-" {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		def = f
+		stdinUsed = false
+	}
+
+	opt := client.SolveOpt{
+		Contexts:     map[string]io.Reader{},
+		Entitlements: clicontext.StringSlice("allow"),
+		Frontend:     clicontext.String("frontend"),
+
+		CacheMounts:       clicontext.StringSlice("cache-mount"),
+		CacheMountsImport: clicontext.String("cache-mounts-from"),
+		CacheMountsExport: clicontext.String("cache-mounts-to"),
+		EgressAllow:       clicontext.StringSlice("egress-allow"),
+	}
+	var dest string
+	if v := clicontext.String("output"); v != "" {
+		if opt.Exporter, opt.ExporterAttrs, err = parseOutput(v); err != nil {
+			return err
+		}
+		// dest is handled by the client, not the exporter
+		dest = opt.ExporterAttrs["dest"]
+		delete(opt.ExporterAttrs, "dest")
+	}
+	for _, v := range clicontext.StringSlice("frontend-opt") {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid frontend option %s, must be key=value", v)
+		}
+		if opt.FrontendOpt == nil {
+			opt.FrontendOpt = map[string]string{}
+		}
+		opt.FrontendOpt[parts[0]] = parts[1]
+	}
+	if p := clicontext.String("sign-key"); p != "" {
+		signer, err := llbsign.LoadSigner(p)
+		if err != nil {
+			return err
+		}
+		opt.Signer = signer
+	}
+
+	for _, v := range clicontext.StringSlice("context-tar") {
+		name, p := "context", v
+		if parts := strings.SplitN(v, "=", 2); len(parts) == 2 {
+			name, p = parts[0], parts[1]
+		}
+		if _, ok := opt.Contexts[name]; ok {
+			return errors.Errorf("duplicate context %s", name)
+		}
+		if p == "-" {
+			if stdinUsed {
+				return errors.New("stdin can only be used once, use --definition to read the definition from a file")
+			}
+			stdinUsed = true
+			opt.Contexts[name] = os.Stdin
+			continue
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		opt.Contexts[name] = f
+	}
+
+	opt.Warnings = make(chan *warnings.Warning)
+	collected := make(chan []*warnings.Warning)
+	go func() {
+		var ws []*warnings.Warning
+		for w := range opt.Warnings {
+			ws = append(ws, w)
+		}
+		collected <- ws
+	}()
+
+	var accesses chan []*filetrace.Access
+	if clicontext.Bool("trace-file-access") {
+		opt.FileAccess = make(chan *filetrace.Access)
+		accesses = make(chan []*filetrace.Access, 1)
+		go func() {
+			var as []*filetrace.Access
+			for a := range opt.FileAccess {
+				as = append(as, a)
+			}
+			accesses <- as
+		}()
+	}
+
+	opt.Usage = make(chan *resourceusage.Usage)
+	usage := make(chan []*resourceusage.Usage, 1)
+	go func() {
+		var us []*resourceusage.Usage
+		for u := range opt.Usage {
+			us = append(us, u)
+		}
+		usage <- us
+	}()
+
+	resp, err := c.Solve(context.TODO(), def, opt)
+	printWarnings(os.Stderr, <-collected)
+	printUsage(os.Stderr, <-usage)
+	if accesses != nil {
+		printFileAccess(os.Stderr, <-accesses)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.Signer != "" {
+		fmt.Fprintf(os.Stderr, "definition signed by %s\n", resp.Signer)
+	}
+	keys := make([]string, 0, len(resp.ExporterResponse))
+	for k := range resp.ExporterResponse {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s=%s\n", k, resp.ExporterResponse[k])
+	}
+	if dest != "" {
+		return writeExported(c, resp.ExporterResponse, dest)
+	}
+	return nil
+}
+
+// writeExported copies the content an exporter wrote to the daemon's content
+// store to the local file dest
+func writeExported(c *client.Client, resp map[string]string, dest string) error {
+	dgst, err := digest.Parse(resp["digest"])
+	if err != nil {
+		return errors.New("exporter didn't return any content to write to dest")
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if err := c.ReadContent(context.TODO(), dgst, f); err != nil {
+		f.Close()
+		os.Remove(dest)
+		return err
+	}
+	return f.Close()
+}
+
+// parseOutput parses the type and attributes of an exporter from a comma
+// separated list of key=value pairs
+func parseOutput(v string) (string, map[string]string, error) {
+	fields, err := csv.NewReader(strings.NewReader(v)).Read()
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "invalid output %s", v)
+	}
+	var typ string
+	attrs := map[string]string{}
+	for _, f := range fields {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 {
+			return "", nil, errors.Errorf("invalid output field %s, must be key=value", f)
+		}
+		if parts[0] == "type" {
+			typ = parts[1]
+			continue
+		}
+		attrs[parts[0]] = parts[1]
+	}
+	if typ == "" {
+		return "", nil, errors.Errorf("output %s has no type", v)
+	}
+	return typ, attrs, nil
+}
+
+func printWarnings(w io.Writer, ws []*warnings.Warning) {
+	if len(ws) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d warnings:\n", len(ws))
+	for _, wr := range ws {
+		vertex := wr.Vertex.Hex()
+		if len(vertex) > 12 {
+			vertex = vertex[:12]
+		}
+		fmt.Fprintf(w, "%s [%s] %s", wr.Severity, vertex, wr.Message)
+		if wr.Filename != "" {
+			fmt.Fprintf(w, " (%s:%d)", wr.Filename, wr.Line)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printUsage prints the resource usage of every exec step
+func printUsage(w io.Writer, us []*resourceusage.Usage) {
+	sort.SliceStable(us, func(i, j int) bool {
+		return us[i].Vertex < us[j].Vertex
+	})
+	for _, u := range us {
+		vertex := u.Vertex.Hex()
+		if len(vertex) > 12 {
+			vertex = vertex[:12]
+		}
+		fmt.Fprintf(w, "[%s] %s\n", vertex, u)
+	}
+}
+
+// printFileAccess prints the files read from every mount of the exec steps.
+// Mounts that no file was read from are marked as unused.
+func printFileAccess(w io.Writer, as []*filetrace.Access) {
+	sort.SliceStable(as, func(i, j int) bool {
+		if as[i].Vertex != as[j].Vertex {
+			return as[i].Vertex < as[j].Vertex
+		}
+		return as[i].Mount < as[j].Mount
+	})
+	for _, a := range as {
+		vertex := a.Vertex.Hex()
+		if len(vertex) > 12 {
+			vertex = vertex[:12]
+		}
+		switch {
+		case !a.Traced:
+			fmt.Fprintf(w, "[%s] %s: not traced\n", vertex, a.Mount)
+		case len(a.Paths) == 0:
+			fmt.Fprintf(w, "[%s] %s: unused input\n", vertex, a.Mount)
+		default:
+			fmt.Fprintf(w, "[%s] %s: %d files read\n", vertex, a.Mount, len(a.Paths))
+			for _, p := range a.Paths {
+				fmt.Fprintf(w, "  %s\n", p)
+			}
+		}
+	}
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+var historyCommand = cli.Command{
+	Name:      "history",
+	Usage:     "display the finished builds of the daemon",
+	ArgsUsage: "[ref]",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "file-access",
+			Usage: "print the files the exec steps read, if they were traced",
+		},
+	},
+	Action: buildHistory,
+}
+
+func buildHistory(clicontext *cli.Context) error {
+	c, err := resolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+	recs, err := c.History(context.TODO(), clicontext.Args().First())
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		status := "completed"
+		if r.Error != "" {
+			status = "failed: " + r.Error
+		}
+		fmt.Printf("%s %s %s %s\n", r.Ref, r.CompletedAt.Format(time.RFC3339), r.CompletedAt.Sub(r.StartedAt).Round(time.Millisecond), status)
+		if r.Signer != "" {
+			fmt.Printf("  signed by %s\n", r.Signer)
+		}
+		if r.Frontend != "" {
+			fmt.Printf("  frontend %s\n", r.Frontend)
+		}
+		if r.Exporter != "" {
+			fmt.Printf("  exporter %s\n", r.Exporter)
+		}
+		if clicontext.Bool("file-access") {
+			printFileAccess(os.Stdout, r.FileAccess)
+		}
+	}
+	return nil
+}
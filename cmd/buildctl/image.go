@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/client"
+	"github.com/tonistiigi/buildkit_poc/util/dockerconfig"
+	"github.com/urfave/cli"
+)
+
+var imageCommand = cli.Command{
+	Name:  "image",
+	Usage: "image utilities",
+	Subcommands: []cli.Command{
+		imageRebaseCommand,
+		imageConvertCommand,
+	},
+}
+
+var imageRebaseCommand = cli.Command{
+	Name:      "rebase",
+	Usage:     "replace the base layers of an image without rebuilding it",
+	ArgsUsage: "IMAGE OLD_BASE NEW_BASE",
+	Action:    imageRebase,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "target",
+			Usage: "push the rebased image to this reference",
+		},
+	},
+}
+
+func imageRebase(clicontext *cli.Context) error {
+	if clicontext.NArg() != 3 {
+		return errors.New("rebase requires image, old base and new base")
+	}
+	client, err := resolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	args := clicontext.Args()
+	dgst, err := client.ImageRebase(context.TODO(), args[0], args[1], args[2], clicontext.String("target"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(dgst)
+	return nil
+}
+
+var imageConvertCommand = cli.Command{
+	Name:        "convert",
+	Usage:       "convert the media types and layer compression of an image",
+	ArgsUsage:   "SOURCE TARGET",
+	Description: "registry credentials are read from the docker config in $DOCKER_CONFIG or ~/.docker",
+	Action:      imageConvert,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "oci",
+			Usage: "use OCI media types",
+		},
+		cli.StringFlag{
+			Name:  "compression",
+			Usage: "layer compression: uncompressed, gzip or zstd",
+			Value: "gzip",
+		},
+	},
+}
+
+func imageConvert(clicontext *cli.Context) error {
+	if clicontext.NArg() != 2 {
+		return errors.New("convert requires source and target image")
+	}
+	c, err := resolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := dockerconfig.Load(dockerconfig.Dir())
+	if err != nil {
+		return err
+	}
+
+	args := clicontext.Args()
+	dgst, err := c.ImageConvert(context.TODO(), args[0], args[1], client.ImageConvertOpt{
+		OCI:         clicontext.Bool("oci"),
+		Compression: clicontext.String("compression"),
+		Credentials: cfg.Credentials,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(dgst)
+	return nil
+}
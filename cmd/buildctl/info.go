@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+var infoCommand = cli.Command{
+	Name:   "info",
+	Usage:  "display the exporters and frontends of the daemon",
+	Action: info,
+}
+
+func info(clicontext *cli.Context) error {
+	c, err := resolveClient(clicontext)
+	if err != nil {
+		return err
+	}
+	i, err := c.Info(context.TODO())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exporters: %s\n", strings.Join(i.Exporters, ", "))
+	fmt.Printf("Frontends: %s\n", strings.Join(i.Frontends, ", "))
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/frontend/dockerfile/lint"
+	"github.com/tonistiigi/buildkit_poc/frontend/dockerfile/parser"
+	"github.com/urfave/cli"
+)
+
+var lintCommand = cli.Command{
+	Name:      "lint",
+	Usage:     "check a Dockerfile for common problems without building it",
+	ArgsUsage: "[Dockerfile]",
+	Action:    lintDockerfile,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "output format: text or json",
+			Value: "text",
+		},
+	},
+}
+
+type lintFinding struct {
+	File     string       `json:"file"`
+	Rule     string       `json:"rule"`
+	Severity string       `json:"severity"`
+	Message  string       `json:"message"`
+	Range    parser.Range `json:"range"`
+}
+
+func lintDockerfile(clicontext *cli.Context) error {
+	format := clicontext.String("format")
+	if format != "text" && format != "json" {
+		return errors.Errorf("invalid format %s", format)
+	}
+
+	fn := "Dockerfile"
+	if clicontext.NArg() > 0 {
+		fn = clicontext.Args().First()
+	}
+	var r io.Reader = os.Stdin
+	if fn != "-" {
+		f, err := os.Open(fn)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	findings, err := lint.Lint(r)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		out := make([]lintFinding, 0, len(findings))
+		for _, f := range findings {
+			out = append(out, lintFinding{File: fn, Rule: f.Rule, Severity: f.Severity.String(), Message: f.Message, Range: f.Range})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s:%d:%d: %s %s: %s\n", fn, f.Range.Start.Line, f.Range.Start.Column, f.Severity, f.Rule, f.Message)
+		}
+	}
+
+	if len(findings) > 0 {
+		return errors.Errorf("%d problems found", len(findings))
+	}
+	return nil
+}
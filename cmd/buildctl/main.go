@@ -28,8 +28,12 @@ func main() {
 
 	app.Commands = []cli.Command{
 		diskUsageCommand,
+		historyCommand,
 		buildCommand,
 		debugCommand,
+		imageCommand,
+		infoCommand,
+		lintCommand,
 	}
 
 	app.Before = func(context *cli.Context) error {
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"expvar"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/containerd/containerd/sys"
 	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/control"
+	"github.com/tonistiigi/buildkit_poc/util/egressproxy"
+	"github.com/tonistiigi/buildkit_poc/util/llbsign"
+	"github.com/tonistiigi/buildkit_poc/util/peercred"
 	"github.com/urfave/cli"
 	"golang.org/x/net/context"
 	"golang.org/x/sys/unix"
@@ -35,15 +42,80 @@ func main() {
 			Usage: "listening socket",
 			Value: "/run/buildkit/buildd.sock",
 		},
+		cli.StringSliceFlag{
+			Name:  "trusted-key",
+			Usage: "public key for verifying signed definitions, named after the file",
+		},
+		cli.BoolFlag{
+			Name:  "require-signed",
+			Usage: "only run definitions signed by a trusted key",
+		},
+		cli.StringSliceFlag{
+			Name:  "allowed-signer",
+			Usage: "only run definitions signed by this trusted key, by name",
+		},
+		cli.StringSliceFlag{
+			Name:  "client-signer",
+			Usage: "only run definitions of a client signed by a trusted key, client=name. Overrides --allowed-signer for the client",
+		},
+		cli.StringSliceFlag{
+			Name:  "allow-device",
+			Usage: "host device exec steps can use with the device entitlement",
+		},
+		cli.StringSliceFlag{
+			Name:  "cache-mount-dir",
+			Usage: "directory clients can export cache mounts to and import them from",
+		},
+		cli.StringFlag{
+			Name:  "cgroup-parent",
+			Usage: "cgroup path or systemd slice[:prefix] to run all build containers under",
+		},
+		cli.StringSliceFlag{
+			Name:  "egress-allow",
+			Usage: "host build containers can reach through the egress proxy, *.domain for subdomains. Builds can narrow the hosts down",
+		},
+		cli.StringFlag{
+			Name:  "http-cache",
+			Usage: "cache plain http downloads of build containers: record or replay. https is tunneled and never cached. Without --egress-allow, record mode can reach all hosts",
+		},
+		cli.BoolFlag{
+			Name:  "debug-dump-graph",
+			Usage: "log the optimized graph of every solve",
+		},
+		cli.StringFlag{
+			Name:  "debug-address",
+			Usage: "serve debug variables and limit metrics over HTTP on this address",
+		},
+		cli.IntFlag{
+			Name:  "max-definition-size",
+			Usage: "reject definitions larger than this many bytes",
+		},
+		cli.IntFlag{
+			Name:  "max-ops",
+			Usage: "reject definitions with more ops",
+		},
+		cli.IntFlag{
+			Name:  "max-depth",
+			Usage: "reject definitions with longer chains of ops",
+		},
+		cli.IntFlag{
+			Name:  "max-solves-per-client",
+			Usage: "reject solves of clients that already run this many",
+		},
+		cli.DurationFlag{
+			Name:  "max-solve-duration",
+			Usage: "cancel solves running longer",
+		},
 	}
 
 	app.Flags = appendFlags(app.Flags)
+	app.Commands = appendCommands(app.Commands)
 
 	app.Action = func(c *cli.Context) error {
 		signals := make(chan os.Signal, 2048)
 		signal.Notify(signals, unix.SIGTERM, unix.SIGINT)
 
-		server := grpc.NewServer(debugGrpcErrors())
+		server := grpc.NewServer(debugGrpcErrors(), grpc.Creds(peercred.NewCredentials()))
 
 		// relative path does not work with nightlyone/lockfile
 		root, err := filepath.Abs(c.GlobalString("root"))
@@ -62,12 +134,25 @@ func main() {
 
 		controller.Register(server)
 
+		if addr := c.GlobalString("debug-address"); addr != "" {
+			// only the variables, not the handlers that imported packages
+			// register on the default mux
+			mux := http.NewServeMux()
+			mux.Handle("/debug/vars", expvar.Handler())
+			go func() {
+				logrus.Infof("serving debug variables on %s", addr)
+				if err := http.ListenAndServe(addr, mux); err != nil {
+					logrus.Errorf("debug server: %v", err)
+				}
+			}()
+		}
+
 		ctx, cancel := context.WithCancel(context.Background())
 		if err := serveGRPC(c.GlobalString("socket"), server, cancel); err != nil {
 			return err
 		}
 
-		return handleSignals(ctx, signals, server)
+		return handleSignals(ctx, signals, server, controller)
 	}
 	app.Before = func(context *cli.Context) error {
 		if context.GlobalBool("debug") {
@@ -81,6 +166,59 @@ func main() {
 	}
 }
 
+func controllerConfig(c *cli.Context) (control.Config, error) {
+	var cfg control.Config
+	if keys := c.GlobalStringSlice("trusted-key"); len(keys) > 0 {
+		v, err := llbsign.LoadVerifier(keys)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Verifier = v
+	}
+	cfg.RequireSigned = c.GlobalBool("require-signed")
+	if cfg.RequireSigned && cfg.Verifier == nil {
+		return cfg, errors.New("--require-signed needs at least one --trusted-key")
+	}
+	cfg.AllowedSigners = c.GlobalStringSlice("allowed-signer")
+	for _, v := range c.GlobalStringSlice("client-signer") {
+		i := strings.LastIndex(v, "=")
+		if i <= 0 {
+			return cfg, errors.Errorf("invalid client signer %s, must be client=name", v)
+		}
+		if cfg.ClientSigners == nil {
+			cfg.ClientSigners = map[string][]string{}
+		}
+		cfg.ClientSigners[v[:i]] = append(cfg.ClientSigners[v[:i]], v[i+1:])
+	}
+	signers := cfg.AllowedSigners
+	for _, s := range cfg.ClientSigners {
+		signers = append(signers, s...)
+	}
+	for _, s := range signers {
+		if cfg.Verifier == nil || !cfg.Verifier.Has(s) {
+			return cfg, errors.Errorf("allowed signer %s is not a --trusted-key", s)
+		}
+	}
+	cfg.AllowedDevices = c.GlobalStringSlice("allow-device")
+	cfg.CacheMountDirs = c.GlobalStringSlice("cache-mount-dir")
+	cfg.CgroupParent = c.GlobalString("cgroup-parent")
+	cfg.EgressAllow = c.GlobalStringSlice("egress-allow")
+	cfg.DumpGraph = c.GlobalBool("debug-dump-graph")
+	cfg.Limits = control.Limits{
+		MaxDefinitionSize:  c.GlobalInt("max-definition-size"),
+		MaxOps:             c.GlobalInt("max-ops"),
+		MaxDepth:           c.GlobalInt("max-depth"),
+		MaxSolvesPerClient: c.GlobalInt("max-solves-per-client"),
+		MaxSolveDuration:   c.GlobalDuration("max-solve-duration"),
+	}
+	if cfg.HTTPCache = c.GlobalString("http-cache"); cfg.HTTPCache != "" {
+		if _, err := egressproxy.ParseCacheMode(cfg.HTTPCache); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
 func serveGRPC(path string, server *grpc.Server, cancel func()) error {
 	if path == "" {
 		return errors.New("--socket path cannot be empty")
@@ -100,12 +238,13 @@ func serveGRPC(path string, server *grpc.Server, cancel func()) error {
 	return nil
 }
 
-func handleSignals(ctx context.Context, signals chan os.Signal, server *grpc.Server) error {
+func handleSignals(ctx context.Context, signals chan os.Signal, server *grpc.Server, controller *control.Controller) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-signals:
 		logrus.Infof("stopping server")
+		controller.Close()
 		server.Stop()
 		return nil
 	}
@@ -17,9 +17,17 @@ func appendFlags(f []cli.Flag) []cli.Flag {
 	}...)
 }
 
+func appendCommands(c []cli.Command) []cli.Command {
+	return c
+}
+
 // root must be an absolute path
 func newController(c *cli.Context, root string) (*control.Controller, error) {
 	socket := c.GlobalString("containerd")
 
-	return control.NewContainerd(root, socket)
+	cfg, err := controllerConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return control.NewContainerd(root, socket, cfg)
 }
@@ -3,15 +3,56 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
 	"github.com/tonistiigi/buildkit_poc/control"
 	"github.com/urfave/cli"
+	"golang.org/x/net/context"
 )
 
 func appendFlags(f []cli.Flag) []cli.Flag {
-	return f
+	return append(f, []cli.Flag{
+		cli.StringFlag{
+			Name:  "snapshotter",
+			Usage: "snapshotter backend: " + strings.Join(control.Snapshotters, ", ") + ", defaults to the one of the state directory",
+		},
+	}...)
+}
+
+func appendCommands(c []cli.Command) []cli.Command {
+	return append(c, cli.Command{
+		Name:  "migrate",
+		Usage: "move the snapshots of the state directory to another snapshotter, buildd must not be running",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "to",
+				Usage: "target snapshotter: " + strings.Join(control.Snapshotters, ", "),
+			},
+		},
+		Action: migrate,
+	})
+}
+
+func migrate(c *cli.Context) error {
+	root, err := filepath.Abs(c.GlobalString("root"))
+	if err != nil {
+		return err
+	}
+	to := c.String("to")
+	if to == "" {
+		return errors.New("--to is required")
+	}
+	return control.MigrateStandalone(context.Background(), root, to, os.Stdout)
 }
 
 // root must be an absolute path
 func newController(c *cli.Context, root string) (*control.Controller, error) {
-	return control.NewStandalone(root)
+	cfg, err := controllerConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return control.NewStandalone(root, c.GlobalString("snapshotter"), cfg)
 }
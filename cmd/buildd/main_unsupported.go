@@ -13,6 +13,10 @@ func appendFlags(f []cli.Flag) []cli.Flag {
 	return f
 }
 
+func appendCommands(c []cli.Command) []cli.Command {
+	return c
+}
+
 func newController(c *cli.Context, root string) (*control.Controller, error) {
 	return nil, errors.New("invalid build")
 }
@@ -0,0 +1,71 @@
+package control
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/cache/cachemount"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+	"golang.org/x/net/context"
+)
+
+// checkCacheMounts rejects cache mount imports and exports without IDs and
+// local directories the daemon doesn't allow
+func (c *Controller) checkCacheMounts(req *controlapi.SolveRequest) error {
+	if len(req.CacheMounts) == 0 && (req.CacheMountsImport != "" || req.CacheMountsExport != "") {
+		return errors.New("importing or exporting cache mounts requires their IDs")
+	}
+	for _, p := range []string{req.CacheMountsImport, req.CacheMountsExport} {
+		if p != "" && cachemount.IsLocal(p) && !c.cacheMountDirAllowed(p) {
+			return errors.Errorf("cache mount directory %s is not allowed by the daemon", p)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) cacheMountDirAllowed(p string) bool {
+	p = filepath.Clean(p)
+	for _, d := range c.opt.CacheMountDirs {
+		d = filepath.Clean(d)
+		if p == d || strings.HasPrefix(p, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Controller) cacheMountOpt() cachemount.Opt {
+	return cachemount.Opt{
+		CacheAccessor: c.opt.CacheManager,
+		ContentStore:  c.opt.ContentStore,
+		Resolver: docker.NewResolver(docker.ResolverOptions{
+			Client: http.DefaultClient,
+		}),
+	}
+}
+
+// importCacheMounts seeds the empty cache mounts of the request. Cache mounts
+// that can't be imported are reported as warnings and start empty.
+func (c *Controller) importCacheMounts(ctx context.Context, req *controlapi.SolveRequest) {
+	if req.CacheMountsImport == "" {
+		return
+	}
+	errs := cachemount.Import(ctx, c.cacheMountOpt(), req.CacheMounts, req.CacheMountsImport)
+	for _, id := range req.CacheMounts {
+		if err, ok := errs[id]; ok {
+			warnings.Warn(ctx, warnings.SeverityWarning, "cache mount %s was not imported: %v", id, err)
+		}
+	}
+}
+
+func (c *Controller) exportCacheMounts(ctx context.Context, req *controlapi.SolveRequest) error {
+	if req.CacheMountsExport == "" {
+		return nil
+	}
+	err := cachemount.Export(ctx, c.cacheMountOpt(), req.CacheMounts, req.CacheMountsExport)
+	return errors.Wrapf(err, "failed to export cache mounts to %s", req.CacheMountsExport)
+}
@@ -0,0 +1,29 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+func TestCheckCacheMounts(t *testing.T) {
+	c := &Controller{opt: Opt{Config: Config{CacheMountDirs: []string{"/var/cache/buildkit"}}}}
+
+	for _, req := range []*controlapi.SolveRequest{
+		{},
+		{CacheMounts: []string{"go"}},
+		{CacheMounts: []string{"go"}, CacheMountsImport: "/var/cache/buildkit", CacheMountsExport: "/var/cache/buildkit/go/"},
+		{CacheMounts: []string{"go"}, CacheMountsImport: "docker.io/library/cache:latest"},
+	} {
+		assert.NoError(t, c.checkCacheMounts(req))
+	}
+
+	for _, req := range []*controlapi.SolveRequest{
+		{CacheMountsExport: "/var/cache/buildkit"},
+		{CacheMounts: []string{"go"}, CacheMountsImport: "/var/cache/buildkit-other"},
+		{CacheMounts: []string{"go"}, CacheMountsExport: "/var/cache/buildkit/../../../etc"},
+	} {
+		assert.Error(t, c.checkCacheMounts(req))
+	}
+}
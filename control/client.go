@@ -0,0 +1,18 @@
+package control
+
+import (
+	"fmt"
+
+	"github.com/tonistiigi/buildkit_poc/util/peercred"
+	"golang.org/x/net/context"
+)
+
+// clientID identifies the client of a request by its user as uid:<uid>. The
+// daemon only listens on a unix socket. It is empty if the client can't be
+// identified.
+func clientID(ctx context.Context) string {
+	if ai, ok := peercred.FromContext(ctx); ok {
+		return fmt.Sprintf("uid:%d", ai.UID)
+	}
+	return ""
+}
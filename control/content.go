@@ -0,0 +1,43 @@
+package control
+
+import (
+	"io"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+const readContentChunkSize = 32 * 1024
+
+// ReadContent streams a blob of the content store, like the images written
+// by exporters, to the client
+func (c *Controller) ReadContent(req *controlapi.ReadContentRequest, stream controlapi.Control_ReadContentServer) error {
+	if c.opt.ContentStore == nil {
+		return errors.New("reading content is not supported")
+	}
+	dgst, err := digest.Parse(req.Digest)
+	if err != nil {
+		return errors.Wrapf(err, "invalid digest %s", req.Digest)
+	}
+	rc, err := c.opt.ContentStore.Reader(stream.Context(), dgst)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", dgst)
+	}
+	defer rc.Close()
+	buf := make([]byte, readContentChunkSize)
+	for {
+		n, err := rc.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&controlapi.ReadContentResponse{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", dgst)
+		}
+	}
+}
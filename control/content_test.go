@@ -0,0 +1,55 @@
+package control
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestReadContent(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "readcontenttest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	dt := bytes.Repeat([]byte("foo"), readContentChunkSize)
+	dgst := digest.FromBytes(dt)
+	assert.NoError(t, content.WriteBlob(context.TODO(), cs, "test", bytes.NewReader(dt), int64(len(dt)), dgst))
+
+	c := &Controller{opt: Opt{ContentStore: cs}}
+	stream := &testReadContentStream{}
+	assert.NoError(t, c.ReadContent(&controlapi.ReadContentRequest{Digest: dgst.String()}, stream))
+	assert.Equal(t, dt, stream.buf.Bytes())
+	assert.True(t, stream.msgs > 1)
+
+	err = c.ReadContent(&controlapi.ReadContentRequest{Digest: digest.FromBytes([]byte("bar")).String()}, stream)
+	assert.Error(t, err)
+	err = c.ReadContent(&controlapi.ReadContentRequest{Digest: "invalid"}, stream)
+	assert.Error(t, err)
+}
+
+type testReadContentStream struct {
+	grpc.ServerStream
+	buf  bytes.Buffer
+	msgs int
+}
+
+func (s *testReadContentStream) Context() context.Context {
+	return context.TODO()
+}
+
+func (s *testReadContentStream) Send(resp *controlapi.ReadContentResponse) error {
+	s.msgs++
+	_, err := s.buf.Write(resp.Data)
+	return err
+}
@@ -1,12 +1,28 @@
 package control
 
 import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/snapshot"
 	"github.com/pkg/errors"
 	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
 	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/exporter"
+	"github.com/tonistiigi/buildkit_poc/frontend"
 	"github.com/tonistiigi/buildkit_poc/solver"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
 	"github.com/tonistiigi/buildkit_poc/source"
+	"github.com/tonistiigi/buildkit_poc/source/local"
+	"github.com/tonistiigi/buildkit_poc/util/convert"
+	"github.com/tonistiigi/buildkit_poc/util/egressproxy"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+	"github.com/tonistiigi/buildkit_poc/util/llbsign"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
 	"github.com/tonistiigi/buildkit_poc/worker"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
@@ -17,11 +33,86 @@ type Opt struct {
 	CacheManager  cache.Manager
 	Worker        worker.Worker
 	SourceManager *source.Manager
+	LocalSource   *local.Source
+	ContentStore  content.Store
+	Converter     *convert.Converter
+	// JournalDir keeps the state of active solves for resuming them after
+	// a restart. Solves are not journaled if empty.
+	JournalDir string
+	// HistoryDir keeps a record of the finished solves. There is no build
+	// history if empty.
+	HistoryDir string
+	Config
+}
+
+// Config contains daemon settings that don't depend on the backend
+type Config struct {
+	// Frontends build requests that select them by name instead of sending
+	// a definition
+	Frontends map[string]frontend.Frontend
+	// Exporters export the results of requests that select them by name.
+	// The daemon adds its built-in exporters to them.
+	Exporters map[string]exporter.Exporter
+	// Verifier checks signatures of definitions if set
+	Verifier *llbsign.Verifier
+	// RequireSigned rejects definitions without a valid signature
+	RequireSigned bool
+	// AllowedSigners are the trusted keys whose signatures are accepted.
+	// Definitions not signed by one of them are rejected if set.
+	AllowedSigners []string
+	// ClientSigners replace AllowedSigners for the clients they are set for,
+	// keyed by the client identity used for the request limits
+	ClientSigners map[string][]string
+	// AllowedDevices are the host devices exec steps may use with the device
+	// entitlement
+	AllowedDevices []string
+	// CacheMountDirs are the local directories requests may export cache
+	// mounts to and import them from. Registry references are always allowed.
+	CacheMountDirs []string
+	// CgroupParent is the cgroup exec containers are created under, a path
+	// or a systemd slice[:prefix]
+	CgroupParent string
+	// EgressAllow lists the hosts exec steps can reach through the egress
+	// proxy. Requests can only narrow it down.
+	EgressAllow []string
+	// HTTPCache is the mode of the download cache, record or replay. The
+	// cache is disabled if empty. Only plain HTTP is cached, HTTPS is
+	// tunneled.
+	HTTPCache string
+	// DumpGraph logs the optimized graph of every solve
+	DumpGraph bool
+	Limits    Limits
 }
 
 type Controller struct { // TODO: ControlService
-	opt    Opt
-	solver *solver.Solver
+	opt     Opt
+	solver  *solver.Solver
+	history *history
+
+	mu       sync.Mutex
+	statuses map[string]*solveStatus
+	// statusAdded is closed and replaced when a status is added
+	statusAdded chan struct{}
+	// clientSolves counts the active solves of every client
+	clientSolves map[string]int
+	closed       bool
+	// resumeCtx is canceled on Close to stop the resumed solves
+	resumeCtx    context.Context
+	cancelResume func()
+}
+
+// withExporters returns the config with the exporters added to the
+// configured ones. Configured exporters replace the ones with the same name.
+func (cfg Config) withExporters(exporters map[string]exporter.Exporter) Config {
+	m := make(map[string]exporter.Exporter, len(exporters)+len(cfg.Exporters))
+	for name, e := range exporters {
+		m[name] = e
+	}
+	for name, e := range cfg.Exporters {
+		m[name] = e
+	}
+	cfg.Exporters = m
+	return cfg
 }
 
 func NewController(opt Opt) (*Controller, error) {
@@ -31,11 +122,38 @@ func NewController(opt Opt) (*Controller, error) {
 			SourceManager: opt.SourceManager,
 			CacheManager:  opt.CacheManager,
 			Worker:        opt.Worker,
+			DumpGraph:     opt.DumpGraph,
 		}),
+		statuses:     make(map[string]*solveStatus),
+		statusAdded:  make(chan struct{}),
+		clientSolves: make(map[string]int),
+	}
+	if opt.HistoryDir != "" {
+		c.history = newHistory(opt.HistoryDir)
+	}
+	c.resumeCtx, c.cancelResume = context.WithCancel(context.Background())
+	if err := c.resume(); err != nil {
+		return nil, err
 	}
 	return c, nil
 }
 
+// Close stops the resumed solves. Solves interrupted after Close keep their
+// journal and are resumed on the next start.
+func (c *Controller) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	c.cancelResume()
+	return nil
+}
+
+func (c *Controller) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
 func (c *Controller) Register(server *grpc.Server) error {
 	controlapi.RegisterControlServer(server, c)
 	return nil
@@ -59,13 +177,417 @@ func (c *Controller) DiskUsage(ctx context.Context, _ *controlapi.DiskUsageReque
 	return resp, nil
 }
 
+func (c *Controller) Info(ctx context.Context, _ *controlapi.InfoRequest) (*controlapi.InfoResponse, error) {
+	resp := &controlapi.InfoResponse{}
+	for name := range c.opt.Exporters {
+		resp.Exporters = append(resp.Exporters, name)
+	}
+	for name := range c.opt.Frontends {
+		resp.Frontends = append(resp.Frontends, name)
+	}
+	sort.Strings(resp.Exporters)
+	sort.Strings(resp.Frontends)
+	return resp, nil
+}
+
+func (c *Controller) History(ctx context.Context, req *controlapi.HistoryRequest) (*controlapi.HistoryResponse, error) {
+	if c.history == nil {
+		return nil, errors.New("build history is not enabled")
+	}
+	recs, err := c.history.records(req.Ref)
+	if err != nil {
+		return nil, err
+	}
+	resp := &controlapi.HistoryResponse{}
+	for _, rec := range recs {
+		br := &controlapi.BuildRecord{
+			Ref:         rec.Ref,
+			Signer:      rec.Signer,
+			Frontend:    rec.Frontend,
+			Exporter:    rec.Exporter,
+			StartedAt:   rec.StartedAt.UnixNano(),
+			CompletedAt: rec.CompletedAt.UnixNano(),
+			Error:       rec.Error,
+		}
+		for _, a := range rec.FileAccess {
+			br.FileAccess = append(br.FileAccess, &controlapi.VertexFileAccess{
+				Vertex: a.Vertex.String(),
+				Mount:  a.Mount,
+				Paths:  a.Paths,
+				Traced: a.Traced,
+			})
+		}
+		resp.Records = append(resp.Records, br)
+	}
+	return resp, nil
+}
+
 func (c *Controller) Solve(ctx context.Context, req *controlapi.SolveRequest) (*controlapi.SolveResponse, error) {
-	v, err := solver.Load(req.Definition)
+	done, err := c.startClientSolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if c.opt.LocalSource != nil {
+		if err := c.opt.LocalSource.Claim(req.Ref, clientID(ctx)); err != nil {
+			return nil, err
+		}
+		ctx = local.WithSolveRef(ctx, req.Ref)
+		defer c.opt.LocalSource.Release(req.Ref)
+	}
+	signer, err := c.verify(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkDefinitionLimits(req); err != nil {
+		return nil, err
+	}
+	var j *journal
+	if c.opt.JournalDir != "" {
+		if j, err = createJournal(c.opt.JournalDir, req, signer, c.readsUploads(req)); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.solve(ctx, req, signer, j)
+	if err != nil {
+		return nil, err
+	}
+	return &controlapi.SolveResponse{ExporterResponse: resp, Signer: signer}, nil
+}
+
+func (c *Controller) solve(ctx context.Context, req *controlapi.SolveRequest, signer string, j *journal) (_ map[string]string, retErr error) {
+	ctx, finish := c.withSolveDuration(ctx)
+	defer func() {
+		retErr = finish(retErr)
+	}()
+
+	st := c.newStatus(req.Ref)
+	defer c.finishStatus(req.Ref, st)
+	if c.history != nil {
+		started := time.Now()
+		defer func() {
+			// interrupted solves are recorded when they are resumed
+			if retErr != nil && c.isClosed() {
+				return
+			}
+			rec := historyRecord{
+				Ref:         req.Ref,
+				Signer:      signer,
+				Frontend:    req.Frontend,
+				Exporter:    req.Exporter,
+				StartedAt:   started,
+				CompletedAt: time.Now(),
+				FileAccess:  st.fileAccess(),
+			}
+			if retErr != nil {
+				rec.Error = retErr.Error()
+			}
+			if err := c.history.add(rec); err != nil {
+				logrus.Errorf("%+v", err)
+			}
+		}()
+	}
+	ctx = warnings.WithWriter(ctx, st)
+	ctx = resourceusage.WithRecorder(ctx, st)
+	if req.TraceFileAccess {
+		ctx = filetrace.WithRecorder(ctx, st)
+	}
+
+	if j != nil {
+		ctx = solver.WithJournal(ctx, j)
+		defer func() {
+			if retErr != nil && c.isClosed() {
+				return
+			}
+			if err := j.remove(); err != nil {
+				logrus.Errorf("%+v", err)
+			}
+		}()
+	}
+
+	if err := c.checkEntitlements(req); err != nil {
+		return nil, err
+	}
+	if err := c.checkCacheMounts(req); err != nil {
+		return nil, err
+	}
+	allow, err := c.egressAllow(req)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to load")
+		return nil, err
+	}
+	scope := egressproxy.NewScope(allow)
+	defer scope.Close()
+	ctx = egressproxy.WithScope(ctx, scope)
+	c.importCacheMounts(ctx, req)
+	defer func() {
+		if retErr == nil {
+			retErr = c.exportCacheMounts(ctx, req)
+		}
+	}()
+
+	var exp exporter.ExporterInstance
+	if req.Exporter != "" {
+		e, ok := c.opt.Exporters[req.Exporter]
+		if !ok {
+			return nil, errors.Errorf("exporter %s not found", req.Exporter)
+		}
+		if exp, err = e.Resolve(ctx, req.ExporterAttrs); err != nil {
+			return nil, err
+		}
 	}
-	if err := c.solver.Solve(ctx, v); err != nil {
+
+	var (
+		ref  cache.ImmutableRef
+		meta map[string][]byte
+	)
+	if req.Frontend != "" {
+		ref, meta, err = c.solveFrontend(ctx, req)
+	} else {
+		v, err := solver.Load(req.Definition)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load")
+		}
+		if err := c.checkDepth(solver.Depth(v)); err != nil {
+			return nil, err
+		}
+		if exp == nil {
+			return nil, c.solver.Solve(ctx, v)
+		}
+		if ref, err = c.solver.Build(ctx, v); err != nil {
+			return nil, err
+		}
+	}
+	if ref != nil {
+		defer ref.Release()
+	}
+	if err != nil || exp == nil {
 		return nil, err
 	}
-	return &controlapi.SolveResponse{}, nil
+	if ref == nil {
+		return nil, errors.New("no result to export")
+	}
+
+	resp, err := exp.Export(ctx, ref, meta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to export with %s", exp.Name())
+	}
+	return resp, nil
+}
+
+// resume restarts the solves that were interrupted by a daemon restart.
+// Clients can follow them with the status stream.
+func (c *Controller) resume() error {
+	if c.opt.JournalDir == "" {
+		return nil
+	}
+	journals, err := loadJournals(c.opt.JournalDir)
+	if err != nil {
+		return err
+	}
+	for _, j := range journals {
+		req := j.request()
+		if j.rec.Uploads {
+			c.failResume(j)
+			continue
+		}
+		logrus.Infof("resuming solve %s", req.Ref)
+		go func(j *journal) {
+			if _, err := c.solve(c.resumeCtx, req, j.rec.Signer, j); err != nil {
+				logrus.Errorf("resumed solve %s failed: %+v", req.Ref, err)
+				return
+			}
+			logrus.Infof("resumed solve %s completed", req.Ref)
+		}(j)
+	}
+	return nil
+}
+
+// failResume ends a solve that can't be resumed because it read contexts
+// uploaded by the client. The error is reported to clients following the
+// solve as a critical warning.
+func (c *Controller) failResume(j *journal) {
+	err := errors.Errorf("solve %s can't be resumed, the contexts uploaded by the client are lost on restart", j.rec.Ref)
+	logrus.Errorf("%v", err)
+	st := c.newStatus(j.rec.Ref)
+	st.Warn(warnings.Warning{Severity: warnings.SeverityCritical, Message: err.Error()})
+	c.finishStatus(j.rec.Ref, st)
+	if c.history != nil {
+		now := time.Now()
+		if err := c.history.add(historyRecord{
+			Ref:         j.rec.Ref,
+			Signer:      j.rec.Signer,
+			Frontend:    j.rec.Frontend,
+			Exporter:    j.rec.Exporter,
+			StartedAt:   now,
+			CompletedAt: now,
+			Error:       err.Error(),
+		}); err != nil {
+			logrus.Errorf("%+v", err)
+		}
+	}
+	if err := j.remove(); err != nil {
+		logrus.Errorf("%+v", err)
+	}
+}
+
+// readsUploads returns true if the solve reads contexts uploaded by the
+// client, either uploaded for its ref or as local sources of its definition
+func (c *Controller) readsUploads(req *controlapi.SolveRequest) bool {
+	if c.opt.LocalSource == nil {
+		return false
+	}
+	if c.opt.LocalSource.HasUploads(req.Ref) {
+		return true
+	}
+	for _, dt := range req.Definition {
+		var op pb.Op
+		if err := op.Unmarshal(dt); err != nil {
+			continue
+		}
+		if src := op.GetSource(); src != nil {
+			id, err := source.FromString(src.Identifier)
+			if _, ok := id.(*source.LocalIdentifier); ok && err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Controller) Status(req *controlapi.StatusRequest, stream controlapi.Control_StatusServer) error {
+	st, err := c.getStatus(stream.Context(), req.Ref)
+	if err != nil {
+		return err
+	}
+	for wi, ai, ui := 0, 0, 0; ; {
+		u, err := st.wait(stream.Context(), wi, ai, ui)
+		if err != nil {
+			return err
+		}
+		if len(u.warnings) > 0 || len(u.accesses) > 0 || len(u.usage) > 0 {
+			resp := &controlapi.StatusResponse{}
+			for _, w := range u.warnings {
+				vw := &controlapi.VertexWarning{
+					Vertex:   w.Vertex.String(),
+					Severity: int32(w.Severity),
+					Message:  w.Message,
+				}
+				if w.Filename != "" {
+					vw.Location = &controlapi.SourceLocation{
+						Filename: w.Filename,
+						Line:     int32(w.Line),
+					}
+				}
+				resp.Warnings = append(resp.Warnings, vw)
+			}
+			for _, a := range u.accesses {
+				resp.FileAccess = append(resp.FileAccess, &controlapi.VertexFileAccess{
+					Vertex: a.Vertex.String(),
+					Mount:  a.Mount,
+					Paths:  a.Paths,
+					Traced: a.Traced,
+				})
+			}
+			for _, ru := range u.usage {
+				resp.Usage = append(resp.Usage, &controlapi.VertexUsage{
+					Vertex:     ru.Vertex.String(),
+					CPUTime:    int64(ru.CPU),
+					PeakMemory: ru.Memory,
+				})
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+			wi += len(u.warnings)
+			ai += len(u.accesses)
+			ui += len(u.usage)
+		}
+		if u.done {
+			return nil
+		}
+	}
+}
+
+func (c *Controller) UploadContext(stream controlapi.Control_UploadContextServer) error {
+	if c.opt.LocalSource == nil {
+		return errors.New("uploading contexts is not supported")
+	}
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	r := &uploadReader{stream: stream, buf: msg.Data}
+	dgst, err := c.opt.LocalSource.Upload(stream.Context(), msg.Ref, clientID(stream.Context()), msg.Name, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload context %s", msg.Name)
+	}
+	return stream.SendAndClose(&controlapi.UploadContextResponse{Digest: dgst.String()})
+}
+
+type uploadReader struct {
+	stream controlapi.Control_UploadContextServer
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err // io.EOF when the client closes the stream
+		}
+		r.buf = msg.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// verify checks the signature of the request against the signers allowed
+// for the client and returns the identity of the signer. Definitions are
+// signed over their digest. Requests built by a frontend have no definition
+// and are signed over the frontend name and options.
+func (c *Controller) verify(ctx context.Context, req *controlapi.SolveRequest) (string, error) {
+	allowed := c.opt.AllowedSigners
+	if signers, ok := c.opt.ClientSigners[clientID(ctx)]; ok {
+		allowed = signers
+	}
+	required := c.opt.RequireSigned || len(allowed) > 0
+
+	if len(req.Signature) == 0 {
+		if required {
+			return "", errors.New("request is not signed")
+		}
+		return "", nil
+	}
+	if c.opt.Verifier == nil {
+		if required {
+			return "", errors.New("no trusted keys configured")
+		}
+		return "", nil
+	}
+	dgst := llbsign.FrontendDigest(req.Frontend, req.FrontendOpt)
+	if req.Frontend == "" {
+		var err error
+		if dgst, err = llbsign.Digest(req.Definition); err != nil {
+			return "", err
+		}
+	}
+	signer, err := c.opt.Verifier.VerifyDigest(dgst, req.Signature)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to verify request")
+	}
+	if len(allowed) > 0 {
+		ok := false
+		for _, s := range allowed {
+			if s == signer {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return "", errors.Errorf("request is signed by %s, which is not an allowed signer", signer)
+		}
+	}
+	logrus.Infof("solve %s signed by %s", req.Ref, signer)
+	return signer, nil
 }
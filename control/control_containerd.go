@@ -21,7 +21,7 @@ import (
 	"google.golang.org/grpc"
 )
 
-func NewContainerd(root, address string) (*Controller, error) {
+func NewContainerd(root, address string, cfg Config) (*Controller, error) {
 	if err := os.MkdirAll(root, 0700); err != nil {
 		return nil, errors.Wrapf(err, "failed to create %s", root)
 	}
@@ -45,12 +45,22 @@ func NewContainerd(root, address string) (*Controller, error) {
 		return nil, err
 	}
 
-	opt, err := defaultControllerOpts(root, *pd)
+	opt, err := defaultControllerOpts(root, *pd, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	w, err := runcworker.New(filepath.Join(root, "runc"))
+	hc, err := newHTTPCache(root, pd.ContentStore, cfg.HTTPCache)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := runcworker.New(runcworker.Opt{
+		Root:         filepath.Join(root, "runc"),
+		CgroupParent: cfg.CgroupParent,
+		EgressAllow:  cfg.EgressAllow,
+		HTTPCache:    hc,
+	})
 	if err != nil {
 		return nil, err
 	}
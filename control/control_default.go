@@ -9,9 +9,15 @@ import (
 	"github.com/containerd/containerd/rootfs"
 	ctdsnapshot "github.com/containerd/containerd/snapshot"
 	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/exporter"
+	imageexporter "github.com/tonistiigi/buildkit_poc/exporter/containerimage"
+	"github.com/tonistiigi/buildkit_poc/exporter/fsimage"
 	"github.com/tonistiigi/buildkit_poc/snapshot/blobmapping"
 	"github.com/tonistiigi/buildkit_poc/source"
 	"github.com/tonistiigi/buildkit_poc/source/containerimage"
+	"github.com/tonistiigi/buildkit_poc/source/local"
+	"github.com/tonistiigi/buildkit_poc/util/convert"
+	"github.com/tonistiigi/buildkit_poc/util/egressproxy"
 )
 
 type pullDeps struct {
@@ -20,7 +26,22 @@ type pullDeps struct {
 	Applier      rootfs.Applier
 }
 
-func defaultControllerOpts(root string, pd pullDeps) (*Opt, error) {
+func newHTTPCache(root string, cs content.Store, mode string) (*egressproxy.Cache, error) {
+	if mode == "" {
+		return nil, nil
+	}
+	m, err := egressproxy.ParseCacheMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	return egressproxy.NewCache(egressproxy.CacheOpt{
+		Root:         filepath.Join(root, "httpcache"),
+		ContentStore: cs,
+		Mode:         m,
+	})
+}
+
+func defaultControllerOpts(root string, pd pullDeps, cfg Config) (*Opt, error) {
 	snapshotter, err := blobmapping.NewSnapshotter(blobmapping.Opt{
 		Root:        filepath.Join(root, "blobmap"),
 		Content:     pd.ContentStore,
@@ -55,9 +76,47 @@ func defaultControllerOpts(root string, pd pullDeps) (*Opt, error) {
 
 	sm.Register(is)
 
+	ls, err := local.NewSource(local.SourceOpt{
+		CacheAccessor: cm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Register(ls)
+
+	converter := convert.New(convert.Opt{
+		ContentStore: pd.ContentStore,
+		BlobMapper:   snapshotter,
+	})
+
+	fsimageExporter, err := fsimage.New(fsimage.Opt{
+		ContentStore: pd.ContentStore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	imageExporter, err := imageexporter.New(imageexporter.Opt{
+		Snapshotter:  snapshotter,
+		ContentStore: pd.ContentStore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &Opt{
 		Snapshotter:   snapshotter,
 		CacheManager:  cm,
 		SourceManager: sm,
+		LocalSource:   ls,
+		ContentStore:  pd.ContentStore,
+		Converter:     converter,
+		JournalDir:    filepath.Join(root, "solves"),
+		HistoryDir:    filepath.Join(root, "history"),
+		Config: cfg.withExporters(map[string]exporter.Exporter{
+			fsimage.ExporterName:       fsimageExporter,
+			imageexporter.ExporterName: imageExporter,
+		}),
 	}, nil
 }
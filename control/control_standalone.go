@@ -4,40 +4,76 @@ package control
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
-	"github.com/containerd/containerd/archive"
 	"github.com/containerd/containerd/archive/compression"
 	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/mount"
+	ctdsnapshot "github.com/containerd/containerd/snapshot"
+	"github.com/containerd/containerd/snapshot/naive"
 	"github.com/containerd/containerd/snapshot/overlay"
 	digest "github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/snapshot/blobmapping"
+	"github.com/tonistiigi/buildkit_poc/snapshot/migrate"
+	"github.com/tonistiigi/buildkit_poc/util/sparse"
 	"github.com/tonistiigi/buildkit_poc/worker/runcworker"
 )
 
-func NewStandalone(root string) (*Controller, error) {
+// NewStandalone returns a controller that runs builds with runc. snapshotter
+// selects the snapshotter backend, the one the state directory uses if
+// empty.
+func NewStandalone(root, snapshotter string, cfg Config) (*Controller, error) {
 	if err := os.MkdirAll(root, 0700); err != nil {
 		return nil, errors.Wrapf(err, "failed to create %s", root)
 	}
 
 	// TODO: take lock to make sure there are no duplicates
 
-	pd, err := newPullDeps(root)
+	current, err := stateSnapshotter(root)
 	if err != nil {
 		return nil, err
 	}
+	if snapshotter == "" {
+		snapshotter = current
+	}
+	if snapshotter != current {
+		if _, err := os.Stat(snapshotterDir(root, current)); err == nil {
+			return nil, errors.Errorf("state directory uses the %s snapshotter, run buildd migrate --to %s first", current, snapshotter)
+		}
+		if err := setStateSnapshotter(root, snapshotter); err != nil {
+			return nil, err
+		}
+	}
 
-	opt, err := defaultControllerOpts(root, *pd)
+	pd, err := newPullDeps(root, snapshotter)
 	if err != nil {
 		return nil, err
 	}
 
-	w, err := runcworker.New(filepath.Join(root, "runc"))
+	opt, err := defaultControllerOpts(root, *pd, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := newHTTPCache(root, pd.ContentStore, cfg.HTTPCache)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := runcworker.New(runcworker.Opt{
+		Root:         filepath.Join(root, "runc"),
+		CgroupParent: cfg.CgroupParent,
+		EgressAllow:  cfg.EgressAllow,
+		HTTPCache:    hc,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -47,8 +83,8 @@ func NewStandalone(root string) (*Controller, error) {
 	return NewController(*opt)
 }
 
-func newPullDeps(root string) (*pullDeps, error) {
-	s, err := overlay.NewSnapshotter(filepath.Join(root, "snapshots"))
+func newPullDeps(root, snapshotter string) (*pullDeps, error) {
+	s, err := newSnapshotter(root, snapshotter)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +103,125 @@ func newPullDeps(root string) (*pullDeps, error) {
 	}, nil
 }
 
+// Snapshotters are the snapshotter backends of the standalone controller
+var Snapshotters = []string{"overlay", "naive"}
+
+const (
+	defaultSnapshotter = "overlay"
+	// snapshotterFile records the snapshotter of the state directory
+	snapshotterFile = "snapshotter"
+)
+
+func newSnapshotter(root, name string) (ctdsnapshot.Snapshotter, error) {
+	switch name {
+	case "overlay":
+		return overlay.NewSnapshotter(snapshotterDir(root, name))
+	case "naive":
+		return naive.NewSnapshotter(snapshotterDir(root, name))
+	}
+	return nil, errors.Errorf("unknown snapshotter %s, supported: %s", name, strings.Join(Snapshotters, ", "))
+}
+
+func snapshotterDir(root, name string) string {
+	// overlay snapshots were kept here before the snapshotter could be
+	// selected
+	if name == defaultSnapshotter {
+		return filepath.Join(root, "snapshots")
+	}
+	return filepath.Join(root, "snapshots-"+name)
+}
+
+// stateSnapshotter returns the snapshotter used by the state directory
+func stateSnapshotter(root string) (string, error) {
+	dt, err := ioutil.ReadFile(filepath.Join(root, snapshotterFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return defaultSnapshotter, nil
+		}
+		return "", errors.Wrap(err, "failed to read snapshotter of state directory")
+	}
+	return strings.TrimSpace(string(dt)), nil
+}
+
+func setStateSnapshotter(root, name string) error {
+	p := filepath.Join(root, snapshotterFile)
+	if err := ioutil.WriteFile(p+".tmp", []byte(name+"\n"), 0600); err != nil {
+		return errors.Wrap(err, "failed to write snapshotter of state directory")
+	}
+	return errors.Wrap(os.Rename(p+".tmp", p), "failed to write snapshotter of state directory")
+}
+
+// MigrateStandalone moves the snapshots of a state directory to another
+// snapshotter. The daemon must not be running. Snapshots keep their names so
+// the cache and blob mappings stay valid. Mappings of snapshots that don't
+// exist after the migration are dropped and reported. The state directory
+// is switched to the new snapshotter after all snapshots have been copied,
+// an interrupted migration continues where it stopped when run again.
+// Progress is written to w. The snapshots of the old snapshotter are not
+// removed.
+func MigrateStandalone(ctx context.Context, root, to string, w io.Writer) error {
+	from, err := stateSnapshotter(root)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return errors.Errorf("state directory already uses the %s snapshotter", to)
+	}
+	src, err := newSnapshotter(root, from)
+	if err != nil {
+		return err
+	}
+	dest, err := newSnapshotter(root, to)
+	if err != nil {
+		return err
+	}
+	if err := migrate.Migrate(ctx, migrate.Opt{From: src, To: dest, Progress: w}); err != nil {
+		return err
+	}
+	if err := pruneMetadata(ctx, root, dest, w); err != nil {
+		return err
+	}
+	if err := setStateSnapshotter(root, to); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "migrated to %s, %s can be removed\n", to, snapshotterDir(root, from))
+	return nil
+}
+
+// pruneMetadata drops the blob mappings and cache mounts of snapshots that
+// don't exist after the migration, like the ones whose snapshot was already
+// missing. Every dropped record is reported to w.
+func pruneMetadata(ctx context.Context, root string, sn ctdsnapshot.Snapshotter, w io.Writer) error {
+	cs, err := content.NewStore(filepath.Join(root, "content"))
+	if err != nil {
+		return err
+	}
+	bm, err := blobmapping.NewSnapshotter(blobmapping.Opt{
+		Root:        filepath.Join(root, "blobmap"),
+		Content:     cs,
+		Snapshotter: sn,
+	})
+	if err != nil {
+		return err
+	}
+	defer bm.Close()
+	names, err := bm.Prune(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to check blob mappings")
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "blob mapping of %s dropped, the snapshot doesn't exist\n", name)
+	}
+	ids, err := cache.PruneCacheMounts(filepath.Join(root, "cachemanager"), sn)
+	if err != nil {
+		return errors.Wrap(err, "failed to check cache mounts")
+	}
+	for _, id := range ids {
+		fmt.Fprintf(w, "cache mount %s dropped, its snapshot doesn't exist\n", id)
+	}
+	return nil
+}
+
 // this should be exposed by containerd
 type localApplier struct {
 	root    string
@@ -102,7 +257,7 @@ func (a *localApplier) Apply(ctx context.Context, desc ocispec.Descriptor, mount
 		r: io.TeeReader(ds, digester.Hash()),
 	}
 
-	if _, err := archive.Apply(ctx, dir, rc); err != nil {
+	if _, err := sparse.Apply(ctx, dir, rc); err != nil {
 		return ocispec.Descriptor{}, err
 	}
 
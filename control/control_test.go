@@ -104,7 +104,7 @@ func TestControl(t *testing.T) {
 		assert.True(t, d.Size >= 8192)
 	}
 
-	w, err := runcworker.New(tmpdir)
+	w, err := runcworker.New(runcworker.Opt{Root: tmpdir})
 	assert.NoError(t, err)
 
 	meta := worker.Meta{
@@ -0,0 +1,55 @@
+package control
+
+import (
+	"net/http"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/convert"
+	"golang.org/x/net/context"
+)
+
+// ImageConvert pulls an image, converts it to other media types and layer
+// compression and pushes the result to the target reference
+func (c *Controller) ImageConvert(ctx context.Context, req *controlapi.ImageConvertRequest) (*controlapi.ImageConvertResponse, error) {
+	if c.opt.Converter == nil || c.opt.ContentStore == nil {
+		return nil, errors.New("image conversion requires a content store")
+	}
+	compression, err := convert.ParseCompression(req.Compression)
+	if err != nil {
+		return nil, err
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Client:      http.DefaultClient,
+		Credentials: credentials(req.Auth),
+	})
+
+	desc, err := convert.Fetch(ctx, c.opt.ContentStore, resolver, req.Source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %s", req.Source)
+	}
+	desc, err = c.opt.Converter.Convert(ctx, desc, convert.Target{
+		OCI:         req.OCI,
+		Compression: compression,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := convert.Push(ctx, c.opt.ContentStore, resolver, req.Target, desc); err != nil {
+		return nil, errors.Wrapf(err, "failed to push %s", req.Target)
+	}
+	return &controlapi.ImageConvertResponse{Digest: desc.Digest.String()}, nil
+}
+
+// credentials returns the registry credentials sent with a request
+func credentials(auth []*controlapi.RegistryAuth) func(string) (string, string, error) {
+	return func(host string) (string, string, error) {
+		for _, a := range auth {
+			if a.Host == host {
+				return a.Username, a.Secret, nil
+			}
+		}
+		return "", "", nil
+	}
+}
@@ -0,0 +1,31 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+func TestCredentials(t *testing.T) {
+	creds := credentials([]*controlapi.RegistryAuth{
+		{Host: "registry-1.docker.io", Username: "user", Secret: "pass"},
+		{Host: "registry.example.com", Secret: "token"},
+	})
+
+	user, secret, err := creds("registry-1.docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass", secret)
+
+	user, secret, err = creds("registry.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "", user)
+	assert.Equal(t, "token", secret)
+
+	// hosts without credentials are accessed anonymously
+	user, secret, err = creds("other.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "", user)
+	assert.Equal(t, "", secret)
+}
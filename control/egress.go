@@ -0,0 +1,26 @@
+package control
+
+import (
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/egressproxy"
+)
+
+// egressAllow returns the hosts the exec steps of a solve can reach. A
+// request can narrow down the hosts allowed by the daemon but can't add to
+// them. The hosts of the daemon are used if the request doesn't list any.
+// All hosts are allowed when recording downloads without an allowlist.
+func (c *Controller) egressAllow(req *controlapi.SolveRequest) ([]string, error) {
+	if len(req.EgressAllow) == 0 {
+		return c.opt.EgressAllow, nil
+	}
+	if len(c.opt.EgressAllow) == 0 && c.opt.HTTPCache == "record" {
+		return req.EgressAllow, nil
+	}
+	for _, h := range req.EgressAllow {
+		if !egressproxy.Covers(c.opt.EgressAllow, h) {
+			return nil, errors.Errorf("network access to %s is not allowed by the daemon", h)
+		}
+	}
+	return req.EgressAllow, nil
+}
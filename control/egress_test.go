@@ -0,0 +1,36 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+func TestEgressAllow(t *testing.T) {
+	c := &Controller{opt: Opt{Config: Config{EgressAllow: []string{"mirror.example.com", "*.golang.org"}}}}
+
+	allow, err := c.egressAllow(&controlapi.SolveRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mirror.example.com", "*.golang.org"}, allow)
+
+	// requests narrow down the hosts of the daemon
+	allow, err = c.egressAllow(&controlapi.SolveRequest{EgressAllow: []string{"proxy.golang.org"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"proxy.golang.org"}, allow)
+
+	_, err = c.egressAllow(&controlapi.SolveRequest{EgressAllow: []string{"proxy.golang.org", "example.com"}})
+	assert.Error(t, err)
+	_, err = c.egressAllow(&controlapi.SolveRequest{EgressAllow: []string{"*.example.com"}})
+	assert.Error(t, err)
+
+	// all hosts are allowed when recording downloads without an allowlist
+	c.opt.EgressAllow = nil
+	c.opt.HTTPCache = "record"
+	allow, err = c.egressAllow(&controlapi.SolveRequest{EgressAllow: []string{"example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, allow)
+	c.opt.HTTPCache = "replay"
+	_, err = c.egressAllow(&controlapi.SolveRequest{EgressAllow: []string{"example.com"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package control
+
+import (
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+)
+
+// EntitlementDevice allows exec steps to use the host devices allowed by the
+// daemon
+const EntitlementDevice = "device"
+
+func (c *Controller) checkEntitlements(req *controlapi.SolveRequest) error {
+	entitlements := make(map[string]struct{})
+	for _, e := range req.Entitlements {
+		switch e {
+		case EntitlementDevice:
+		default:
+			return errors.Errorf("unknown entitlement %s", e)
+		}
+		entitlements[e] = struct{}{}
+	}
+
+	allowed := make(map[string]struct{})
+	for _, d := range c.opt.AllowedDevices {
+		allowed[d] = struct{}{}
+	}
+
+	for _, dt := range req.Definition {
+		var op pb.Op
+		if err := (&op).Unmarshal(dt); err != nil {
+			return errors.Wrap(err, "failed to parse op")
+		}
+		exec := op.GetExec()
+		if exec == nil || exec.Meta == nil {
+			continue
+		}
+		for _, d := range exec.Meta.Devices {
+			if _, ok := entitlements[EntitlementDevice]; !ok {
+				return errors.Errorf("using device %s requires the %s entitlement", d, EntitlementDevice)
+			}
+			if _, ok := allowed[d]; !ok {
+				return errors.Errorf("device %s is not allowed by the daemon", d)
+			}
+		}
+	}
+	return nil
+}
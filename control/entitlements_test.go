@@ -0,0 +1,33 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/client/llb"
+)
+
+func TestCheckEntitlements(t *testing.T) {
+	c := &Controller{opt: Opt{Config: Config{AllowedDevices: []string{"/dev/fuse"}}}}
+
+	img := llb.Image("docker.io/library/busybox:latest")
+	def, err := img.Run(llb.Meta{Args: []string{"true"}}).Marshal()
+	assert.NoError(t, err)
+	err = c.checkEntitlements(&controlapi.SolveRequest{Definition: def})
+	assert.NoError(t, err)
+
+	def, err = img.Run(llb.Meta{Args: []string{"true"}, Devices: []string{"/dev/fuse"}}).Marshal()
+	assert.NoError(t, err)
+	err = c.checkEntitlements(&controlapi.SolveRequest{Definition: def})
+	assert.Error(t, err)
+	err = c.checkEntitlements(&controlapi.SolveRequest{Definition: def, Entitlements: []string{EntitlementDevice}})
+	assert.NoError(t, err)
+	err = c.checkEntitlements(&controlapi.SolveRequest{Definition: def, Entitlements: []string{"network"}})
+	assert.Error(t, err)
+
+	def, err = img.Run(llb.Meta{Args: []string{"true"}, Devices: []string{"/dev/kvm"}}).Marshal()
+	assert.NoError(t, err)
+	err = c.checkEntitlements(&controlapi.SolveRequest{Definition: def, Entitlements: []string{EntitlementDevice}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package control
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes/docker"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/solver"
+	"github.com/tonistiigi/buildkit_poc/util/rebase"
+)
+
+// maxSymlinks is the number of symlinks followed when reading a file
+const maxSymlinks = 255
+
+func (c *Controller) solveFrontend(ctx context.Context, req *controlapi.SolveRequest) (cache.ImmutableRef, map[string][]byte, error) {
+	f, ok := c.opt.Frontends[req.Frontend]
+	if !ok {
+		return nil, nil, errors.Errorf("frontend %s not found", req.Frontend)
+	}
+	if len(req.Definition) != 0 {
+		return nil, nil, errors.Errorf("frontend %s can't be used with a definition", req.Frontend)
+	}
+	ref, meta, err := f.Solve(ctx, &llbBridge{c: c, req: req}, req.FrontendOpt)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "frontend %s failed", req.Frontend)
+	}
+	return ref, meta, nil
+}
+
+// llbBridge solves the definitions of a frontend with the entitlements and
+// limits of the request that selected it
+type llbBridge struct {
+	c   *Controller
+	req *controlapi.SolveRequest
+}
+
+func (b *llbBridge) Solve(ctx context.Context, def [][]byte) (cache.ImmutableRef, error) {
+	req := &controlapi.SolveRequest{Definition: def, Entitlements: b.req.Entitlements}
+	if err := b.c.checkEntitlements(req); err != nil {
+		return nil, err
+	}
+	if err := b.c.checkDefinitionLimits(req); err != nil {
+		return nil, err
+	}
+	v, err := solver.Load(def)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load")
+	}
+	if err := b.c.checkDepth(solver.Depth(v)); err != nil {
+		return nil, err
+	}
+	return b.c.solver.Build(ctx, v)
+}
+
+func (b *llbBridge) ResolveImageConfig(ctx context.Context, ref string) (digest.Digest, []byte, error) {
+	cs := b.c.opt.ContentStore
+	if cs == nil {
+		return "", nil, errors.New("resolving images requires a content store")
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Client: http.DefaultClient,
+	})
+	desc, err := rebase.Fetch(ctx, cs, resolver, ref, false)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to fetch %s", ref)
+	}
+	config, err := (&images.Image{Target: desc}).Config(ctx, cs)
+	if err != nil {
+		return "", nil, err
+	}
+	dt, err := content.ReadBlob(ctx, cs, config.Digest)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to read config of %s", ref)
+	}
+	return desc.Digest, dt, nil
+}
+
+func (b *llbBridge) ReadFile(ctx context.Context, ref cache.ImmutableRef, p string) ([]byte, error) {
+	mounts, err := ref.Mount()
+	if err != nil {
+		return nil, err
+	}
+	lm := snapshot.LocalMounter(mounts)
+	root, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer lm.Unmount()
+
+	fp, err := rootPath(root, p)
+	if err != nil {
+		return nil, err
+	}
+	dt, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", p)
+	}
+	return dt, nil
+}
+
+// rootPath resolves p inside of root. Symlinks are followed as if root was
+// the root directory so the result never points outside of it.
+func rootPath(root, p string) (string, error) {
+	resolved := "/"
+	parts := strings.Split(p, "/")
+	links := 0
+	for len(parts) > 0 {
+		part := parts[0]
+		parts = parts[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, part)
+		fi, err := os.Lstat(filepath.Join(root, next))
+		if err != nil {
+			if os.IsNotExist(err) {
+				resolved = next
+				continue
+			}
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if links++; links > maxSymlinks {
+			return "", errors.Errorf("too many symlinks in %s", p)
+		}
+		target, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			resolved = "/"
+		}
+		parts = append(strings.Split(target, "/"), parts...)
+	}
+	return filepath.Join(root, resolved), nil
+}
@@ -0,0 +1,190 @@
+package control
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/mount"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/client/llb"
+	"github.com/tonistiigi/buildkit_poc/exporter"
+	"github.com/tonistiigi/buildkit_poc/frontend"
+)
+
+func TestFrontend(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "frontendtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpdir, "foo"), []byte("foo"), 0600))
+	assert.NoError(t, os.Symlink("../../foo", filepath.Join(tmpdir, "rel")))
+
+	ref := &testRef{dir: tmpdir}
+	f := &testFrontend{ref: ref}
+	c, err := NewController(Opt{Config: Config{Frontends: map[string]frontend.Frontend{"test": f}}})
+	assert.NoError(t, err)
+
+	req := &controlapi.SolveRequest{
+		Ref:         "ref",
+		Frontend:    "test",
+		FrontendOpt: map[string]string{"file": "rel"},
+	}
+	_, err = c.solve(context.TODO(), req, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(f.dt))
+	assert.True(t, ref.released)
+
+	// definitions of the frontend are checked for entitlements
+	def, err := llb.Image("docker.io/library/busybox:latest").Run(llb.Meta{Args: []string{"true"}, Devices: []string{"/dev/fuse"}}).Marshal()
+	assert.NoError(t, err)
+	_, err = (&llbBridge{c: c, req: req}).Solve(context.TODO(), def)
+	assert.Error(t, err)
+
+	// and for the limits of the daemon
+	def, err = llb.Image("docker.io/library/busybox:latest").Run(llb.Meta{Args: []string{"true"}}).Marshal()
+	assert.NoError(t, err)
+	c.opt.Limits = Limits{MaxOps: 1}
+	_, err = (&llbBridge{c: c, req: req}).Solve(context.TODO(), def)
+	assert.Contains(t, err.Error(), "the limit is 1")
+	c.opt.Limits = Limits{MaxDepth: 1}
+	_, err = (&llbBridge{c: c, req: req}).Solve(context.TODO(), def)
+	assert.Contains(t, err.Error(), "deep, the limit is 1")
+	c.opt.Limits = Limits{}
+
+	_, err = c.solve(context.TODO(), &controlapi.SolveRequest{Ref: "ref", Frontend: "test", Definition: def}, "", nil)
+	assert.Error(t, err)
+	_, err = c.solve(context.TODO(), &controlapi.SolveRequest{Ref: "ref", Frontend: "unknown"}, "", nil)
+	assert.Error(t, err)
+}
+
+func TestFrontendExport(t *testing.T) {
+	ref := &testRef{}
+	e := &testExporter{}
+	c, err := NewController(Opt{Config: Config{
+		Frontends: map[string]frontend.Frontend{"test": &testFrontend{ref: ref}},
+		Exporters: map[string]exporter.Exporter{"test": e},
+	}})
+	assert.NoError(t, err)
+
+	info, err := c.Info(context.TODO(), &controlapi.InfoRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test"}, info.Exporters)
+	assert.Equal(t, []string{"test"}, info.Frontends)
+
+	resp, err := c.Solve(context.TODO(), &controlapi.SolveRequest{
+		Ref:           "ref",
+		Frontend:      "test",
+		Exporter:      "test",
+		ExporterAttrs: map[string]string{"foo": "bar"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar"}, resp.ExporterResponse)
+	assert.Equal(t, ref, e.ref)
+	assert.Equal(t, map[string][]byte{"file": nil}, e.meta)
+	assert.True(t, ref.released)
+
+	_, err = c.Solve(context.TODO(), &controlapi.SolveRequest{Ref: "ref", Frontend: "test", Exporter: "test", ExporterAttrs: map[string]string{"invalid": ""}})
+	assert.Error(t, err)
+	_, err = c.Solve(context.TODO(), &controlapi.SolveRequest{Ref: "ref", Frontend: "test", Exporter: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestWithExporters(t *testing.T) {
+	builtin, other, custom := &testExporter{}, &testExporter{}, &testExporter{}
+	cfg := Config{Exporters: map[string]exporter.Exporter{"image": custom}}
+	merged := cfg.withExporters(map[string]exporter.Exporter{"image": builtin, "fsimage": other})
+	assert.Equal(t, 2, len(merged.Exporters))
+	assert.True(t, merged.Exporters["image"] == custom)
+	assert.True(t, merged.Exporters["fsimage"] == other)
+	assert.Equal(t, 1, len(cfg.Exporters))
+}
+
+func TestRootPath(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "rootpath")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(tmpdir, "a/b"), 0700))
+	assert.NoError(t, os.Symlink("/a", filepath.Join(tmpdir, "abs")))
+	assert.NoError(t, os.Symlink("../../../a/b", filepath.Join(tmpdir, "a/rel")))
+	assert.NoError(t, os.Symlink("loop", filepath.Join(tmpdir, "loop")))
+
+	for p, expected := range map[string]string{
+		"a/b/c":        "a/b/c",
+		"/abs/b":       "a/b",
+		"abs/rel/c":    "a/b/c",
+		"../../a/../a": "a",
+		"/":            "",
+	} {
+		resolved, err := rootPath(tmpdir, p)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpdir, expected), resolved, p)
+	}
+
+	_, err = rootPath(tmpdir, "loop")
+	assert.Error(t, err)
+}
+
+type testFrontend struct {
+	ref cache.ImmutableRef
+	dt  []byte
+}
+
+func (f *testFrontend) Solve(ctx context.Context, llb frontend.FrontendLLBBridge, opt map[string]string) (cache.ImmutableRef, map[string][]byte, error) {
+	var dt []byte
+	if p, ok := opt["file"]; ok {
+		var err error
+		if dt, err = llb.ReadFile(ctx, f.ref, p); err != nil {
+			return nil, nil, err
+		}
+	}
+	f.dt = dt
+	return f.ref, map[string][]byte{"file": dt}, nil
+}
+
+type testExporter struct {
+	ref  cache.ImmutableRef
+	meta map[string][]byte
+}
+
+func (e *testExporter) Resolve(ctx context.Context, attrs map[string]string) (exporter.ExporterInstance, error) {
+	if _, ok := attrs["invalid"]; ok {
+		return nil, errors.New("invalid attribute")
+	}
+	return &testExporterInstance{testExporter: e, attrs: attrs}, nil
+}
+
+type testExporterInstance struct {
+	*testExporter
+	attrs map[string]string
+}
+
+func (e *testExporterInstance) Name() string {
+	return "test"
+}
+
+func (e *testExporterInstance) Export(ctx context.Context, ref cache.ImmutableRef, meta map[string][]byte) (map[string]string, error) {
+	e.ref, e.meta = ref, meta
+	return e.attrs, nil
+}
+
+type testRef struct {
+	cache.ImmutableRef
+	dir      string
+	released bool
+}
+
+func (r *testRef) Mount() ([]mount.Mount, error) {
+	return []mount.Mount{{Type: "bind", Source: r.dir, Options: []string{"rbind", "ro"}}}, nil
+}
+
+func (r *testRef) Release() error {
+	r.released = true
+	return nil
+}
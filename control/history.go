@@ -0,0 +1,133 @@
+package control
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+)
+
+// historyLimit is the number of finished solves kept in the build history
+const historyLimit = 1000
+
+// history keeps a record of every finished solve on disk
+type history struct {
+	mu    sync.Mutex
+	dir   string
+	limit int
+}
+
+type historyRecord struct {
+	Ref string
+	// Signer is the identity of the key that signed the request, empty if it
+	// was not signed
+	Signer      string
+	Frontend    string
+	Exporter    string
+	StartedAt   time.Time
+	CompletedAt time.Time
+	Error       string
+	// FileAccess are the files the exec steps read, if they were traced
+	FileAccess []filetrace.Access
+}
+
+func newHistory(dir string) *history {
+	return &history{dir: dir, limit: historyLimit}
+}
+
+// add writes a record and removes the oldest ones above the limit
+func (h *history) add(rec historyRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return errors.Wrapf(err, "failed to create %s", h.dir)
+	}
+	dt, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal history record")
+	}
+	// refs are chosen by the client so they are not used as file names
+	p := filepath.Join(h.dir, digest.FromString(rec.Ref).Hex()+".json")
+	tmp := p + ".tmp"
+	if err := ioutil.WriteFile(tmp, dt, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %s", tmp)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return errors.Wrapf(err, "failed to write %s", p)
+	}
+	return h.prune()
+}
+
+// hold lock before calling
+func (h *history) prune() error {
+	files, err := h.files()
+	if err != nil || len(files) <= h.limit {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, fi := range files[:len(files)-h.limit] {
+		p := filepath.Join(h.dir, fi.Name())
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove %s", p)
+		}
+	}
+	return nil
+}
+
+func (h *history) files() ([]os.FileInfo, error) {
+	files, err := ioutil.ReadDir(h.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read %s", h.dir)
+	}
+	var out []os.FileInfo
+	for _, fi := range files {
+		if strings.HasSuffix(fi.Name(), ".json") {
+			out = append(out, fi)
+		}
+	}
+	return out, nil
+}
+
+// records returns the records sorted by completion time. Only the record of
+// ref is returned if it is set.
+func (h *history) records(ref string) ([]historyRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	files, err := h.files()
+	if err != nil {
+		return nil, err
+	}
+	var recs []historyRecord
+	for _, fi := range files {
+		if ref != "" && fi.Name() != digest.FromString(ref).Hex()+".json" {
+			continue
+		}
+		p := filepath.Join(h.dir, fi.Name())
+		dt, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", p)
+		}
+		var rec historyRecord
+		if err := json.Unmarshal(dt, &rec); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", p)
+		}
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].CompletedAt.Before(recs[j].CompletedAt)
+	})
+	return recs, nil
+}
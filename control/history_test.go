@@ -0,0 +1,75 @@
+package control
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/frontend"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+)
+
+func TestHistory(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "historytest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	c, err := NewController(Opt{
+		HistoryDir: filepath.Join(tmpdir, "history"),
+		Config: Config{Frontends: map[string]frontend.Frontend{
+			"test":   &testFrontend{ref: &testRef{}},
+			"access": &accessFrontend{},
+		}},
+	})
+	assert.NoError(t, err)
+	c.history.limit = 2
+
+	resp, err := c.History(context.TODO(), &controlapi.HistoryRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(resp.Records))
+
+	ctx := context.TODO()
+	_, err = c.solve(ctx, &controlapi.SolveRequest{Ref: "foo", Frontend: "access", TraceFileAccess: true}, "ci", nil)
+	assert.NoError(t, err)
+	_, err = c.solve(context.TODO(), &controlapi.SolveRequest{Ref: "bar", Frontend: "unknown"}, "", nil)
+	assert.Error(t, err)
+
+	resp, err = c.History(context.TODO(), &controlapi.HistoryRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resp.Records))
+	assert.Equal(t, "foo", resp.Records[0].Ref)
+	assert.Equal(t, "ci", resp.Records[0].Signer)
+	assert.Equal(t, "access", resp.Records[0].Frontend)
+	assert.Equal(t, "", resp.Records[0].Error)
+	assert.Equal(t, []*controlapi.VertexFileAccess{{Vertex: "sha256:foo", Mount: "/", Paths: []string{"etc/passwd"}, Traced: true}}, resp.Records[0].FileAccess)
+	assert.True(t, resp.Records[0].CompletedAt >= resp.Records[0].StartedAt)
+	assert.Equal(t, "bar", resp.Records[1].Ref)
+	assert.Contains(t, resp.Records[1].Error, "frontend unknown not found")
+
+	resp, err = c.History(context.TODO(), &controlapi.HistoryRequest{Ref: "bar"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(resp.Records))
+	assert.Equal(t, "bar", resp.Records[0].Ref)
+
+	// the oldest records are removed above the limit
+	_, err = c.solve(context.TODO(), &controlapi.SolveRequest{Ref: "baz", Frontend: "test"}, "", nil)
+	assert.NoError(t, err)
+	recs, err := c.history.records("")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(recs))
+	assert.Equal(t, "bar", recs[0].Ref)
+	assert.Equal(t, "baz", recs[1].Ref)
+}
+
+// accessFrontend records a file access like a traced exec step
+type accessFrontend struct{}
+
+func (f *accessFrontend) Solve(ctx context.Context, llb frontend.FrontendLLBBridge, opt map[string]string) (cache.ImmutableRef, map[string][]byte, error) {
+	filetrace.Record(ctx, filetrace.Access{Vertex: "sha256:foo", Mount: "/", Paths: []string{"etc/passwd"}, Traced: true})
+	return nil, nil, nil
+}
@@ -0,0 +1,148 @@
+package control
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+)
+
+// journal records an active solve on disk so that it can be resumed after
+// the daemon restarts
+type journal struct {
+	mu   sync.Mutex
+	path string
+	rec  journalRecord
+}
+
+type journalRecord struct {
+	Ref             string
+	Definition      [][]byte
+	Entitlements    []string
+	Frontend        string
+	FrontendOpt     map[string]string
+	Exporter        string
+	ExporterAttrs   map[string]string
+	TraceFileAccess bool
+	EgressAllow     []string
+	// Signer is the identity of the key that signed the request
+	Signer string
+	// Uploads is set if the solve reads build contexts uploaded by the
+	// client. Uploads are only kept in memory, so the solve can't be resumed.
+	Uploads  bool
+	Vertices map[digest.Digest][]string
+}
+
+func createJournal(dir string, req *controlapi.SolveRequest, signer string, uploads bool) (*journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", dir)
+	}
+	j := &journal{
+		// refs are chosen by the client so they are not used as file names
+		path: filepath.Join(dir, digest.FromString(req.Ref).Hex()+".json"),
+		rec: journalRecord{
+			Ref:             req.Ref,
+			Definition:      req.Definition,
+			Entitlements:    req.Entitlements,
+			Frontend:        req.Frontend,
+			FrontendOpt:     req.FrontendOpt,
+			Exporter:        req.Exporter,
+			ExporterAttrs:   req.ExporterAttrs,
+			TraceFileAccess: req.TraceFileAccess,
+			EgressAllow:     req.EgressAllow,
+			Signer:          signer,
+			Uploads:         uploads,
+			Vertices:        make(map[digest.Digest][]string),
+		},
+	}
+	if err := j.write(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func loadJournals(dir string) ([]*journal, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+	var journals []*journal
+	for _, fi := range files {
+		if !strings.HasSuffix(fi.Name(), ".json") {
+			continue
+		}
+		p := filepath.Join(dir, fi.Name())
+		dt, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", p)
+		}
+		j := &journal{path: p}
+		if err := json.Unmarshal(dt, &j.rec); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", p)
+		}
+		if j.rec.Vertices == nil {
+			j.rec.Vertices = make(map[digest.Digest][]string)
+		}
+		journals = append(journals, j)
+	}
+	return journals, nil
+}
+
+func (j *journal) request() *controlapi.SolveRequest {
+	return &controlapi.SolveRequest{
+		Ref:             j.rec.Ref,
+		Definition:      j.rec.Definition,
+		Entitlements:    j.rec.Entitlements,
+		Frontend:        j.rec.Frontend,
+		FrontendOpt:     j.rec.FrontendOpt,
+		Exporter:        j.rec.Exporter,
+		ExporterAttrs:   j.rec.ExporterAttrs,
+		TraceFileAccess: j.rec.TraceFileAccess,
+		EgressAllow:     j.rec.EgressAllow,
+	}
+}
+
+func (j *journal) Results(dgst digest.Digest) ([]string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ids, ok := j.rec.Vertices[dgst]
+	return ids, ok
+}
+
+func (j *journal) Record(dgst digest.Digest, ids []string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.rec.Vertices[dgst] = ids
+	return j.write()
+}
+
+// hold lock before calling
+func (j *journal) write() error {
+	dt, err := json.Marshal(j.rec)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal journal")
+	}
+	tmp := j.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, dt, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %s", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, j.path), "failed to write %s", j.path)
+}
+
+func (j *journal) remove() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove %s", j.path)
+	}
+	return nil
+}
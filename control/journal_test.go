@@ -0,0 +1,84 @@
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+	"golang.org/x/net/context"
+)
+
+func TestJournal(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "journaltest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	journals, err := loadJournals(tmpdir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(journals))
+
+	req := &controlapi.SolveRequest{
+		Ref:          "../foo",
+		Definition:   [][]byte{[]byte("op1"), []byte("op2")},
+		Entitlements: []string{EntitlementDevice},
+	}
+	j, err := createJournal(tmpdir, req, "ci", false)
+	assert.NoError(t, err)
+
+	dgst := digest.FromBytes([]byte("op1"))
+	_, ok := j.Results(dgst)
+	assert.False(t, ok)
+	err = j.Record(dgst, []string{"foo", "bar"})
+	assert.NoError(t, err)
+	err = j.Record(digest.FromBytes([]byte("op2")), []string{})
+	assert.NoError(t, err)
+
+	journals, err = loadJournals(tmpdir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(journals))
+	assert.Equal(t, req, journals[0].request())
+	assert.Equal(t, "ci", journals[0].rec.Signer)
+
+	ids, ok := journals[0].Results(dgst)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"foo", "bar"}, ids)
+	_, ok = journals[0].Results(digest.FromBytes([]byte("op2")))
+	assert.True(t, ok)
+
+	err = journals[0].remove()
+	assert.NoError(t, err)
+	journals, err = loadJournals(tmpdir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(journals))
+}
+
+func TestResumeUploads(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "journaltest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	_, err = createJournal(tmpdir, &controlapi.SolveRequest{Ref: "foo", Definition: [][]byte{[]byte("op1")}}, "", true)
+	assert.NoError(t, err)
+
+	// solves that read uploads fail instead of being resumed
+	c, err := NewController(Opt{JournalDir: tmpdir})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	journals, err := loadJournals(tmpdir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(journals))
+
+	st, err := c.getStatus(context.TODO(), "foo")
+	assert.NoError(t, err)
+	u, err := st.wait(context.TODO(), 0, 0, 0)
+	assert.NoError(t, err)
+	assert.True(t, u.done)
+	assert.Equal(t, 1, len(u.warnings))
+	assert.Equal(t, warnings.SeverityCritical, u.warnings[0].Severity)
+	assert.Contains(t, u.warnings[0].Message, "can't be resumed")
+}
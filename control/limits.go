@@ -0,0 +1,117 @@
+package control
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"golang.org/x/net/context"
+)
+
+// Limits protect the daemon from requests that would use too many
+// resources. A zero value disables a limit.
+type Limits struct {
+	// MaxDefinitionSize is the maximum total size of the ops of a definition
+	// in bytes
+	MaxDefinitionSize int
+	// MaxOps is the maximum number of ops of a definition
+	MaxOps int
+	// MaxDepth is the maximum number of ops on a path through the graph of a
+	// definition
+	MaxDepth int
+	// MaxSolvesPerClient is the maximum number of concurrent solves of a
+	// client. Clients are identified by their user as uid:<uid>. Solves of
+	// clients that can't be identified are rejected when the limit is set.
+	MaxSolvesPerClient int
+	// MaxSolveDuration is the time after which solves are canceled
+	MaxSolveDuration time.Duration
+}
+
+const (
+	limitDefinitionSize = "definition_size"
+	limitOps            = "ops"
+	limitDepth          = "depth"
+	limitClientSolves   = "client_solves"
+	limitSolveDuration  = "solve_duration"
+)
+
+// limitMetrics counts the requests rejected or canceled by every limit. It
+// is published by expvar.
+var limitMetrics = expvar.NewMap("buildkit_limits")
+
+func (c *Controller) checkDefinitionLimits(req *controlapi.SolveRequest) error {
+	l := c.opt.Limits
+	if l.MaxOps > 0 && len(req.Definition) > l.MaxOps {
+		limitMetrics.Add(limitOps, 1)
+		return errors.Errorf("definition has %d ops, the limit is %d", len(req.Definition), l.MaxOps)
+	}
+	if l.MaxDefinitionSize > 0 {
+		size := 0
+		for _, dt := range req.Definition {
+			size += len(dt)
+		}
+		if size > l.MaxDefinitionSize {
+			limitMetrics.Add(limitDefinitionSize, 1)
+			return errors.Errorf("definition is %d bytes, the limit is %d", size, l.MaxDefinitionSize)
+		}
+	}
+	return nil
+}
+
+func (c *Controller) checkDepth(depth int) error {
+	if max := c.opt.Limits.MaxDepth; max > 0 && depth > max {
+		limitMetrics.Add(limitDepth, 1)
+		return errors.Errorf("definition is %d ops deep, the limit is %d", depth, max)
+	}
+	return nil
+}
+
+// startClientSolve counts a solve of the client of the request. The returned
+// function must be called when the solve finishes.
+func (c *Controller) startClientSolve(ctx context.Context) (func(), error) {
+	max := c.opt.Limits.MaxSolvesPerClient
+	if max <= 0 {
+		return func() {}, nil
+	}
+	id := clientID(ctx)
+	if id == "" {
+		limitMetrics.Add(limitClientSolves, 1)
+		return nil, errors.New("failed to identify the client for the limit of concurrent solves")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clientSolves[id] >= max {
+		limitMetrics.Add(limitClientSolves, 1)
+		return nil, errors.Errorf("client %s has reached the limit of %d concurrent solves", id, max)
+	}
+	c.clientSolves[id]++
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.clientSolves[id]--; c.clientSolves[id] == 0 {
+			delete(c.clientSolves, id)
+		}
+	}, nil
+}
+
+// withSolveDuration cancels the context after the maximum solve duration.
+// The returned function must be called with the error of the solve and
+// returns the error to report.
+func (c *Controller) withSolveDuration(ctx context.Context) (context.Context, func(error) error) {
+	d := c.opt.Limits.MaxSolveDuration
+	if d <= 0 {
+		return ctx, func(err error) error { return err }
+	}
+	sctx, cancel := context.WithTimeout(ctx, d)
+	return sctx, func(err error) error {
+		expired := sctx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+		if err != nil && expired {
+			limitMetrics.Add(limitSolveDuration, 1)
+			return errors.Errorf("solve exceeded the maximum duration of %s", d)
+		}
+		return err
+	}
+}
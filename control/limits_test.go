@@ -0,0 +1,67 @@
+package control
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/peercred"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+)
+
+func TestLimits(t *testing.T) {
+	c := &Controller{
+		opt: Opt{Config: Config{Limits: Limits{
+			MaxDefinitionSize:  10,
+			MaxOps:             2,
+			MaxDepth:           3,
+			MaxSolvesPerClient: 1,
+			MaxSolveDuration:   10 * time.Millisecond,
+		}}},
+		clientSolves: map[string]int{},
+	}
+
+	assert.NoError(t, c.checkDefinitionLimits(&controlapi.SolveRequest{Definition: [][]byte{[]byte("12345"), []byte("12345")}}))
+	assert.Error(t, c.checkDefinitionLimits(&controlapi.SolveRequest{Definition: [][]byte{[]byte("12345"), []byte("123456")}}))
+	assert.Error(t, c.checkDefinitionLimits(&controlapi.SolveRequest{Definition: [][]byte{{1}, {2}, {3}}}))
+	assert.NoError(t, c.checkDepth(3))
+	assert.Error(t, c.checkDepth(4))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.UnixAddr{Net: "unix"},
+		AuthInfo: peercred.AuthInfo{UID: 1000},
+	})
+	done, err := c.startClientSolve(ctx)
+	assert.NoError(t, err)
+	_, err = c.startClientSolve(ctx)
+	assert.Error(t, err)
+	// other users are counted separately
+	other := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: peercred.AuthInfo{UID: 1001}})
+	doneOther, err := c.startClientSolve(other)
+	assert.NoError(t, err)
+	doneOther()
+	done()
+	assert.Equal(t, 0, len(c.clientSolves))
+	done, err = c.startClientSolve(ctx)
+	assert.NoError(t, err)
+	done()
+
+	// clients without credentials can't be limited and are rejected
+	anon := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.UnixAddr{Net: "unix"}})
+	_, err = c.startClientSolve(anon)
+	assert.Error(t, err)
+
+	sctx, finish := c.withSolveDuration(context.Background())
+	<-sctx.Done()
+	err = finish(sctx.Err())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum duration")
+
+	_, finish = c.withSolveDuration(context.Background())
+	err = finish(errors.New("failed"))
+	assert.Equal(t, "failed", err.Error())
+}
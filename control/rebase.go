@@ -0,0 +1,29 @@
+package control
+
+import (
+	"net/http"
+
+	"github.com/containerd/containerd/remotes/docker"
+	"github.com/pkg/errors"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/rebase"
+	"golang.org/x/net/context"
+)
+
+// ImageRebase moves the application layers of an image onto a new base.
+// The resulting image is kept in the content store and pushed if a target
+// is set.
+func (c *Controller) ImageRebase(ctx context.Context, req *controlapi.ImageRebaseRequest) (*controlapi.ImageRebaseResponse, error) {
+	if c.opt.ContentStore == nil {
+		return nil, errors.New("image rebase requires a content store")
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Client: http.DefaultClient,
+	})
+
+	desc, err := rebase.RebaseRemote(ctx, c.opt.ContentStore, resolver, req.Image, req.OldBase, req.NewBase, req.Target)
+	if err != nil {
+		return nil, err
+	}
+	return &controlapi.ImageRebaseResponse{Digest: desc.Digest.String()}, nil
+}
@@ -0,0 +1,158 @@
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// statusRetention is how long the status of a finished solve can still be
+// read. The client may start reading the status after the solve has ended.
+const statusRetention = time.Minute
+
+// statusWait is how long a status request waits for its solve to start
+const statusWait = 10 * time.Second
+
+type solveStatus struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	warnings []warnings.Warning
+	accesses []filetrace.Access
+	usage    []resourceusage.Usage
+	done     bool
+}
+
+// statusUpdate is the part of a status a request hasn't sent yet
+type statusUpdate struct {
+	warnings []warnings.Warning
+	accesses []filetrace.Access
+	usage    []resourceusage.Usage
+	done     bool
+}
+
+func newSolveStatus() *solveStatus {
+	st := &solveStatus{}
+	st.cond = sync.NewCond(&st.mu)
+	return st
+}
+
+func (st *solveStatus) Warn(w warnings.Warning) {
+	st.mu.Lock()
+	st.warnings = append(st.warnings, w)
+	st.mu.Unlock()
+	st.cond.Broadcast()
+}
+
+func (st *solveStatus) RecordAccess(a filetrace.Access) {
+	st.mu.Lock()
+	st.accesses = append(st.accesses, a)
+	st.mu.Unlock()
+	st.cond.Broadcast()
+}
+
+func (st *solveStatus) RecordUsage(u resourceusage.Usage) {
+	st.mu.Lock()
+	st.usage = append(st.usage, u)
+	st.mu.Unlock()
+	st.cond.Broadcast()
+}
+
+func (st *solveStatus) fileAccess() []filetrace.Access {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]filetrace.Access{}, st.accesses...)
+}
+
+func (st *solveStatus) finish() {
+	st.mu.Lock()
+	st.done = true
+	st.mu.Unlock()
+	st.cond.Broadcast()
+}
+
+// wait returns the warnings after index wi, the file accesses after index ai
+// and the usage after index ui, blocking until there are new ones or the
+// solve has finished
+func (st *solveStatus) wait(ctx context.Context, wi, ai, ui int) (*statusUpdate, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// the waiter checks ctx with the lock held, so it can't miss this
+			st.mu.Lock()
+			st.cond.Broadcast()
+			st.mu.Unlock()
+		}
+	}()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for len(st.warnings) <= wi && len(st.accesses) <= ai && len(st.usage) <= ui && !st.done {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		st.cond.Wait()
+	}
+	return &statusUpdate{
+		warnings: st.warnings[wi:],
+		accesses: st.accesses[ai:],
+		usage:    st.usage[ui:],
+		done:     st.done,
+	}, nil
+}
+
+// newStatus creates the status for a solve ref and wakes up the status
+// requests waiting for it
+func (c *Controller) newStatus(ref string) *solveStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := newSolveStatus()
+	c.statuses[ref] = st
+	close(c.statusAdded)
+	c.statusAdded = make(chan struct{})
+	return st
+}
+
+// getStatus returns the status for a solve ref, waiting up to statusWait for
+// the solve to start
+func (c *Controller) getStatus(ctx context.Context, ref string) (*solveStatus, error) {
+	timer := time.NewTimer(statusWait)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		st, ok := c.statuses[ref]
+		added := c.statusAdded
+		c.mu.Unlock()
+		if ok {
+			return st, nil
+		}
+		select {
+		case <-added:
+		case <-timer.C:
+			return nil, grpc.Errorf(codes.NotFound, "no solve with ref %s", ref)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Controller) finishStatus(ref string, st *solveStatus) {
+	st.finish()
+	time.AfterFunc(statusRetention, func() {
+		c.mu.Lock()
+		if c.statuses[ref] == st {
+			delete(c.statuses, ref)
+		}
+		c.mu.Unlock()
+	})
+}
@@ -0,0 +1,62 @@
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"golang.org/x/net/context"
+)
+
+func TestStatus(t *testing.T) {
+	c := &Controller{
+		statuses:    map[string]*solveStatus{},
+		statusAdded: make(chan struct{}),
+	}
+
+	// a status request doesn't create an entry for an unknown ref
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err := c.getStatus(ctx, "foo")
+	cancel()
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(c.statuses))
+
+	// a status request waits for the solve to start
+	ch := make(chan *solveStatus)
+	go func() {
+		st, err := c.getStatus(context.Background(), "foo")
+		assert.NoError(t, err)
+		ch <- st
+	}()
+	time.Sleep(10 * time.Millisecond)
+	st := c.newStatus("foo")
+	select {
+	case got := <-ch:
+		assert.Equal(t, st, got)
+	case <-time.After(time.Second):
+		t.Fatal("status was not found")
+	}
+}
+
+func TestStatusWait(t *testing.T) {
+	st := newSolveStatus()
+	st.RecordUsage(resourceusage.Usage{Vertex: "sha256:foo", CPU: time.Second, Memory: 1 << 20})
+	u, err := st.wait(context.Background(), 0, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(u.usage))
+	assert.Equal(t, time.Second, u.usage[0].CPU)
+	assert.False(t, u.done)
+
+	// canceling the request wakes up the waiter
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = st.wait(ctx, 0, 0, 1)
+	assert.Equal(t, context.Canceled, err)
+
+	st.finish()
+	u, err = st.wait(context.Background(), 0, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(u.usage))
+	assert.True(t, u.done)
+}
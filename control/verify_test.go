@@ -0,0 +1,83 @@
+package control
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	controlapi "github.com/tonistiigi/buildkit_poc/api/services/control"
+	"github.com/tonistiigi/buildkit_poc/util/llbsign"
+	"github.com/tonistiigi/buildkit_poc/util/peercred"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/peer"
+)
+
+func TestVerify(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "verifytest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	def := [][]byte{[]byte("foo")}
+	frontendOpt := map[string]string{"target": "foo"}
+	sigs := map[string][]byte{}
+	frontendSigs := map[string][]byte{}
+	var paths []string
+	for _, name := range []string{"ci", "dev"} {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		dt, err := x509.MarshalPKIXPublicKey(key.Public())
+		assert.NoError(t, err)
+		p := filepath.Join(tmpdir, name+".pem")
+		assert.NoError(t, ioutil.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: dt}), 0600))
+		paths = append(paths, p)
+		sigs[name], err = llbsign.Sign(def, key)
+		assert.NoError(t, err)
+		frontendSigs[name], err = llbsign.SignDigest(llbsign.FrontendDigest("dockerfile", frontendOpt), key)
+		assert.NoError(t, err)
+	}
+	v, err := llbsign.LoadVerifier(paths)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	c := &Controller{opt: Opt{Config: Config{Verifier: v}}}
+	signer, err := c.verify(ctx, &controlapi.SolveRequest{Definition: def})
+	assert.NoError(t, err)
+	assert.Equal(t, "", signer)
+	signer, err = c.verify(ctx, &controlapi.SolveRequest{Definition: def, Signature: sigs["dev"]})
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", signer)
+
+	// frontend requests are signed over the frontend and its options
+	signer, err = c.verify(ctx, &controlapi.SolveRequest{Frontend: "dockerfile", FrontendOpt: frontendOpt, Signature: frontendSigs["dev"]})
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", signer)
+	_, err = c.verify(ctx, &controlapi.SolveRequest{Frontend: "dockerfile", FrontendOpt: map[string]string{"target": "bar"}, Signature: frontendSigs["dev"]})
+	assert.Error(t, err)
+	_, err = c.verify(ctx, &controlapi.SolveRequest{Frontend: "dockerfile", FrontendOpt: frontendOpt, Signature: sigs["dev"]})
+	assert.Error(t, err)
+
+	c.opt.AllowedSigners = []string{"ci"}
+	c.opt.ClientSigners = map[string][]string{"uid:1000": {"dev"}}
+	_, err = c.verify(ctx, &controlapi.SolveRequest{Definition: def})
+	assert.Error(t, err)
+	_, err = c.verify(ctx, &controlapi.SolveRequest{Definition: def, Signature: sigs["dev"]})
+	assert.Error(t, err)
+	signer, err = c.verify(ctx, &controlapi.SolveRequest{Definition: def, Signature: sigs["ci"]})
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", signer)
+
+	// the client policy replaces the allowed signers
+	ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: peercred.AuthInfo{UID: 1000}})
+	signer, err = c.verify(ctx, &controlapi.SolveRequest{Definition: def, Signature: sigs["dev"]})
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", signer)
+	_, err = c.verify(ctx, &controlapi.SolveRequest{Definition: def, Signature: sigs["ci"]})
+	assert.Error(t, err)
+}
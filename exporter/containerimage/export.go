@@ -0,0 +1,366 @@
+package containerimage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/exporter"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/snapshot/differ"
+	"github.com/tonistiigi/buildkit_poc/util/convert"
+	"github.com/tonistiigi/buildkit_poc/util/rebase"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// ExporterName is the name the exporter is registered with
+	ExporterName = "image"
+	// ConfigKey is the metadata key of the image config set by frontends.
+	// The rootfs of the config is replaced with the exported layers.
+	ConfigKey = "containerimage.config"
+)
+
+type Opt struct {
+	Snapshotter  snapshot.Snapshotter
+	ContentStore content.Store
+	// Resolver pushes images and fetches the bases of rebased images. A
+	// docker resolver without credentials is used if nil.
+	Resolver remotes.Resolver
+}
+
+// New returns an exporter that writes the result as an OCI image to the
+// content store, with a layer for every snapshot in the parent chain of the
+// result. The image is pushed if a name is set. With rebase-old and
+// rebase-new set, the layers of the base image rebase-old the result was
+// built on are replaced with the layers of rebase-new.
+func New(opt Opt) (exporter.Exporter, error) {
+	if opt.Snapshotter == nil || opt.ContentStore == nil {
+		return nil, errors.New("image exporter requires a snapshotter and a content store")
+	}
+	if opt.Resolver == nil {
+		opt.Resolver = docker.NewResolver(docker.ResolverOptions{
+			Client: http.DefaultClient,
+		})
+	}
+	return &imageExporter{opt: opt}, nil
+}
+
+type imageExporter struct {
+	opt Opt
+}
+
+func (e *imageExporter) Resolve(ctx context.Context, attrs map[string]string) (exporter.ExporterInstance, error) {
+	i := &imageExporterInstance{imageExporter: e, compression: "gzip"}
+	for k, v := range attrs {
+		switch k {
+		case "name":
+			i.name = v
+		case "push":
+			push, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, errors.Errorf("invalid push %s", v)
+			}
+			i.push = push
+		case "compression":
+			switch v {
+			case "uncompressed", "gzip":
+			case "estargz":
+				i.differOpt.Stargz = true
+			default:
+				return nil, errors.Errorf("invalid compression %s, must be uncompressed, gzip or estargz", v)
+			}
+			i.compression = v
+		case "compression-level":
+			level, err := strconv.Atoi(v)
+			if err != nil || level < gzip.HuffmanOnly || level > gzip.BestCompression || level == gzip.NoCompression {
+				return nil, errors.Errorf("invalid compression level %s", v)
+			}
+			i.differOpt.Level = level
+		case "compression-workers":
+			workers, err := strconv.Atoi(v)
+			if err != nil || workers <= 0 {
+				return nil, errors.Errorf("invalid compression worker count %s", v)
+			}
+			i.differOpt.Concurrency = workers
+		case "rebase-old":
+			i.rebaseOld = v
+		case "rebase-new":
+			i.rebaseNew = v
+		default:
+			return nil, errors.Errorf("unknown image exporter attribute %s", k)
+		}
+	}
+	if i.push && i.name == "" {
+		return nil, errors.New("pushing requires a name")
+	}
+	if (i.rebaseOld == "") != (i.rebaseNew == "") {
+		return nil, errors.New("rebasing requires rebase-old and rebase-new")
+	}
+	return i, nil
+}
+
+type imageExporterInstance struct {
+	*imageExporter
+	name        string
+	push        bool
+	compression string
+	differOpt   differ.Opt
+	rebaseOld   string
+	rebaseNew   string
+}
+
+func (i *imageExporterInstance) Name() string {
+	return ExporterName
+}
+
+func (i *imageExporterInstance) Export(ctx context.Context, ref cache.ImmutableRef, meta map[string][]byte) (map[string]string, error) {
+	chain, err := i.chain(ctx, ref.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		oldBase, newBase ocispec.Descriptor
+		base             []ocispec.Descriptor
+		baseDiffIDs      []digest.Digest
+	)
+	if i.rebaseOld != "" {
+		if oldBase, err = rebase.Fetch(ctx, i.opt.ContentStore, i.opt.Resolver, i.rebaseOld, false); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch %s", i.rebaseOld)
+		}
+		if newBase, err = rebase.Fetch(ctx, i.opt.ContentStore, i.opt.Resolver, i.rebaseNew, true); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch %s", i.rebaseNew)
+		}
+		if base, baseDiffIDs, err = i.baseLayers(ctx, chain, oldBase); err != nil {
+			return nil, err
+		}
+	}
+
+	media := ocispec.MediaTypeImageLayerGzip
+	if i.compression == "uncompressed" {
+		media = ocispec.MediaTypeImageLayer
+	}
+	opt := i.differOpt
+	opt.ContentStore = i.opt.ContentStore
+	d := differ.New(opt)
+
+	// layers are compressed concurrently, every one with its own workers.
+	// The layers of a base that is replaced are not diffed.
+	layers := make([]ocispec.Descriptor, len(chain))
+	copy(layers, base)
+	eg, ctx := errgroup.WithContext(ctx)
+	for j := len(base); j < len(chain); j++ {
+		j := j
+		eg.Go(func() error {
+			var parent string
+			if j > 0 {
+				parent = chain[j-1]
+			}
+			desc, err := i.diff(ctx, d, parent, chain[j], media)
+			if err != nil {
+				return errors.Wrapf(err, "failed to export layer %s", chain[j])
+			}
+			layers[j] = desc
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	diffIDs := make([]digest.Digest, len(layers))
+	copy(diffIDs, baseDiffIDs)
+	for j := len(base); j < len(layers); j++ {
+		l := layers[j]
+		diffIDs[j] = l.Digest
+		if dgst, ok := l.Annotations[differ.UncompressedAnnotation]; ok {
+			diffIDs[j] = digest.Digest(dgst)
+			delete(l.Annotations, differ.UncompressedAnnotation)
+			if len(l.Annotations) == 0 {
+				layers[j].Annotations = nil
+			}
+		}
+	}
+
+	config, err := imageConfig(meta[ConfigKey], diffIDs)
+	if err != nil {
+		return nil, err
+	}
+	configDesc, err := i.writeBlob(ctx, ocispec.MediaTypeImageConfig, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write image config")
+	}
+	mfst, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    configDesc,
+		Layers:    layers,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal manifest")
+	}
+	desc, err := i.writeBlob(ctx, ocispec.MediaTypeImageManifest, mfst)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write manifest")
+	}
+
+	if i.rebaseOld != "" {
+		if desc, err = rebase.Rebase(ctx, i.opt.ContentStore, desc, oldBase, newBase); err != nil {
+			return nil, errors.Wrapf(err, "failed to rebase onto %s", i.rebaseNew)
+		}
+	}
+
+	if i.push {
+		if err := convert.Push(ctx, i.opt.ContentStore, i.opt.Resolver, i.name, desc); err != nil {
+			return nil, errors.Wrapf(err, "failed to push %s", i.name)
+		}
+	}
+
+	resp := map[string]string{"digest": desc.Digest.String()}
+	if i.name != "" {
+		resp["name"] = i.name
+	}
+	return resp, nil
+}
+
+// chain returns the snapshot of the ref and its parents, base first
+func (i *imageExporterInstance) chain(ctx context.Context, key string) ([]string, error) {
+	var chain []string
+	for key != "" {
+		info, err := i.opt.Snapshotter.Stat(ctx, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to stat %s", key)
+		}
+		chain = append([]string{key}, chain...)
+		key = info.Parent
+	}
+	return chain, nil
+}
+
+// baseLayers returns the layers and diff IDs of the base image the result
+// was built on. Snapshots of pulled layers are named by their chain ID, so
+// the result is built on the base if its chain starts with the chain IDs of
+// the base.
+func (i *imageExporterInstance) baseLayers(ctx context.Context, chain []string, desc ocispec.Descriptor) ([]ocispec.Descriptor, []digest.Digest, error) {
+	dt, err := content.ReadBlob(ctx, i.opt.ContentStore, desc.Digest)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read manifest of %s", i.rebaseOld)
+	}
+	var mfst ocispec.Manifest
+	if err := json.Unmarshal(dt, &mfst); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse manifest of %s", i.rebaseOld)
+	}
+	diffIDs, err := (&images.Image{Target: desc}).RootFS(ctx, i.opt.ContentStore)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read rootfs of %s", i.rebaseOld)
+	}
+	if len(diffIDs) != len(mfst.Layers) {
+		return nil, nil, errors.Errorf("mismatched rootfs and manifest layers in %s", i.rebaseOld)
+	}
+	if len(diffIDs) > len(chain) {
+		return nil, nil, errors.Errorf("result has %d layers, %s has %d", len(chain), i.rebaseOld, len(diffIDs))
+	}
+	chainIDs := identity.ChainIDs(append([]digest.Digest{}, diffIDs...))
+	for j, id := range chainIDs {
+		if chain[j] != id.String() {
+			return nil, nil, errors.Errorf("result is not built on %s", i.rebaseOld)
+		}
+	}
+	return mfst.Layers, diffIDs, nil
+}
+
+// diff writes the changes of the snapshot key to its parent as a layer
+func (i *imageExporterInstance) diff(ctx context.Context, d *differ.Differ, parent, key, media string) (ocispec.Descriptor, error) {
+	upper, releaseUpper, err := i.view(ctx, key)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer releaseUpper()
+	ref := "export-" + key + "-" + uniqueSuffix()
+	// overlay snapshots keep their changes in a directory that doesn't need
+	// to be compared with the parent
+	if upperdir, ok := differ.SnapshotUpperdir(upper); ok {
+		return d.DiffUpperdir(ctx, upperdir, media, ref)
+	}
+	lower, releaseLower, err := i.view(ctx, parent)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer releaseLower()
+	return d.DiffMounts(ctx, lower, upper, media, ref)
+}
+
+// view returns read-only mounts of a committed snapshot. An empty key
+// returns an empty directory.
+func (i *imageExporterInstance) view(ctx context.Context, key string) ([]mount.Mount, func(), error) {
+	if key == "" {
+		dir, err := ioutil.TempDir("", "buildkit-export")
+		if err != nil {
+			return nil, nil, err
+		}
+		return []mount.Mount{{Type: "bind", Source: dir, Options: []string{"rbind", "ro"}}}, func() { os.RemoveAll(dir) }, nil
+	}
+	name := "export-view-" + key + "-" + uniqueSuffix()
+	mounts, err := i.opt.Snapshotter.View(ctx, name, key)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to view %s", key)
+	}
+	return mounts, func() { i.opt.Snapshotter.Remove(ctx, name) }, nil
+}
+
+func (i *imageExporterInstance) writeBlob(ctx context.Context, mediaType string, dt []byte) (ocispec.Descriptor, error) {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	if err := content.WriteBlob(ctx, i.opt.ContentStore, "export-"+desc.Digest.String(), bytes.NewReader(dt), desc.Size, desc.Digest); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// imageConfig returns the config set by the frontend, or a minimal one, with
+// the rootfs set to the exported layers
+func imageConfig(dt []byte, diffIDs []digest.Digest) ([]byte, error) {
+	config := map[string]interface{}{}
+	if len(dt) > 0 {
+		if err := json.Unmarshal(dt, &config); err != nil {
+			return nil, errors.Wrap(err, "failed to parse image config")
+		}
+		// the history of the frontend doesn't match the exported layers
+		delete(config, "history")
+	} else {
+		config["architecture"] = runtime.GOARCH
+		config["os"] = "linux"
+	}
+	config["rootfs"] = ocispec.RootFS{Type: "layers", DiffIDs: diffIDs}
+	return json.Marshal(config)
+}
+
+func uniqueSuffix() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,472 @@
+package containerimage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/containerd/containerd/archive/compression"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/remotes"
+	cdsnapshot "github.com/containerd/containerd/snapshot"
+	"github.com/containerd/containerd/snapshot/naive"
+	"github.com/containerd/containerd/snapshot/overlay"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/identity"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/util/estargz"
+	"github.com/tonistiigi/buildkit_poc/util/sparse"
+	"golang.org/x/sys/unix"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "imageexport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	sn, err := naive.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	writeSnapshot(t, sn, "base-active", "", func(dir string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo"), []byte("foo"), 0644))
+	})
+	assert.NoError(t, sn.Commit(ctx, "base", "base-active"))
+	writeSnapshot(t, sn, "child-active", "base", func(dir string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bar"), []byte("bar"), 0644))
+	})
+	assert.NoError(t, sn.Commit(ctx, "child", "child-active"))
+
+	e, err := New(Opt{Snapshotter: sn, ContentStore: cs})
+	assert.NoError(t, err)
+
+	_, err = e.Resolve(ctx, map[string]string{"compression": "lz4"})
+	assert.Error(t, err)
+	_, err = e.Resolve(ctx, map[string]string{"compression-workers": "0"})
+	assert.Error(t, err)
+	_, err = e.Resolve(ctx, map[string]string{"push": "true"})
+	assert.Error(t, err)
+
+	for _, compression := range []string{"gzip", "estargz", "uncompressed"} {
+		i, err := e.Resolve(ctx, map[string]string{
+			"compression":         compression,
+			"compression-level":   "1",
+			"compression-workers": "2",
+		})
+		assert.NoError(t, err)
+		resp, err := i.Export(ctx, &testRef{id: "child"}, map[string][]byte{
+			ConfigKey: []byte(`{"architecture":"arm64","os":"linux","config":{"Cmd":["sh"]}}`),
+		})
+		assert.NoError(t, err)
+
+		var mfst ocispec.Manifest
+		readJSON(t, cs, digest.Digest(resp["digest"]), &mfst)
+		var config struct {
+			Architecture string
+			Config       map[string]interface{}
+			RootFS       ocispec.RootFS
+		}
+		readJSON(t, cs, mfst.Config.Digest, &config)
+		assert.Equal(t, "arm64", config.Architecture)
+		assert.Equal(t, []interface{}{"sh"}, config.Config["Cmd"])
+
+		assert.Equal(t, 2, len(mfst.Layers))
+		assert.Equal(t, 2, len(config.RootFS.DiffIDs))
+		for j, name := range []string{"foo", "bar"} {
+			l := mfst.Layers[j]
+			if compression == "estargz" {
+				assert.NoError(t, digest.Digest(l.Annotations[estargz.TOCDigestAnnotation]).Validate())
+			} else {
+				assert.Nil(t, l.Annotations)
+			}
+			rc, err := cs.Reader(ctx, l.Digest)
+			assert.NoError(t, err)
+			var r io.Reader = rc
+			if compression != "uncompressed" {
+				assert.Equal(t, ocispec.MediaTypeImageLayerGzip, l.MediaType)
+				zr, err := gzip.NewReader(rc)
+				assert.NoError(t, err)
+				r = zr
+			} else {
+				assert.Equal(t, ocispec.MediaTypeImageLayer, l.MediaType)
+			}
+			digester := digest.Canonical.Digester()
+			tr := tar.NewReader(io.TeeReader(r, digester.Hash()))
+			var names []string
+			for {
+				h, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+				names = append(names, h.Name)
+			}
+			if compression == "estargz" {
+				// the landmark and the table of contents surround the files
+				assert.Equal(t, estargz.TOCName, names[len(names)-1])
+				names = names[1 : len(names)-1]
+			}
+			io.Copy(ioutil.Discard, r)
+			rc.Close()
+			assert.Equal(t, []string{name}, names)
+			assert.Equal(t, config.RootFS.DiffIDs[j], digester.Digest())
+		}
+	}
+}
+
+type testRef struct {
+	cache.ImmutableRef
+	id string
+}
+
+func (r *testRef) ID() string {
+	return r.id
+}
+
+func writeSnapshot(t *testing.T, sn cdsnapshot.Snapshotter, key, parent string, fn func(dir string)) {
+	mounts, err := sn.Prepare(context.TODO(), key, parent)
+	assert.NoError(t, err)
+	lm := snapshot.LocalMounter(mounts)
+	dir, err := lm.Mount()
+	assert.NoError(t, err)
+	fn(dir)
+	assert.NoError(t, lm.Unmount())
+}
+
+func readJSON(t *testing.T, cs content.Store, dgst digest.Digest, v interface{}) {
+	dt, err := content.ReadBlob(context.TODO(), cs, dgst)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(dt, v))
+}
+
+func TestExportRebase(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "imageexport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	registry, err := content.NewStore(filepath.Join(tmpdir, "registry"))
+	assert.NoError(t, err)
+	r := &testResolver{cs: registry, refs: map[string]ocispec.Descriptor{
+		"old": writeImage(t, registry, "base1", "base2"),
+		"new": writeImage(t, registry, "base1", "base2-patched", "base3"),
+	}}
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	sn, err := naive.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	// pulled snapshots are named by their chain ID
+	chainIDs := identity.ChainIDs([]digest.Digest{diffID("base1"), diffID("base2")})
+	parent := ""
+	for _, id := range chainIDs {
+		writeSnapshot(t, sn, id.String()+"-active", parent, func(dir string) {})
+		assert.NoError(t, sn.Commit(ctx, id.String(), id.String()+"-active"))
+		parent = id.String()
+	}
+	writeSnapshot(t, sn, "app-active", parent, func(dir string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app"), []byte("app"), 0644))
+	})
+	assert.NoError(t, sn.Commit(ctx, "app", "app-active"))
+
+	e, err := New(Opt{Snapshotter: sn, ContentStore: cs, Resolver: r})
+	assert.NoError(t, err)
+
+	_, err = e.Resolve(ctx, map[string]string{"rebase-old": "old"})
+	assert.Error(t, err)
+
+	// the result must be built on the old base
+	i, err := e.Resolve(ctx, map[string]string{"rebase-old": "new", "rebase-new": "old"})
+	assert.NoError(t, err)
+	_, err = i.Export(ctx, &testRef{id: "app"}, nil)
+	assert.Error(t, err)
+
+	i, err = e.Resolve(ctx, map[string]string{"rebase-old": "old", "rebase-new": "new"})
+	assert.NoError(t, err)
+	resp, err := i.Export(ctx, &testRef{id: "app"}, nil)
+	assert.NoError(t, err)
+
+	var mfst ocispec.Manifest
+	readJSON(t, cs, digest.Digest(resp["digest"]), &mfst)
+	var config struct {
+		RootFS ocispec.RootFS
+	}
+	readJSON(t, cs, mfst.Config.Digest, &config)
+	assert.Equal(t, 4, len(mfst.Layers))
+	assert.Equal(t, 4, len(config.RootFS.DiffIDs))
+	for j, l := range []string{"base1", "base2-patched", "base3"} {
+		assert.Equal(t, blob(l), mfst.Layers[j].Digest)
+		assert.Equal(t, diffID(l), config.RootFS.DiffIDs[j])
+		// the layers of the new base are fetched
+		_, err := cs.Info(ctx, blob(l))
+		assert.NoError(t, err)
+	}
+	_, err = cs.Info(ctx, mfst.Layers[3].Digest)
+	assert.NoError(t, err)
+}
+
+// testResolver serves the images of a content store
+type testResolver struct {
+	remotes.Resolver
+	cs   content.Store
+	refs map[string]ocispec.Descriptor
+}
+
+func (r *testResolver) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	desc, ok := r.refs[ref]
+	if !ok {
+		return "", ocispec.Descriptor{}, errors.Errorf("%s not found", ref)
+	}
+	return ref, desc, nil
+}
+
+func (r *testResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		return r.cs.Reader(ctx, desc.Digest)
+	}), nil
+}
+
+func blob(name string) digest.Digest {
+	return digest.FromString("blob-" + name)
+}
+
+func diffID(name string) digest.Digest {
+	return digest.FromString("diff-" + name)
+}
+
+// writeImage writes a linux/amd64 image with placeholder layers
+func writeImage(t *testing.T, cs content.Store, layers ...string) ocispec.Descriptor {
+	var (
+		diffIDs []digest.Digest
+		descs   []ocispec.Descriptor
+	)
+	for _, l := range layers {
+		diffIDs = append(diffIDs, diffID(l))
+		dt := []byte("blob-" + l)
+		assert.NoError(t, content.WriteBlob(context.TODO(), cs, "layer-"+l, bytes.NewReader(dt), int64(len(dt)), blob(l)))
+		descs = append(descs, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: blob(l), Size: int64(len(dt))})
+	}
+	config := writeTestJSON(t, cs, ocispec.MediaTypeImageConfig, map[string]interface{}{
+		"architecture": runtime.GOARCH,
+		"os":           "linux",
+		"rootfs":       ocispec.RootFS{Type: "layers", DiffIDs: diffIDs},
+	})
+	return writeTestJSON(t, cs, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    config,
+		Layers:    descs,
+	})
+}
+
+func writeTestJSON(t *testing.T, cs content.Store, mediaType string, v interface{}) ocispec.Descriptor {
+	dt, err := json.Marshal(v)
+	assert.NoError(t, err)
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(dt), Size: int64(len(dt))}
+	assert.NoError(t, content.WriteBlob(context.TODO(), cs, "test-"+desc.Digest.String(), bytes.NewReader(dt), desc.Size, desc.Digest))
+	return desc
+}
+
+func TestExportOverlay(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "imageexport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	sn, err := overlay.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	writeSnapshot(t, sn, "base-active", "", func(dir string) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "dir"), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "dir/a"), []byte("a"), 0644))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo"), []byte("foo"), 0644))
+	})
+	assert.NoError(t, sn.Commit(ctx, "base", "base-active"))
+	writeSnapshot(t, sn, "child-active", "base", func(dir string) {
+		assert.NoError(t, os.Remove(filepath.Join(dir, "foo")))
+		assert.NoError(t, os.RemoveAll(filepath.Join(dir, "dir")))
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "dir"), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "dir/b"), []byte("b"), 0644))
+	})
+	assert.NoError(t, sn.Commit(ctx, "child", "child-active"))
+
+	vc := &viewCounter{Snapshotter: sn}
+	e, err := New(Opt{Snapshotter: vc, ContentStore: cs})
+	assert.NoError(t, err)
+	i, err := e.Resolve(ctx, map[string]string{"compression": "uncompressed"})
+	assert.NoError(t, err)
+	resp, err := i.Export(ctx, &testRef{id: "child"}, nil)
+	assert.NoError(t, err)
+
+	// the parent isn't viewed to diff the child
+	assert.Equal(t, []string{"base", "child"}, vc.sorted())
+
+	var mfst ocispec.Manifest
+	readJSON(t, cs, digest.Digest(resp["digest"]), &mfst)
+	assert.Equal(t, 2, len(mfst.Layers))
+	rc, err := cs.Reader(ctx, mfst.Layers[1].Digest)
+	assert.NoError(t, err)
+	defer rc.Close()
+	var names []string
+	tr := tar.NewReader(rc)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, h.Name)
+	}
+	// only the upperdir of the overlay snapshot marks directories opaque,
+	// comparing the trees deletes their old files one by one
+	assert.Contains(t, names, "dir/.wh..wh..opq")
+	assert.Contains(t, names, ".wh.foo")
+	assert.Contains(t, names, "dir/b")
+	assert.NotContains(t, names, "dir/.wh.a")
+
+	// the views are removed after exporting
+	var views []string
+	assert.NoError(t, sn.Walk(ctx, func(ctx context.Context, info cdsnapshot.Info) error {
+		if info.Kind != cdsnapshot.KindCommitted {
+			views = append(views, info.Name)
+		}
+		return nil
+	}))
+	assert.Equal(t, 0, len(views))
+}
+
+// viewCounter records the snapshots that are viewed
+type viewCounter struct {
+	cdsnapshot.Snapshotter
+	mu    sync.Mutex
+	views []string
+}
+
+func (s *viewCounter) View(ctx context.Context, key, parent string) ([]mount.Mount, error) {
+	s.mu.Lock()
+	s.views = append(s.views, parent)
+	s.mu.Unlock()
+	return s.Snapshotter.View(ctx, key, parent)
+}
+
+func (s *viewCounter) sorted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	views := append([]string{}, s.views...)
+	sort.Strings(views)
+	return views
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "imageexport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	sn, err := overlay.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	// the pulled layer is extracted the same way the applier does it
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	data := make([]byte, 1<<20)
+	copy(data[512<<10:], "pulled")
+	hdr := &tar.Header{Name: "pulled", Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg, Xattrs: map[string]string{"user.pulled": "1"}}
+	sparse.Mark(hdr)
+	assert.NoError(t, tw.WriteHeader(hdr))
+	_, err = tw.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	writeSnapshot(t, sn, "base-active", "", func(dir string) {
+		_, err := sparse.Apply(ctx, dir, buf)
+		assert.NoError(t, err)
+	})
+	assert.NoError(t, sn.Commit(ctx, "base", "base-active"))
+
+	// the exec adds a sparse file and changes the xattrs of the pulled one
+	writeSnapshot(t, sn, "child-active", "base", func(dir string) {
+		f, err := os.Create(filepath.Join(dir, "exec"))
+		assert.NoError(t, err)
+		assert.NoError(t, f.Truncate(1<<20))
+		_, err = f.WriteAt([]byte("exec"), 256<<10)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+		assert.NoError(t, unix.Setxattr(filepath.Join(dir, "exec"), "user.exec", []byte("1"), 0))
+		assert.NoError(t, unix.Setxattr(filepath.Join(dir, "pulled"), "user.pulled", []byte("2"), 0))
+	})
+	assert.NoError(t, sn.Commit(ctx, "child", "child-active"))
+
+	e, err := New(Opt{Snapshotter: sn, ContentStore: cs})
+	assert.NoError(t, err)
+	i, err := e.Resolve(ctx, nil)
+	assert.NoError(t, err)
+	resp, err := i.Export(ctx, &testRef{id: "child"}, nil)
+	assert.NoError(t, err)
+
+	var mfst ocispec.Manifest
+	readJSON(t, cs, digest.Digest(resp["digest"]), &mfst)
+	assert.Equal(t, 2, len(mfst.Layers))
+	root := filepath.Join(tmpdir, "import")
+	assert.NoError(t, os.MkdirAll(root, 0755))
+	for _, l := range mfst.Layers {
+		rc, err := cs.Reader(ctx, l.Digest)
+		assert.NoError(t, err)
+		ds, err := compression.DecompressStream(rc)
+		assert.NoError(t, err)
+		_, err = sparse.Apply(ctx, root, ds)
+		assert.NoError(t, err)
+		ds.Close()
+		rc.Close()
+	}
+
+	for name, expected := range map[string]struct {
+		off   int
+		dt    string
+		xattr string
+	}{
+		"pulled": {512 << 10, "pulled", "2"},
+		"exec":   {256 << 10, "exec", "1"},
+	} {
+		p := filepath.Join(root, name)
+		fi, err := os.Stat(p)
+		assert.NoError(t, err)
+		assert.True(t, sparse.IsSparse(fi), name)
+		dt, err := ioutil.ReadFile(p)
+		assert.NoError(t, err)
+		data := make([]byte, 1<<20)
+		copy(data[expected.off:], expected.dt)
+		assert.Equal(t, data, dt, name)
+		xattr := make([]byte, 16)
+		n, err := unix.Getxattr(p, "user."+name, xattr)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.xattr, string(xattr[:n]), name)
+	}
+}
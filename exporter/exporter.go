@@ -0,0 +1,21 @@
+package exporter
+
+import (
+	"context"
+
+	"github.com/tonistiigi/buildkit_poc/cache"
+)
+
+// Exporter writes the result of a solve somewhere outside of the cache.
+// Exporters are registered with the controller by name.
+type Exporter interface {
+	// Resolve validates the attributes of a solve request before solving
+	Resolve(ctx context.Context, attrs map[string]string) (ExporterInstance, error)
+}
+
+type ExporterInstance interface {
+	Name() string
+	// Export exports the result ref. Metadata is set by frontends. The
+	// returned map is sent back to the client.
+	Export(ctx context.Context, ref cache.ImmutableRef, meta map[string][]byte) (map[string]string, error)
+}
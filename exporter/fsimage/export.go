@@ -0,0 +1,114 @@
+package fsimage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/containerd/containerd/content"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/exporter"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/util/fsimage"
+)
+
+// ExporterName is the name the exporter is registered with
+const ExporterName = "fsimage"
+
+type Opt struct {
+	ContentStore content.Store
+}
+
+// New returns an exporter that writes the result as an ext4 filesystem
+// image to the content store. The response contains the digest and size of
+// the image, which clients read with ReadContent. Only ext4 images are
+// written, squashfs is not supported.
+func New(opt Opt) (exporter.Exporter, error) {
+	if opt.ContentStore == nil {
+		return nil, errors.New("fsimage exporter requires a content store")
+	}
+	return &imageExporter{opt: opt}, nil
+}
+
+type imageExporter struct {
+	opt Opt
+}
+
+func (e *imageExporter) Resolve(ctx context.Context, attrs map[string]string) (exporter.ExporterInstance, error) {
+	i := &imageExporterInstance{imageExporter: e}
+	for k, v := range attrs {
+		switch k {
+		case "size":
+			size, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || size <= 0 {
+				return nil, errors.Errorf("invalid size %s", v)
+			}
+			i.imageOpt.Size = size
+		case "inodes":
+			inodes, err := strconv.Atoi(v)
+			if err != nil || inodes <= 0 {
+				return nil, errors.Errorf("invalid inode count %s", v)
+			}
+			i.imageOpt.Inodes = inodes
+		case "epoch":
+			sec, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid epoch %s", v)
+			}
+			epoch := time.Unix(sec, 0).UTC()
+			i.imageOpt.Epoch = &epoch
+		case "label":
+			i.imageOpt.Label = v
+		default:
+			return nil, errors.Errorf("unknown fsimage exporter attribute %s", k)
+		}
+	}
+	return i, nil
+}
+
+type imageExporterInstance struct {
+	*imageExporter
+	imageOpt fsimage.Opt
+}
+
+func (i *imageExporterInstance) Name() string {
+	return ExporterName
+}
+
+func (i *imageExporterInstance) Export(ctx context.Context, ref cache.ImmutableRef, meta map[string][]byte) (map[string]string, error) {
+	mounts, err := ref.Mount()
+	if err != nil {
+		return nil, err
+	}
+	lm := snapshot.LocalMounter(mounts)
+	root, err := lm.Mount()
+	if err != nil {
+		return nil, err
+	}
+	defer lm.Unmount()
+
+	cw, err := i.opt.ContentStore.Writer(ctx, "fsimage-"+ref.ID(), 0, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open writer")
+	}
+	defer cw.Close()
+	if err := cw.Truncate(0); err != nil {
+		return nil, err
+	}
+	if err := fsimage.WriteExt4(ctx, cw, root, i.imageOpt); err != nil {
+		return nil, err
+	}
+	dgst := cw.Digest()
+	status, err := cw.Status()
+	if err != nil {
+		return nil, err
+	}
+	if err := cw.Commit(0, dgst); err != nil && !content.IsExists(err) {
+		return nil, errors.Wrap(err, "failed to commit image")
+	}
+	return map[string]string{
+		"digest": dgst.String(),
+		"size":   strconv.FormatInt(status.Offset, 10),
+	}, nil
+}
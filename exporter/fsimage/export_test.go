@@ -0,0 +1,65 @@
+package fsimage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/mount"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/cache"
+)
+
+func TestExport(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "fsimageexport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	root := filepath.Join(tmpdir, "root")
+	assert.NoError(t, os.MkdirAll(root, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "foo"), []byte("foo"), 0600))
+
+	e, err := New(Opt{ContentStore: cs})
+	assert.NoError(t, err)
+
+	_, err = e.Resolve(ctx, map[string]string{"unknown": "1"})
+	assert.Error(t, err)
+	_, err = e.Resolve(ctx, map[string]string{"size": "-1"})
+	assert.Error(t, err)
+
+	i, err := e.Resolve(ctx, map[string]string{"size": "4194304", "epoch": "0", "label": "test"})
+	assert.NoError(t, err)
+	resp, err := i.Export(ctx, &testRef{dir: root}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(4<<20), resp["size"])
+
+	info, err := cs.Info(ctx, digest.Digest(resp["digest"]))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4<<20), info.Size)
+
+	// the same result gives the same image
+	resp2, err := i.Export(ctx, &testRef{dir: root}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, resp, resp2)
+}
+
+type testRef struct {
+	cache.ImmutableRef
+	dir string
+}
+
+func (r *testRef) ID() string {
+	return "test"
+}
+
+func (r *testRef) Mount() ([]mount.Mount, error) {
+	return []mount.Mount{{Type: "bind", Source: r.dir, Options: []string{"rbind", "ro"}}}, nil
+}
@@ -0,0 +1,374 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tonistiigi/buildkit_poc/frontend/dockerfile/parser"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+)
+
+const (
+	// RuleUnpinnedBaseImage reports base images that are not pinned to a
+	// digest
+	RuleUnpinnedBaseImage = "unpinned-base-image"
+	// RuleAptGetCleanup reports apt-get install without removing the package
+	// lists in the same layer
+	RuleAptGetCleanup = "apt-get-cleanup"
+	// RuleAddInsteadOfCopy reports ADD of local files that COPY could do
+	RuleAddInsteadOfCopy = "add-instead-of-copy"
+	// RuleMissingUser reports a final stage that runs as root
+	RuleMissingUser = "missing-user"
+	// RuleUndefinedArg reports references to variables that are not defined
+	RuleUndefinedArg = "undefined-arg"
+)
+
+// Finding is a rule violation in a Dockerfile
+type Finding struct {
+	Rule     string
+	Severity warnings.Severity
+	Message  string
+	Range    parser.Range
+}
+
+// args that are defined without an ARG instruction
+var predefinedArgs = []string{
+	"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy",
+	"FTP_PROXY", "ftp_proxy", "NO_PROXY", "no_proxy", "ALL_PROXY", "all_proxy",
+	"BUILDPLATFORM", "BUILDOS", "BUILDARCH", "BUILDVARIANT",
+	"TARGETPLATFORM", "TARGETOS", "TARGETARCH", "TARGETVARIANT",
+}
+
+// instructions where variables are expanded by the builder
+var expandInstructions = map[string]struct{}{
+	"add": {}, "copy": {}, "env": {}, "expose": {}, "label": {}, "stopsignal": {},
+	"user": {}, "volume": {}, "workdir": {}, "arg": {},
+}
+
+var (
+	aptGetInstallRegexp = regexp.MustCompile(`\bapt-get\b[^;&|]*\binstall\b`)
+	aptListsRegexp      = regexp.MustCompile(`\brm\b[^;&|]*/var/lib/apt/lists`)
+	archiveRegexp       = regexp.MustCompile(`\.(tar|tar\.gz|tgz|tar\.bz2|tbz2?|tar\.xz|txz)$`)
+)
+
+// Lint parses a Dockerfile and checks it against all rules. The Dockerfile is
+// not built so only problems visible in the source are found. Findings are
+// sorted by position.
+func Lint(r io.Reader) ([]Finding, error) {
+	res, err := parser.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{
+		escape:     res.EscapeToken,
+		globalArgs: map[string]struct{}{},
+	}
+	for _, n := range res.Nodes {
+		l.node(n)
+	}
+	l.finish()
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i].Range.Start, l.findings[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings, nil
+}
+
+type stage struct {
+	name string
+	from *parser.Node
+	// vars are the ARG and ENV names in scope. If complete is false the base
+	// image may define more variables.
+	vars     map[string]struct{}
+	env      map[string]struct{}
+	complete bool
+	user     *parser.Node
+}
+
+type linter struct {
+	escape     rune
+	globalArgs map[string]struct{}
+	stages     []*stage
+	findings   []Finding
+}
+
+func (l *linter) report(rule string, s warnings.Severity, rng parser.Range, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{Rule: rule, Severity: s, Message: fmt.Sprintf(format, args...), Range: rng})
+}
+
+func (l *linter) current() *stage {
+	if len(l.stages) == 0 {
+		return nil
+	}
+	return l.stages[len(l.stages)-1]
+}
+
+func (l *linter) node(n *parser.Node) {
+	st := l.current()
+	if n.Instruction == "from" {
+		l.from(n)
+		return
+	}
+	if st == nil {
+		// only ARG is allowed before the first FROM
+		if n.Instruction == "arg" {
+			for _, name := range argNames(n) {
+				l.globalArgs[name] = struct{}{}
+			}
+		}
+		return
+	}
+
+	if _, ok := expandInstructions[n.Instruction]; ok {
+		l.checkRefs(n, st)
+	}
+
+	switch n.Instruction {
+	case "arg":
+		for _, name := range argNames(n) {
+			st.vars[name] = struct{}{}
+		}
+	case "env":
+		for _, name := range envNames(n) {
+			st.vars[name] = struct{}{}
+			st.env[name] = struct{}{}
+		}
+	case "user":
+		st.user = n
+	case "run":
+		l.checkAptGet(n)
+	case "add":
+		l.checkAdd(n)
+	}
+}
+
+func (l *linter) from(n *parser.Node) {
+	st := &stage{from: n, vars: map[string]struct{}{}, env: map[string]struct{}{}}
+	if len(n.Words) == 3 && strings.EqualFold(n.Words[1].Value, "as") {
+		st.name = strings.ToLower(n.Words[2].Value)
+	}
+	for _, name := range predefinedArgs {
+		st.vars[name] = struct{}{}
+	}
+	defer func() {
+		l.stages = append(l.stages, st)
+	}()
+	if len(n.Words) == 0 {
+		return
+	}
+
+	image := n.Words[0]
+	for _, r := range l.refs(n, n.Args[:len(image.Value)]) {
+		if _, ok := l.globalArgs[r.name]; ok {
+			continue
+		}
+		if isPredefined(r.name) {
+			continue
+		}
+		l.report(RuleUndefinedArg, warnings.SeverityWarning, r.rng, "%s is not defined by an ARG before the first FROM", r.name)
+	}
+	if strings.Contains(image.Value, "$") {
+		return
+	}
+
+	if strings.EqualFold(image.Value, "scratch") {
+		st.complete = true
+		return
+	}
+	for _, base := range l.stages {
+		if base.name != "" && base.name == strings.ToLower(image.Value) {
+			// ENV and USER are inherited from the stage, ARGs are not
+			for name := range base.env {
+				st.vars[name] = struct{}{}
+				st.env[name] = struct{}{}
+			}
+			st.user = base.user
+			st.complete = base.complete
+			return
+		}
+	}
+
+	if !strings.Contains(image.Value, "@") {
+		name := image.Value
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		if i := strings.LastIndex(name, ":"); i < 0 || name[i+1:] == "latest" {
+			l.report(RuleUnpinnedBaseImage, warnings.SeverityWarning, image.Range, "base image %s uses the latest tag", image.Value)
+		} else {
+			l.report(RuleUnpinnedBaseImage, warnings.SeverityInfo, image.Range, "base image %s is not pinned to a digest", image.Value)
+		}
+	}
+}
+
+func (l *linter) finish() {
+	st := l.current()
+	if st == nil {
+		return
+	}
+	if st.user == nil {
+		l.report(RuleMissingUser, warnings.SeverityWarning, st.from.Range, "final stage has no USER instruction and runs as root")
+		return
+	}
+	if len(st.user.Words) > 0 {
+		user := strings.SplitN(st.user.Words[0].Value, ":", 2)[0]
+		if user == "root" || user == "0" {
+			l.report(RuleMissingUser, warnings.SeverityWarning, st.user.Range, "final stage runs as root")
+		}
+	}
+}
+
+func (l *linter) checkRefs(n *parser.Node, st *stage) {
+	for _, r := range l.refs(n, n.Args) {
+		if _, ok := st.vars[r.name]; ok {
+			continue
+		}
+		if _, ok := l.globalArgs[r.name]; ok {
+			l.report(RuleUndefinedArg, warnings.SeverityWarning, r.rng, "global ARG %s needs to be redeclared in the stage to be used", r.name)
+			continue
+		}
+		// the base image may set the variable with ENV
+		if st.complete {
+			l.report(RuleUndefinedArg, warnings.SeverityWarning, r.rng, "%s is not defined", r.name)
+		}
+	}
+}
+
+func (l *linter) checkAptGet(n *parser.Node) {
+	cmd := n.Args
+	if n.JSON {
+		cmd = strings.Join(n.JSONArgs, " ")
+	}
+	if aptGetInstallRegexp.MatchString(cmd) && !aptListsRegexp.MatchString(cmd) {
+		l.report(RuleAptGetCleanup, warnings.SeverityWarning, n.Range, "apt-get install without removing /var/lib/apt/lists in the same RUN")
+	}
+}
+
+func (l *linter) checkAdd(n *parser.Node) {
+	var srcs []string
+	if n.JSON {
+		srcs = n.JSONArgs
+	} else {
+		for _, w := range n.Words {
+			srcs = append(srcs, w.Value)
+		}
+	}
+	if len(srcs) < 2 {
+		return
+	}
+	for _, src := range srcs[:len(srcs)-1] {
+		if strings.Contains(src, "://") || strings.HasPrefix(src, "git@") || strings.Contains(src, "$") || archiveRegexp.MatchString(src) {
+			return
+		}
+	}
+	l.report(RuleAddInsteadOfCopy, warnings.SeverityInfo, n.Range, "use COPY instead of ADD for local files")
+}
+
+type ref struct {
+	name string
+	rng  parser.Range
+}
+
+// refs returns the variables referenced with $name or ${name} in s, which is
+// a prefix of the arguments of n. References with a default value are
+// skipped.
+func (l *linter) refs(n *parser.Node, s string) []ref {
+	var refs []ref
+	var quote bool
+	for i := 0; i < len(s); i++ {
+		switch c := rune(s[i]); {
+		case c == l.escape:
+			i++
+		case c == '\'':
+			quote = !quote
+		case c == '$' && !quote:
+			name, length, ok := parseRef(s[i+1:])
+			if !ok {
+				continue
+			}
+			refs = append(refs, ref{name: name, rng: n.ArgsRange(i, i+1+length)})
+			i += length
+		}
+	}
+	return refs
+}
+
+// parseRef parses the variable name after $ and returns the length of the
+// reference
+func parseRef(s string) (string, int, bool) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", 0, false
+		}
+		name := s[1:end]
+		if !isName(name) {
+			// ${name:-default} and ${name:+alt} handle unset variables
+			return "", 0, false
+		}
+		return name, end + 1, true
+	}
+	end := 0
+	for end < len(s) && isNameChar(s[end], end == 0) {
+		end++
+	}
+	if end == 0 {
+		return "", 0, false
+	}
+	return s[:end], end, true
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+func isPredefined(name string) bool {
+	for _, n := range predefinedArgs {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func argNames(n *parser.Node) []string {
+	var names []string
+	for _, w := range n.Words {
+		names = append(names, strings.SplitN(w.Value, "=", 2)[0])
+	}
+	return names
+}
+
+func envNames(n *parser.Node) []string {
+	if len(n.Words) == 0 {
+		return nil
+	}
+	// ENV name value
+	if !strings.Contains(n.Words[0].Value, "=") {
+		return []string{n.Words[0].Value}
+	}
+	var names []string
+	for _, w := range n.Words {
+		names = append(names, strings.SplitN(w.Value, "=", 2)[0])
+	}
+	return names
+}
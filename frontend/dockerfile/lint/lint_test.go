@@ -0,0 +1,84 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/frontend/dockerfile/parser"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+)
+
+func TestLint(t *testing.T) {
+	dockerfile := `ARG VERSION=3.6
+FROM alpine:3.6 AS base
+ENV DIR=/app
+ARG NAME
+WORKDIR ${DIR}/$NAME/$UNKNOWN
+
+FROM base AS build
+ADD a.txt b.txt /dest/
+ADD src.tar.gz https://example.com/foo /dest/
+RUN apt-get update && apt-get install -y \
+      curl
+RUN apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*
+
+FROM scratch
+COPY --from=build $DIR/$VERSION /
+FROM busybox@sha256:0000000000000000000000000000000000000000000000000000000000000000
+FROM $MISSING
+FROM example.com:5000/foo
+USER root
+`
+	findings, err := Lint(strings.NewReader(dockerfile))
+	assert.NoError(t, err)
+
+	var rules []string
+	for _, f := range findings {
+		rules = append(rules, f.Rule)
+	}
+	assert.Equal(t, []string{
+		RuleUnpinnedBaseImage, // alpine:3.6
+		RuleAddInsteadOfCopy,  // ADD a.txt b.txt
+		RuleAptGetCleanup,     // apt-get install without rm
+		RuleUndefinedArg,      // $DIR in scratch
+		RuleUndefinedArg,      // $VERSION not redeclared
+		RuleUndefinedArg,      // $MISSING
+		RuleUnpinnedBaseImage, // example.com:5000/foo
+		RuleMissingUser,       // USER root
+	}, rules)
+
+	assert.Equal(t, Finding{
+		Rule:     RuleUnpinnedBaseImage,
+		Severity: warnings.SeverityInfo,
+		Message:  "base image alpine:3.6 is not pinned to a digest",
+		Range:    rng(2, 6, 2, 16),
+	}, findings[0])
+	assert.Equal(t, rng(10, 1, 11, 11), findings[2].Range)
+	assert.Equal(t, "DIR is not defined", findings[3].Message)
+	assert.Equal(t, rng(15, 19, 15, 23), findings[3].Range)
+	assert.Equal(t, "global ARG VERSION needs to be redeclared in the stage to be used", findings[4].Message)
+	assert.Equal(t, rng(17, 6, 17, 14), findings[5].Range)
+	assert.Equal(t, "base image example.com:5000/foo uses the latest tag", findings[6].Message)
+	assert.Equal(t, warnings.SeverityWarning, findings[6].Severity)
+	assert.Equal(t, "final stage runs as root", findings[7].Message)
+}
+
+func TestLintMissingUser(t *testing.T) {
+	findings, err := Lint(strings.NewReader("FROM busybox:1.27 AS base\nUSER nobody\nFROM base\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(findings))
+
+	findings, err = Lint(strings.NewReader("FROM busybox:1.27\nRUN true\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(findings))
+	assert.Equal(t, RuleMissingUser, findings[0].Rule)
+	assert.Equal(t, rng(1, 1, 1, 18), findings[0].Range)
+}
+
+func rng(startLine, startColumn, endLine, endColumn int) parser.Range {
+	return parser.Range{
+		Start: parser.Position{Line: startLine, Column: startColumn},
+		End:   parser.Position{Line: endLine, Column: endColumn},
+	}
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const defaultEscapeToken = '\\'
+
+var directiveRegexp = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+
+// instructions that take --flags before their arguments
+var flagInstructions = map[string]struct{}{
+	"add": {}, "copy": {}, "from": {}, "run": {}, "healthcheck": {},
+}
+
+// instructions with an exec form
+var jsonInstructions = map[string]struct{}{
+	"add": {}, "copy": {}, "run": {}, "cmd": {}, "entrypoint": {}, "volume": {}, "shell": {},
+}
+
+// Position is a location in a Dockerfile. Lines and columns start at 1.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Range is a span in a Dockerfile. End points after the last character.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Word is a whitespace separated argument of an instruction. Quotes are
+// kept in the value.
+type Word struct {
+	Value string
+	Range Range
+}
+
+// Node is an instruction of a Dockerfile
+type Node struct {
+	// Instruction is the lowercase instruction name
+	Instruction string
+	// Flags are the --name=value flags before the arguments
+	Flags []Word
+	// Words are the arguments after the flags
+	Words []Word
+	// JSON is set if the arguments are in the exec form. JSONArgs holds the
+	// decoded array.
+	JSON     bool
+	JSONArgs []string
+	// Original is the instruction with continuation lines joined
+	Original string
+	Range    Range
+
+	// Args is the text after the flags, offset is its start in Original
+	Args   string
+	offset int
+	segs   []segment
+}
+
+// segment maps a part of Original to the line it came from
+type segment struct {
+	offset int
+	pos    Position
+}
+
+// Result is a parsed Dockerfile
+type Result struct {
+	Nodes       []*Node
+	EscapeToken rune
+}
+
+// Parse parses a Dockerfile. Only the structure is parsed: instructions are
+// not validated and variables are not expanded.
+func Parse(r io.Reader) (*Result, error) {
+	res := &Result{EscapeToken: defaultEscapeToken}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var cur *Node
+	directives := true
+	for lineNo := 1; s.Scan(); lineNo++ {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		trimmed := strings.TrimSpace(line)
+
+		if cur == nil {
+			if directives {
+				if m := directiveRegexp.FindStringSubmatch(trimmed); m != nil {
+					if strings.ToLower(m[1]) == "escape" {
+						if m[2] != "\\" && m[2] != "`" {
+							return nil, errors.Errorf("invalid escape token %s on line %d", m[2], lineNo)
+						}
+						res.EscapeToken = rune(m[2][0])
+					}
+					continue
+				}
+				directives = false
+			}
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			cur = &Node{}
+		} else if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			// empty and comment lines inside of continuations are skipped
+			continue
+		}
+
+		start := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		text, continued := trimContinuation(line[start:], res.EscapeToken)
+		cur.segs = append(cur.segs, segment{offset: len(cur.Original), pos: Position{Line: lineNo, Column: start + 1}})
+		cur.Original += text
+		if !continued {
+			res.Nodes = append(res.Nodes, cur.finish(res.EscapeToken))
+			cur = nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read Dockerfile")
+	}
+	if cur != nil {
+		res.Nodes = append(res.Nodes, cur.finish(res.EscapeToken))
+	}
+	return res, nil
+}
+
+// trimContinuation removes the escape token that continues line on the next
+// line
+func trimContinuation(line string, escape rune) (string, bool) {
+	trimmed := strings.TrimRightFunc(line, unicode.IsSpace)
+	if strings.HasSuffix(trimmed, string(escape)) {
+		return strings.TrimSuffix(trimmed, string(escape)), true
+	}
+	return line, false
+}
+
+func (n *Node) finish(escape rune) *Node {
+	n.Range = Range{Start: n.Position(0), End: n.Position(len(strings.TrimRightFunc(n.Original, unicode.IsSpace)))}
+
+	words := splitWords(n.Original, escape)
+	if len(words) == 0 {
+		return n
+	}
+	n.Instruction = strings.ToLower(words[0].value)
+	n.offset = len(n.Original)
+	words = words[1:]
+
+	if _, ok := flagInstructions[n.Instruction]; ok {
+		for len(words) > 0 && strings.HasPrefix(words[0].value, "--") {
+			n.Flags = append(n.Flags, n.word(words[0]))
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 {
+		n.offset = words[0].offset
+	}
+	n.Args = strings.TrimRightFunc(n.Original[n.offset:], unicode.IsSpace)
+	for _, w := range words {
+		n.Words = append(n.Words, n.word(w))
+	}
+
+	if _, ok := jsonInstructions[n.Instruction]; ok && strings.HasPrefix(n.Args, "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(n.Args), &args); err == nil {
+			n.JSON = true
+			n.JSONArgs = args
+		}
+	}
+	return n
+}
+
+// Position returns the position of an offset in Original
+func (n *Node) Position(offset int) Position {
+	var seg segment
+	for _, s := range n.segs {
+		if s.offset > offset {
+			break
+		}
+		seg = s
+	}
+	return Position{Line: seg.pos.Line, Column: seg.pos.Column + offset - seg.offset}
+}
+
+// ArgsRange returns the range of a span of Args
+func (n *Node) ArgsRange(start, end int) Range {
+	return Range{Start: n.Position(n.offset + start), End: n.Position(n.offset + end)}
+}
+
+func (n *Node) word(w rawWord) Word {
+	return Word{Value: w.value, Range: Range{Start: n.Position(w.offset), End: n.Position(w.offset + len(w.value))}}
+}
+
+type rawWord struct {
+	value  string
+	offset int
+}
+
+// splitWords splits s on whitespace outside of quotes
+func splitWords(s string, escape rune) []rawWord {
+	var (
+		words []rawWord
+		start = -1
+		quote rune
+		esc   bool
+	)
+	for i, c := range s {
+		switch {
+		case esc:
+			esc = false
+		case c == escape:
+			esc = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case unicode.IsSpace(c):
+			if start >= 0 {
+				words = append(words, rawWord{value: s[start:i], offset: start})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, rawWord{value: s[start:], offset: start})
+	}
+	return words
+}
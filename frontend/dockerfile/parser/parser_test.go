@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	dockerfile := "\ufeff# syntax = foo\n" +
+		"# escape=`\n" +
+		"\n" +
+		"# comment\n" +
+		"FROM --platform=linux/amd64 busybox AS base\n" +
+		"RUN echo foo && `\n" +
+		"  # comment inside of a continuation\n" +
+		"\n" +
+		"    echo \"bar baz\"\n" +
+		"COPY [\"a b\", \"/dest\"]\n" +
+		"  env FOO=bar\r\n"
+
+	res, err := Parse(strings.NewReader(dockerfile))
+	assert.NoError(t, err)
+	assert.Equal(t, '`', res.EscapeToken)
+	assert.Equal(t, 4, len(res.Nodes))
+
+	n := res.Nodes[0]
+	assert.Equal(t, "from", n.Instruction)
+	assert.Equal(t, []Word{{Value: "--platform=linux/amd64", Range: Range{Start: Position{5, 6}, End: Position{5, 28}}}}, n.Flags)
+	assert.Equal(t, "busybox AS base", n.Args)
+	assert.Equal(t, 3, len(n.Words))
+	assert.Equal(t, Range{Start: Position{5, 29}, End: Position{5, 36}}, n.Words[0].Range)
+	assert.Equal(t, Range{Start: Position{5, 1}, End: Position{5, 44}}, n.Range)
+
+	n = res.Nodes[1]
+	assert.Equal(t, "run", n.Instruction)
+	assert.Equal(t, "echo foo && echo \"bar baz\"", n.Args)
+	assert.False(t, n.JSON)
+	assert.Equal(t, "\"bar baz\"", n.Words[4].Value)
+	assert.Equal(t, Range{Start: Position{9, 10}, End: Position{9, 19}}, n.Words[4].Range)
+	assert.Equal(t, Range{Start: Position{6, 1}, End: Position{9, 19}}, n.Range)
+	assert.Equal(t, Range{Start: Position{6, 5}, End: Position{9, 10}}, n.ArgsRange(0, 17))
+
+	n = res.Nodes[2]
+	assert.Equal(t, "copy", n.Instruction)
+	assert.True(t, n.JSON)
+	assert.Equal(t, []string{"a b", "/dest"}, n.JSONArgs)
+
+	n = res.Nodes[3]
+	assert.Equal(t, "env", n.Instruction)
+	assert.Equal(t, "FOO=bar", n.Args)
+	assert.Equal(t, Range{Start: Position{11, 3}, End: Position{11, 14}}, n.Range)
+}
+
+func TestParseDirectives(t *testing.T) {
+	// directives are only parsed before the first instruction
+	res, err := Parse(strings.NewReader("FROM busybox\n# escape=`\nRUN foo \\\n  bar\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, '\\', res.EscapeToken)
+	assert.Equal(t, 2, len(res.Nodes))
+	assert.Equal(t, "foo bar", res.Nodes[1].Args)
+
+	_, err = Parse(strings.NewReader("# escape=x\nFROM busybox\n"))
+	assert.Error(t, err)
+
+	// a continuation on the last line ends the instruction
+	res, err = Parse(strings.NewReader("FROM busybox\nRUN foo \\"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(res.Nodes))
+	assert.Equal(t, "foo", res.Nodes[1].Args)
+}
@@ -0,0 +1,30 @@
+package frontend
+
+import (
+	"context"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/tonistiigi/buildkit_poc/cache"
+)
+
+// Frontend builds a solve request from its options instead of the client
+// sending a definition. Frontends are registered with the controller by
+// name.
+type Frontend interface {
+	// Solve returns the result of the build and metadata for the exporter,
+	// like an image config. The caller releases the result.
+	Solve(ctx context.Context, llb FrontendLLBBridge, opt map[string]string) (cache.ImmutableRef, map[string][]byte, error)
+}
+
+// FrontendLLBBridge gives frontends access to the solver
+type FrontendLLBBridge interface {
+	// Solve solves a marshaled definition and returns its result. The
+	// caller releases it.
+	Solve(ctx context.Context, def [][]byte) (cache.ImmutableRef, error)
+	// ResolveImageConfig returns the digest of the manifest and the config
+	// of an image. Layers are not pulled.
+	ResolveImageConfig(ctx context.Context, ref string) (digest.Digest, []byte, error)
+	// ReadFile reads a file from a result. Symlinks are resolved inside of
+	// the result.
+	ReadFile(ctx context.Context, ref cache.ImmutableRef, path string) ([]byte, error)
+}
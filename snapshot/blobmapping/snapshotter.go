@@ -5,6 +5,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/boltdb/bolt"
 	"github.com/containerd/containerd/content"
@@ -18,6 +19,7 @@ const dbFile = "blobmap.db"
 var (
 	bucketBySnapshot = []byte("by_snapshot")
 	bucketByBlob     = []byte("by_blob")
+	bucketConverted  = []byte("converted")
 )
 
 type Opt struct {
@@ -59,6 +61,11 @@ func NewSnapshotter(opt Opt) (*Snapshotter, error) {
 	return s, nil
 }
 
+// Close closes the mapping database. The wrapped snapshotter is not closed.
+func (s *Snapshotter) Close() error {
+	return s.db.Close()
+}
+
 func (s *Snapshotter) init() error {
 	// this should do a walk from the DB and remove any records that are not
 	// in snapshotter any more
@@ -166,6 +173,84 @@ func (s *Snapshotter) SetBlob(ctx context.Context, key string, blob digest.Diges
 	})
 }
 
+// GetConversion returns the blob that blob was converted to with the given
+// layer media type. Conversions whose target is missing from the content
+// store are ignored.
+func (s *Snapshotter) GetConversion(ctx context.Context, blob digest.Digest, mediaType string) (digest.Digest, error) {
+	var converted digest.Digest
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketConverted)
+		if b == nil {
+			return nil
+		}
+		v := b.Get(blobKey(blob, mediaType))
+		if v != nil {
+			converted = digest.Digest(v)
+		}
+		return nil
+	})
+	if err != nil || converted == "" {
+		return "", err
+	}
+	if _, err := s.opt.Content.Info(ctx, converted); err != nil {
+		if content.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return converted, nil
+}
+
+// SetConversion records that blob was converted to converted with the given
+// layer media type
+func (s *Snapshotter) SetConversion(ctx context.Context, blob digest.Digest, mediaType string, converted digest.Digest) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucketConverted)
+		if err != nil {
+			return err
+		}
+		return b.Put(blobKey(blob, mediaType), []byte(converted))
+	})
+}
+
+// Prune removes the blob mappings of snapshots that don't exist and returns
+// the names of the snapshots. The blobs are kept in the content store.
+func (s *Snapshotter) Prune(ctx context.Context) ([]string, error) {
+	var pruned []string
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketBySnapshot)
+		if b == nil {
+			return nil
+		}
+		stale := map[string]digest.Digest{}
+		if err := b.ForEach(func(k, v []byte) error {
+			if _, err := s.Snapshotter.Stat(ctx, string(k)); err != nil {
+				if !snapshot.IsNotExist(err) {
+					return err
+				}
+				stale[string(k)] = digest.Digest(v)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for key, blob := range stale {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+			if bb := tx.Bucket(bucketByBlob); bb != nil {
+				if err := bb.Delete(blobKey(blob, key)); err != nil {
+					return err
+				}
+			}
+			pruned = append(pruned, key)
+		}
+		return nil
+	})
+	sort.Strings(pruned)
+	return pruned, err
+}
+
 func blobKey(blob digest.Digest, snapshot string) []byte {
 	return []byte(string(blob) + "-" + snapshot)
 }
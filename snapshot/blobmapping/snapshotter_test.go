@@ -0,0 +1,58 @@
+package blobmapping
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/snapshot/naive"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "blobmapping")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+	sn, err := naive.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+	s, err := NewSnapshotter(Opt{Content: cs, Snapshotter: sn, Root: tmpdir})
+	assert.NoError(t, err)
+	defer s.Close()
+
+	dt := []byte("layer")
+	blob := digest.FromBytes(dt)
+	assert.NoError(t, content.WriteBlob(ctx, cs, "layer", bytes.NewReader(dt), int64(len(dt)), blob))
+
+	for _, name := range []string{"foo", "bar"} {
+		_, err := sn.Prepare(ctx, name+"-active", "")
+		assert.NoError(t, err)
+		assert.NoError(t, sn.Commit(ctx, name, name+"-active"))
+		assert.NoError(t, s.SetBlob(ctx, name, blob))
+	}
+
+	// the mapping of a snapshot that was removed behind its back is dropped
+	assert.NoError(t, sn.Remove(ctx, "bar"))
+	pruned, err := s.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bar"}, pruned)
+
+	b, err := s.GetBlob(ctx, "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, digest.Digest(""), b)
+	b, err = s.GetBlob(ctx, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, blob, b)
+
+	pruned, err = s.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(pruned))
+}
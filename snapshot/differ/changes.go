@@ -0,0 +1,204 @@
+package differ
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/containerd/containerd/fs"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+type walkPath struct {
+	path     string
+	fullPath string
+	fi       os.FileInfo
+}
+
+// changes compares lower and upper with a double walk like fs.Changes. Files
+// are only equal if all their extended attributes are, so files where only
+// attributes changed are reported as modified.
+func changes(ctx context.Context, lower, upper string, changeFn fs.ChangeFunc) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	lch, uch := make(chan *walkPath), make(chan *walkPath)
+	eg.Go(func() error {
+		defer close(lch)
+		return walkPaths(ctx, lower, lch)
+	})
+	eg.Go(func() error {
+		defer close(uch)
+		return walkPaths(ctx, upper, uch)
+	})
+	eg.Go(func() error {
+		var l, u *walkPath
+		var rmdir string // deleted directory whose children are skipped
+		for lch != nil || uch != nil {
+			if l == nil && lch != nil {
+				var ok bool
+				if l, ok = <-lch; !ok {
+					lch = nil
+				}
+			}
+			if u == nil && uch != nil {
+				var ok bool
+				if u, ok = <-uch; !ok {
+					uch = nil
+				}
+			}
+			if l == nil && u == nil {
+				continue
+			}
+
+			var k fs.ChangeKind
+			var p string
+			var fi os.FileInfo
+			switch c := comparePaths(l, u); {
+			case c < 0:
+				k, p = fs.ChangeKindDelete, l.path
+				isDir := l.fi.IsDir()
+				l = nil
+				if rmdir != "" && strings.HasPrefix(p, rmdir) {
+					continue
+				}
+				rmdir = ""
+				if isDir {
+					rmdir = p + string(filepath.Separator)
+				}
+			case c > 0:
+				k, p, fi, u = fs.ChangeKindAdd, u.path, u.fi, nil
+				rmdir = ""
+			default:
+				same, err := sameFile(l, u)
+				if err != nil {
+					return err
+				}
+				rmdir = ""
+				if l.fi.IsDir() && !u.fi.IsDir() {
+					rmdir = l.path + string(filepath.Separator)
+				}
+				k, p, fi = fs.ChangeKindModify, u.path, u.fi
+				l, u = nil, nil
+				if same {
+					if _, linked := fs.GetLinkInfo(fi); !linked || fi.IsDir() {
+						continue
+					}
+					k = fs.ChangeKindUnmodified
+				}
+			}
+			if err := changeFn(k, p, fi, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return eg.Wait()
+}
+
+// comparePaths orders paths like filepath.Walk visits them, a directory's
+// children before its siblings with names sharing its prefix. A nil path is
+// after all others.
+func comparePaths(l, u *walkPath) int {
+	if l == nil {
+		return 1
+	}
+	if u == nil {
+		return -1
+	}
+	return strings.Compare(strings.Replace(l.path, "/", "\x00", -1), strings.Replace(u.path, "/", "\x00", -1))
+}
+
+func walkPaths(ctx context.Context, root string, ch chan<- *walkPath) error {
+	return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		p, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		p = filepath.Join(string(filepath.Separator), p)
+		if p == string(filepath.Separator) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- &walkPath{path: p, fullPath: path, fi: fi}:
+		}
+		return nil
+	})
+}
+
+func sameFile(l, u *walkPath) (bool, error) {
+	if os.SameFile(l.fi, u.fi) {
+		return true, nil
+	}
+	if !sameStat(l.fi, u.fi) {
+		return false, nil
+	}
+	lx, _, err := readXattrs(l.fullPath)
+	if err != nil {
+		return false, err
+	}
+	ux, _, err := readXattrs(u.fullPath)
+	if err != nil {
+		return false, err
+	}
+	if !reflect.DeepEqual(lx, ux) {
+		return false, nil
+	}
+	if l.fi.IsDir() {
+		return true, nil
+	}
+	if l.fi.Size() != u.fi.Size() {
+		return false, nil
+	}
+	lt, ut := l.fi.ModTime(), u.fi.ModTime()
+	if lt.Unix() != ut.Unix() {
+		return false, nil
+	}
+	// truncated timestamps can't tell the files apart, compare the content
+	if lt.Nanosecond() == 0 || ut.Nanosecond() == 0 {
+		if l.fi.Mode().IsRegular() && l.fi.Size() > 0 {
+			return sameContent(l.fullPath, u.fullPath)
+		}
+		return true, nil
+	}
+	return lt.Nanosecond() == ut.Nanosecond(), nil
+}
+
+func sameContent(p1, p2 string) (bool, error) {
+	f1, err := os.Open(p1)
+	if err != nil {
+		return false, err
+	}
+	defer f1.Close()
+	f2, err := os.Open(p2)
+	if err != nil {
+		return false, err
+	}
+	defer f2.Close()
+
+	b1, b2 := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		n1, err1 := io.ReadFull(f1, b1)
+		n2, err2 := io.ReadFull(f2, b2)
+		if !bytes.Equal(b1[:n1], b2[:n2]) {
+			return false, nil
+		}
+		if err1 == io.EOF || err1 == io.ErrUnexpectedEOF {
+			return err2 == io.EOF || err2 == io.ErrUnexpectedEOF, nil
+		}
+		if err1 != nil {
+			return false, errors.Wrapf(err1, "failed to read %s", p1)
+		}
+		if err2 != nil {
+			return false, errors.Wrapf(err2, "failed to read %s", p2)
+		}
+	}
+}
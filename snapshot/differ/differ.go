@@ -0,0 +1,190 @@
+package differ
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"runtime"
+	"strconv"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/rootfs"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/util/estargz"
+	"github.com/tonistiigi/buildkit_poc/util/pgzip"
+)
+
+// UncompressedAnnotation is set on compressed diffs to the digest of the
+// uncompressed tar stream (the diffID)
+const UncompressedAnnotation = "buildkit.uncompressed"
+
+type Opt struct {
+	ContentStore content.Store
+	// Stargz writes compressed diffs in the seekable eStargz format so
+	// that they can be pulled lazily
+	Stargz bool
+	// Level is the gzip compression level, gzip.DefaultCompression if zero
+	Level int
+	// Concurrency is the number of blocks of a diff that are compressed in
+	// parallel, one per CPU if zero
+	Concurrency int
+}
+
+// Differ computes diffs between snapshot mounts and stores them in the
+// content store. Diffs of overlay snapshots are read directly from the
+// upperdir, everything else falls back to comparing the mounted trees.
+type Differ struct {
+	opt Opt
+}
+
+var _ rootfs.MountDiffer = &Differ{}
+
+func New(opt Opt) *Differ {
+	return &Differ{opt: opt}
+}
+
+func (d *Differ) DiffMounts(ctx context.Context, lower, upper []mount.Mount, media, ref string) (ocispec.Descriptor, error) {
+	return d.diff(ctx, media, ref, func(w io.Writer) error {
+		return WriteDiff(ctx, w, lower, upper)
+	})
+}
+
+// DiffUpperdir stores the changes in the upperdir of an overlay snapshot
+// as a layer. SnapshotUpperdir returns the upperdir of a committed snapshot.
+func (d *Differ) DiffUpperdir(ctx context.Context, upperdir, media, ref string) (ocispec.Descriptor, error) {
+	return d.diff(ctx, media, ref, func(w io.Writer) error {
+		return writeUpperdirDiff(ctx, w, upperdir)
+	})
+}
+
+// diff stores the uncompressed tar stream written by writeDiff with the
+// layer media type
+func (d *Differ) diff(ctx context.Context, media, ref string, writeDiff func(io.Writer) error) (ocispec.Descriptor, error) {
+	var compressed bool
+	switch media {
+	case ocispec.MediaTypeImageLayer:
+	case ocispec.MediaTypeImageLayerGzip:
+		compressed = true
+	default:
+		return ocispec.Descriptor{}, errors.Errorf("unsupported diff media type: %v", media)
+	}
+
+	cw, err := d.opt.ContentStore.Writer(ctx, ref, 0, "")
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to open writer")
+	}
+	defer cw.Close()
+
+	var (
+		w        io.Writer = cw
+		zw       io.WriteCloser
+		sw       *estargz.Writer
+		digester = digest.Canonical.Digester()
+	)
+	if compressed {
+		level := d.opt.Level
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		if d.opt.Stargz {
+			sw = estargz.NewWriterLevel(cw, level)
+			zw = newStargzConverter(sw)
+			w = zw
+		} else {
+			concurrency := d.opt.Concurrency
+			if concurrency == 0 {
+				concurrency = runtime.NumCPU()
+			}
+			pw, err := pgzip.NewWriterLevel(cw, level, concurrency)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			zw = pw
+			w = io.MultiWriter(zw, digester.Hash())
+		}
+	}
+
+	if err := writeDiff(w); err != nil {
+		if zw != nil {
+			zw.Close()
+		}
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to write diff")
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return ocispec.Descriptor{}, errors.Wrap(err, "failed to compress diff")
+		}
+	}
+
+	dgst := cw.Digest()
+	if err := cw.Commit(0, dgst); err != nil {
+		if !content.IsExists(err) {
+			return ocispec.Descriptor{}, errors.Wrap(err, "failed to commit")
+		}
+	}
+
+	info, err := d.opt.ContentStore.Info(ctx, dgst)
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to get info from content store")
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: media,
+		Digest:    info.Digest,
+		Size:      info.Size,
+	}
+	if sw != nil {
+		desc.Annotations = map[string]string{
+			UncompressedAnnotation:             sw.DiffID().String(),
+			estargz.TOCDigestAnnotation:        sw.TOCDigest().String(),
+			estargz.UncompressedSizeAnnotation: strconv.FormatInt(sw.UncompressedSize(), 10),
+		}
+	} else if compressed {
+		desc.Annotations = map[string]string{
+			UncompressedAnnotation: digester.Digest().String(),
+		}
+	}
+	return desc, nil
+}
+
+// WriteDiff writes the changes between the lower and upper mounts as an
+// uncompressed tar stream
+func WriteDiff(ctx context.Context, w io.Writer, lower, upper []mount.Mount) error {
+	if upperdir, ok := overlayUpperdir(lower, upper); ok {
+		return writeUpperdirDiff(ctx, w, upperdir)
+	}
+	return writeDiff(ctx, w, lower, upper)
+}
+
+// stargzConverter converts the tar stream written to it with a stargz writer
+type stargzConverter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newStargzConverter(sw *estargz.Writer) *stargzConverter {
+	pr, pw := io.Pipe()
+	c := &stargzConverter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := sw.AppendTar(pr)
+		if err == nil {
+			err = sw.Close()
+		}
+		pr.CloseWithError(err)
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *stargzConverter) Write(p []byte) (int, error) {
+	return c.pw.Write(p)
+}
+
+func (c *stargzConverter) Close() error {
+	c.pw.Close()
+	return <-c.done
+}
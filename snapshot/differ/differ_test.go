@@ -0,0 +1,191 @@
+// +build linux
+
+package differ
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/archive"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/fs"
+	"github.com/containerd/containerd/mount"
+	cdsnapshot "github.com/containerd/containerd/snapshot"
+	"github.com/containerd/containerd/snapshot/overlay"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/util/estargz"
+)
+
+func TestOverlayDiff(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	ctx := context.TODO()
+
+	tmpdir, err := ioutil.TempDir("", "differtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	sn, err := overlay.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+
+	writeSnapshot(t, sn, "base-active", "", func(dir string) {
+		write(t, dir, "foo", "foo")
+		write(t, dir, "bar", "bar")
+		write(t, dir, "dir/a", "a")
+		write(t, dir, "dir/b", "b")
+	})
+	assert.NoError(t, sn.Commit(ctx, "base", "base-active"))
+
+	writeSnapshot(t, sn, "child-active", "base", func(dir string) {
+		write(t, dir, "foo", "foo2")
+		assert.NoError(t, os.Remove(filepath.Join(dir, "bar")))
+		assert.NoError(t, os.RemoveAll(filepath.Join(dir, "dir")))
+		write(t, dir, "dir/c", "c")
+		write(t, dir, "new", "new")
+		assert.NoError(t, os.Link(filepath.Join(dir, "new"), filepath.Join(dir, "new2")))
+	})
+	assert.NoError(t, sn.Commit(ctx, "child", "child-active"))
+
+	lower, err := sn.View(ctx, "base-view", "base")
+	assert.NoError(t, err)
+	upper, err := sn.View(ctx, "child-view", "child")
+	assert.NoError(t, err)
+
+	_, ok := overlayUpperdir(lower, upper)
+	assert.True(t, ok)
+	_, ok = overlayUpperdir(upper, lower)
+	assert.False(t, ok)
+	upperdir, ok := SnapshotUpperdir(upper)
+	assert.True(t, ok)
+	_, err = os.Stat(filepath.Join(upperdir, "new"))
+	assert.NoError(t, err)
+	_, ok = SnapshotUpperdir(lower)
+	assert.False(t, ok)
+
+	d := New(Opt{ContentStore: cs})
+	desc, err := d.DiffMounts(ctx, lower, upper, ocispec.MediaTypeImageLayerGzip, "test-overlay")
+	assert.NoError(t, err)
+	assert.Equal(t, ocispec.MediaTypeImageLayerGzip, desc.MediaType)
+
+	names := tarNames(t, cs, desc)
+	assert.Contains(t, names, ".wh.bar")
+	assert.Contains(t, names, "dir/.wh..wh..opq")
+	assert.Contains(t, names, "new2")
+	assert.NotContains(t, names, "dir/a")
+
+	uncompressed := digest.Digest(desc.Annotations[UncompressedAnnotation])
+	assert.NoError(t, uncompressed.Validate())
+
+	r, err := cs.Reader(ctx, desc.Digest)
+	assert.NoError(t, err)
+	defer r.Close()
+	gr, err := gzip.NewReader(r)
+	assert.NoError(t, err)
+	checkApply(t, gr, lower, upper)
+
+	d = New(Opt{ContentStore: cs, Stargz: true})
+	desc, err = d.DiffMounts(ctx, lower, upper, ocispec.MediaTypeImageLayerGzip, "test-stargz")
+	assert.NoError(t, err)
+	names = tarNames(t, cs, desc)
+	assert.Contains(t, names, ".wh.bar")
+	assert.Contains(t, names, "new2")
+	assert.Equal(t, estargz.TOCName, names[len(names)-1])
+	assert.NoError(t, digest.Digest(desc.Annotations[estargz.TOCDigestAnnotation]).Validate())
+	assert.NotEqual(t, uncompressed, desc.Annotations[UncompressedAnnotation])
+
+	// the double walk has to produce an equivalent layer
+	f, err := os.Create(filepath.Join(tmpdir, "walk.tar"))
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.NoError(t, writeDiff(ctx, f, lower, upper))
+	_, err = f.Seek(0, 0)
+	assert.NoError(t, err)
+	checkApply(t, f, lower, upper)
+}
+
+func writeSnapshot(t *testing.T, sn cdsnapshot.Snapshotter, key, parent string, fn func(dir string)) {
+	mounts, err := sn.Prepare(context.TODO(), key, parent)
+	assert.NoError(t, err)
+	lm := snapshot.LocalMounter(mounts)
+	dir, err := lm.Mount()
+	assert.NoError(t, err)
+	fn(dir)
+	assert.NoError(t, lm.Unmount())
+}
+
+func write(t *testing.T, dir, p, dt string) {
+	p = filepath.Join(dir, p)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	assert.NoError(t, ioutil.WriteFile(p, []byte(dt), 0644))
+	// tar headers don't keep subsecond precision
+	tm := time.Now().Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(p, tm, tm))
+}
+
+func tarNames(t *testing.T, cs content.Store, desc ocispec.Descriptor) []string {
+	r, err := cs.Reader(context.TODO(), desc.Digest)
+	assert.NoError(t, err)
+	defer r.Close()
+	gr, err := gzip.NewReader(r)
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	return names
+}
+
+// checkApply applies the diff on top of a copy of lower and verifies that the
+// result matches upper
+func checkApply(t *testing.T, diff io.Reader, lower, upper []mount.Mount) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "differapply")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	lm := snapshot.LocalMounter(lower)
+	lowerDir, err := lm.Mount()
+	assert.NoError(t, err)
+	defer lm.Unmount()
+	assert.NoError(t, fs.CopyDir(tmpdir, lowerDir))
+
+	_, err = archive.Apply(ctx, tmpdir, diff)
+	assert.NoError(t, err)
+
+	um := snapshot.LocalMounter(upper)
+	upperDir, err := um.Mount()
+	assert.NoError(t, err)
+	defer um.Unmount()
+
+	var changes []string
+	err = fs.Changes(ctx, upperDir, tmpdir, func(k fs.ChangeKind, p string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if k != fs.ChangeKindUnmodified {
+			changes = append(changes, k.String()+" "+p)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(changes), "unexpected changes %v", changes)
+}
@@ -0,0 +1,175 @@
+package differ
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/containerd/mount"
+	"github.com/pkg/errors"
+)
+
+const (
+	whiteoutOpaqueDir = whiteoutPrefix + whiteoutPrefix + ".opq"
+
+	overlayXattrPrefix = "trusted.overlay."
+	overlayOpaqueXattr = overlayXattrPrefix + "opaque"
+)
+
+// overlayUpperdir returns the directory holding the changes between lower and
+// upper if upper is an overlay mount stacked directly on top of lower
+func overlayUpperdir(lower, upper []mount.Mount) (string, bool) {
+	if len(upper) != 1 || upper[0].Type != "overlay" || len(lower) > 1 {
+		return "", false
+	}
+	upperDirs := mountDirs(upper[0])
+	var lowerDirs []string
+	if len(lower) == 1 {
+		lowerDirs = mountDirs(lower[0])
+	}
+	if len(lowerDirs) == 0 || len(upperDirs) != len(lowerDirs)+1 {
+		return "", false
+	}
+	for i, d := range lowerDirs {
+		if upperDirs[i+1] != d {
+			return "", false
+		}
+	}
+	return upperDirs[0], true
+}
+
+// SnapshotUpperdir returns the directory holding the changes of a committed
+// overlay snapshot to its parent, given the mounts of a view of it. Views
+// of snapshots with a parent are overlay mounts without an upperdir, their
+// first lowerdir is the upperdir of the snapshot.
+func SnapshotUpperdir(view []mount.Mount) (string, bool) {
+	if len(view) != 1 || view[0].Type != "overlay" {
+		return "", false
+	}
+	var lowers []string
+	for _, o := range view[0].Options {
+		if strings.HasPrefix(o, "upperdir=") {
+			return "", false
+		}
+		if strings.HasPrefix(o, "lowerdir=") {
+			lowers = strings.Split(strings.TrimPrefix(o, "lowerdir="), ":")
+		}
+	}
+	if len(lowers) < 2 {
+		return "", false
+	}
+	return lowers[0], true
+}
+
+// mountDirs returns the directories making up a mount, topmost first
+func mountDirs(m mount.Mount) []string {
+	switch m.Type {
+	case "bind":
+		return []string{m.Source}
+	case "overlay":
+		var upper string
+		var lowers []string
+		for _, o := range m.Options {
+			if strings.HasPrefix(o, "upperdir=") {
+				upper = strings.TrimPrefix(o, "upperdir=")
+			} else if strings.HasPrefix(o, "lowerdir=") {
+				lowers = strings.Split(strings.TrimPrefix(o, "lowerdir="), ":")
+			}
+		}
+		if upper != "" {
+			return append([]string{upper}, lowers...)
+		}
+		return lowers
+	}
+	return nil
+}
+
+// writeUpperdirDiff writes the contents of an overlay upperdir as a tar
+// stream, translating overlay whiteouts and opaque directories to OCI
+// whiteout files
+func writeUpperdirDiff(ctx context.Context, w io.Writer, upperdir string) error {
+	tw := tar.NewWriter(w)
+	inodes := map[uint64]string{}
+
+	err := filepath.Walk(upperdir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		name, err := filepath.Rel(upperdir, path)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			return errors.Errorf("unsupported stat type for %s", path)
+		}
+
+		if fi.Mode()&os.ModeCharDevice != 0 && st.Rdev == 0 {
+			return tw.WriteHeader(&tar.Header{
+				Name:       filepath.Join(filepath.Dir(name), whiteoutPrefix+filepath.Base(name)),
+				Typeflag:   tar.TypeReg,
+				ModTime:    fi.ModTime(),
+				AccessTime: fi.ModTime(),
+				ChangeTime: fi.ModTime(),
+			})
+		}
+
+		hdr, opaque, err := fileHeader(path, name, fi)
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeDevice != 0 {
+			hdr.Devmajor = int64((st.Rdev >> 8) & 0xfff)
+			hdr.Devminor = int64((st.Rdev & 0xff) | ((st.Rdev >> 12) & 0xfff00))
+		}
+
+		if !fi.IsDir() && st.Nlink > 1 {
+			if source, ok := inodes[st.Ino]; ok {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Linkname = source
+				hdr.Size = 0
+			} else {
+				inodes[st.Ino] = hdr.Name
+			}
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return errors.Wrap(err, "failed to write file header")
+		}
+
+		if opaque {
+			if err := tw.WriteHeader(&tar.Header{
+				Name:       filepath.Join(name, whiteoutOpaqueDir),
+				Typeflag:   tar.TypeReg,
+				ModTime:    fi.ModTime(),
+				AccessTime: fi.ModTime(),
+				ChangeTime: fi.ModTime(),
+			}); err != nil {
+				return errors.Wrap(err, "failed to write opaque whiteout")
+			}
+		}
+
+		if hdr.Typeflag == tar.TypeReg && hdr.Size > 0 {
+			return copyFile(tw, path, hdr.Size)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,23 @@
+// +build !linux
+
+package differ
+
+import (
+	"context"
+	"io"
+
+	"github.com/containerd/containerd/mount"
+	"github.com/pkg/errors"
+)
+
+func overlayUpperdir(lower, upper []mount.Mount) (string, bool) {
+	return "", false
+}
+
+func SnapshotUpperdir(view []mount.Mount) (string, bool) {
+	return "", false
+}
+
+func writeUpperdirDiff(ctx context.Context, w io.Writer, upperdir string) error {
+	return errors.New("overlay diffs are only supported on linux")
+}
@@ -0,0 +1,12 @@
+package differ
+
+import (
+	"os"
+	"syscall"
+)
+
+func sameStat(fi1, fi2 os.FileInfo) bool {
+	s1, ok1 := fi1.Sys().(*syscall.Stat_t)
+	s2, ok2 := fi2.Sys().(*syscall.Stat_t)
+	return ok1 && ok2 && s1.Mode == s2.Mode && s1.Uid == s2.Uid && s1.Gid == s2.Gid && s1.Rdev == s2.Rdev
+}
@@ -0,0 +1,9 @@
+// +build !linux
+
+package differ
+
+import "os"
+
+func sameStat(fi1, fi2 os.FileInfo) bool {
+	return false
+}
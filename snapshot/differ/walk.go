@@ -0,0 +1,173 @@
+package differ
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/fs"
+	"github.com/containerd/containerd/mount"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/util/sparse"
+)
+
+const whiteoutPrefix = ".wh."
+
+// writeDiff mounts both sides and compares them with a double walk
+func writeDiff(ctx context.Context, w io.Writer, lower, upper []mount.Mount) error {
+	lm := snapshot.LocalMounter(lower)
+	lowerDir, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+
+	um := snapshot.LocalMounter(upper)
+	upperDir, err := um.Mount()
+	if err != nil {
+		return err
+	}
+	defer um.Unmount()
+
+	cw := &changeWriter{
+		tw:        tar.NewWriter(w),
+		lower:     lowerDir,
+		upper:     upperDir,
+		whiteoutT: time.Now(),
+		inodeSrc:  map[uint64]string{},
+		inodeRefs: map[uint64][]string{},
+	}
+	if err := changes(ctx, lowerDir, upperDir, cw.handleChange); err != nil {
+		return err
+	}
+	return errors.Wrap(cw.tw.Close(), "failed to close tar writer")
+}
+
+// changeWriter writes the changes of a double walk as a tar stream. Unlike
+// archive.WriteDiff it keeps all extended attributes and also writes files
+// where only the attributes changed.
+type changeWriter struct {
+	tw        *tar.Writer
+	lower     string
+	upper     string
+	whiteoutT time.Time
+	inodeSrc  map[uint64]string   // inode -> first written name
+	inodeRefs map[uint64][]string // inode -> unmodified names waiting for a source
+}
+
+func (cw *changeWriter) handleChange(k fs.ChangeKind, p string, fi os.FileInfo, err error) error {
+	if err != nil {
+		return err
+	}
+	name := strings.TrimPrefix(p, string(filepath.Separator))
+
+	if k == fs.ChangeKindDelete {
+		return errors.Wrap(cw.tw.WriteHeader(&tar.Header{
+			Name:       filepath.Join(filepath.Dir(name), whiteoutPrefix+filepath.Base(name)),
+			Typeflag:   tar.TypeReg,
+			ModTime:    cw.whiteoutT,
+			AccessTime: cw.whiteoutT,
+			ChangeTime: cw.whiteoutT,
+		}), "failed to write whiteout header")
+	}
+
+	path := filepath.Join(cw.upper, p)
+	hdr, _, err := fileHeader(path, name, fi)
+	if err != nil {
+		return err
+	}
+
+	var additionalLinks []string
+	inode, isHardlink := fs.GetLinkInfo(fi)
+	if isHardlink {
+		if source, ok := cw.inodeSrc[inode]; ok {
+			hdr.Typeflag = tar.TypeLink
+			hdr.Linkname = source
+			hdr.Size = 0
+		} else {
+			if k == fs.ChangeKindUnmodified {
+				cw.inodeRefs[inode] = append(cw.inodeRefs[inode], hdr.Name)
+				return nil
+			}
+			cw.inodeSrc[inode] = hdr.Name
+			additionalLinks = cw.inodeRefs[inode]
+			delete(cw.inodeRefs, inode)
+		}
+	} else if k == fs.ChangeKindUnmodified {
+		return nil
+	}
+
+	if err := cw.tw.WriteHeader(hdr); err != nil {
+		return errors.Wrap(err, "failed to write file header")
+	}
+	if hdr.Typeflag == tar.TypeReg && hdr.Size > 0 {
+		if err := copyFile(cw.tw, path, hdr.Size); err != nil {
+			return err
+		}
+	}
+
+	source := hdr.Name
+	for _, l := range additionalLinks {
+		hdr.Name = l
+		hdr.Typeflag = tar.TypeLink
+		hdr.Linkname = source
+		hdr.Size = 0
+		if err := cw.tw.WriteHeader(hdr); err != nil {
+			return errors.Wrap(err, "failed to write file header")
+		}
+	}
+	return nil
+}
+
+// fileHeader returns the tar header of a file including all of its extended
+// attributes. It also reports if the path is an opaque overlay directory.
+func fileHeader(path, name string, fi os.FileInfo) (*tar.Header, bool, error) {
+	var link string
+	if fi.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return nil, false, err
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(fi, link)
+	if err != nil {
+		return nil, false, err
+	}
+	hdr.Name = name
+	hdr.ModTime = hdr.ModTime.Truncate(time.Second)
+	if fi.IsDir() {
+		hdr.Name += "/"
+	}
+
+	xattrs, opaque, err := readXattrs(path)
+	if err != nil {
+		return nil, false, err
+	}
+	hdr.Xattrs = xattrs
+	if sparse.IsSparse(fi) {
+		sparse.Mark(hdr)
+	}
+	return hdr, opaque, nil
+}
+
+func copyFile(w io.Writer, path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(w, f)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy %s", path)
+	}
+	if n != size {
+		return errors.Errorf("short write copying %s", path)
+	}
+	return nil
+}
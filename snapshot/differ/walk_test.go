@@ -0,0 +1,187 @@
+// +build linux
+
+package differ
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/containerd/containerd/fs"
+	"github.com/containerd/containerd/mount"
+	"github.com/containerd/containerd/snapshot/overlay"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/util/sparse"
+)
+
+// cap_net_raw+ep
+var capNetRaw = []byte{1, 0, 0, 2, 0, 0x20, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	ctx := context.TODO()
+
+	tmpdir, err := ioutil.TempDir("", "differtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	lowerDir := filepath.Join(tmpdir, "lower")
+	write(t, lowerDir, "ping", "ping")
+	write(t, lowerDir, "attrs", "attrs")
+	// dir/ sorts after dir.b as a string but is walked before it
+	write(t, lowerDir, "dir/a", "a")
+	write(t, lowerDir, "dir.b", "b")
+
+	upperDir := filepath.Join(tmpdir, "upper")
+	assert.NoError(t, fs.CopyDir(upperDir, lowerDir))
+	p := func(name string) string { return filepath.Join(upperDir, name) }
+	// only the attributes change
+	assert.NoError(t, syscall.Setxattr(p("ping"), "security.capability", capNetRaw, 0))
+	assert.NoError(t, syscall.Setxattr(p("attrs"), "user.foo", []byte("bar"), 0))
+	write(t, upperDir, "new", "new")
+	write(t, upperDir, "dir/c", "c")
+	assert.NoError(t, syscall.Setxattr(p("new"), "user.new", []byte("1"), 0))
+	assert.NoError(t, os.Link(p("new"), p("new2")))
+	assert.NoError(t, syscall.Mknod(p("null"), syscall.S_IFCHR|0666, 1<<8|3))
+	f, err := os.Create(p("sparse"))
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte("end"), 16<<20)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	lower := []mount.Mount{{Type: "bind", Source: lowerDir, Options: []string{"rbind", "ro"}}}
+	upper := []mount.Mount{{Type: "bind", Source: upperDir, Options: []string{"rbind", "ro"}}}
+	buf := &bytes.Buffer{}
+	assert.NoError(t, writeDiff(ctx, buf, lower, upper))
+
+	// apply the layer on a copy of lower like a pull does
+	dest := filepath.Join(tmpdir, "dest")
+	assert.NoError(t, fs.CopyDir(dest, lowerDir))
+	_, err = sparse.Apply(ctx, dest, buf)
+	assert.NoError(t, err)
+	d := func(name string) string { return filepath.Join(dest, name) }
+
+	for _, name := range []string{"dir/a", "dir/c", "dir.b"} {
+		_, err := os.Lstat(d(name))
+		assert.NoError(t, err, name)
+	}
+	assert.Equal(t, capNetRaw, getxattr(t, d("ping"), "security.capability"))
+	assert.Equal(t, "bar", string(getxattr(t, d("attrs"), "user.foo")))
+	assert.Equal(t, "1", string(getxattr(t, d("new2"), "user.new")))
+	assert.True(t, os.SameFile(stat(t, d("new")), stat(t, d("new2"))))
+
+	st := stat(t, d("null")).Sys().(*syscall.Stat_t)
+	assert.Equal(t, uint32(syscall.S_IFCHR), st.Mode&syscall.S_IFMT)
+	assert.Equal(t, uint64(1<<8|3), uint64(st.Rdev))
+
+	fi := stat(t, d("sparse"))
+	assert.Equal(t, int64(16<<20+3), fi.Size())
+	assert.True(t, fi.Sys().(*syscall.Stat_t).Blocks*512 < 1<<20, "sparse file was not restored")
+	assert.Equal(t, stat(t, p("sparse")).ModTime().Unix(), fi.ModTime().Unix())
+}
+
+// TestLayerRoundTrip pulls a layer into a snapshot, changes it like an exec
+// step, exports the change and imports both layers again
+func TestLayerRoundTrip(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	ctx := context.TODO()
+
+	tmpdir, err := ioutil.TempDir("", "differtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	sn, err := overlay.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	// the pulled layer, written by another builder that doesn't mark sparse
+	// files
+	src := filepath.Join(tmpdir, "src")
+	writeSparse(t, src, "sparse", 8<<20)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "zeros"), make([]byte, 1<<20), 0644))
+	write(t, src, "attrs", "attrs")
+	empty := filepath.Join(tmpdir, "empty")
+	assert.NoError(t, os.MkdirAll(empty, 0700))
+	pulled := &bytes.Buffer{}
+	assert.NoError(t, writeDiff(ctx, pulled, bindMounts(empty), bindMounts(src)))
+	unmarked := bytes.Replace(pulled.Bytes(), []byte("BUILDKIT.sparse"), []byte("BUILDKIT.xxxxxx"), -1)
+
+	writeSnapshot(t, sn, "base-active", "", func(dir string) {
+		_, err := sparse.Apply(ctx, dir, bytes.NewReader(unmarked))
+		assert.NoError(t, err)
+		// zero blocks of files that were not marked stay allocated
+		assert.False(t, sparse.IsSparse(stat(t, filepath.Join(dir, "zeros"))))
+		assert.False(t, sparse.IsSparse(stat(t, filepath.Join(dir, "sparse"))))
+	})
+	assert.NoError(t, sn.Commit(ctx, "base", "base-active"))
+
+	// the exec step
+	writeSnapshot(t, sn, "exec-active", "base", func(dir string) {
+		writeSparse(t, dir, "sparse2", 8<<20)
+		assert.NoError(t, syscall.Setxattr(filepath.Join(dir, "attrs"), "user.foo", []byte("bar"), 0))
+	})
+	assert.NoError(t, sn.Commit(ctx, "exec", "exec-active"))
+
+	lower, err := sn.View(ctx, "base-view", "base")
+	assert.NoError(t, err)
+	upper, err := sn.View(ctx, "exec-view", "exec")
+	assert.NoError(t, err)
+
+	for _, fn := range []func(context.Context, io.Writer, []mount.Mount, []mount.Mount) error{WriteDiff, writeDiff} {
+		exported := &bytes.Buffer{}
+		assert.NoError(t, fn(ctx, exported, lower, upper))
+
+		dest := filepath.Join(tmpdir, "dest")
+		assert.NoError(t, os.RemoveAll(dest))
+		assert.NoError(t, os.MkdirAll(dest, 0700))
+		_, err = sparse.Apply(ctx, dest, bytes.NewReader(unmarked))
+		assert.NoError(t, err)
+		_, err = sparse.Apply(ctx, dest, exported)
+		assert.NoError(t, err)
+
+		d := func(name string) string { return filepath.Join(dest, name) }
+		assert.False(t, sparse.IsSparse(stat(t, d("zeros"))))
+		assert.Equal(t, int64(1<<20), stat(t, d("zeros")).Size())
+		fi := stat(t, d("sparse2"))
+		assert.Equal(t, int64(8<<20+3), fi.Size())
+		assert.True(t, sparse.IsSparse(fi), "sparse file was not restored")
+		assert.Equal(t, "bar", string(getxattr(t, d("attrs"), "user.foo")))
+	}
+}
+
+func writeSparse(t *testing.T, dir, name string, size int64) {
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	f, err := os.Create(filepath.Join(dir, name))
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte("end"), size)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+}
+
+func bindMounts(dir string) []mount.Mount {
+	return []mount.Mount{{Type: "bind", Source: dir, Options: []string{"rbind", "ro"}}}
+}
+
+func getxattr(t *testing.T, p, key string) []byte {
+	buf := make([]byte, 256)
+	n, err := syscall.Getxattr(p, key, buf)
+	assert.NoError(t, err)
+	if err != nil {
+		return nil
+	}
+	return buf[:n]
+}
+
+func stat(t *testing.T, p string) os.FileInfo {
+	fi, err := os.Lstat(p)
+	assert.NoError(t, err)
+	return fi
+}
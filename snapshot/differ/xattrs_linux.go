@@ -0,0 +1,43 @@
+package differ
+
+import (
+	"strings"
+	"syscall"
+
+	"github.com/containerd/continuity/sysx"
+	"github.com/pkg/errors"
+)
+
+// readXattrs returns the extended attributes of a path. Overlay internal
+// attributes are not returned, instead it reports if the directory is opaque.
+func readXattrs(path string) (map[string]string, bool, error) {
+	keys, err := sysx.LListxattr(path)
+	if err != nil {
+		if err == syscall.ENOTSUP {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "failed to list xattrs of %s", path)
+	}
+	var xattrs map[string]string
+	var opaque bool
+	for _, k := range keys {
+		v, err := sysx.LGetxattr(path, k)
+		if err != nil {
+			if err == sysx.ENODATA {
+				continue
+			}
+			return nil, false, errors.Wrapf(err, "failed to get xattr %s of %s", k, path)
+		}
+		if strings.HasPrefix(k, overlayXattrPrefix) {
+			if k == overlayOpaqueXattr && string(v) == "y" {
+				opaque = true
+			}
+			continue
+		}
+		if xattrs == nil {
+			xattrs = map[string]string{}
+		}
+		xattrs[k] = string(v)
+	}
+	return xattrs, opaque, nil
+}
@@ -0,0 +1,7 @@
+// +build !linux
+
+package differ
+
+func readXattrs(path string) (map[string]string, bool, error) {
+	return nil, false, nil
+}
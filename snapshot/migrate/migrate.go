@@ -0,0 +1,223 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/containerd/containerd/mount"
+	cdsnapshot "github.com/containerd/containerd/snapshot"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/snapshot/differ"
+	"github.com/tonistiigi/buildkit_poc/util/sparse"
+)
+
+const keyPrefix = "migrate-"
+
+type Opt struct {
+	From cdsnapshot.Snapshotter
+	To   cdsnapshot.Snapshotter
+	// Progress receives a line for every migrated or dropped snapshot
+	Progress io.Writer
+}
+
+// Migrate copies the snapshots of one snapshotter to another. Committed
+// snapshots are copied parents first, then the active ones, like the
+// snapshots of cache mounts. Every snapshot is recreated under the same name
+// by applying its diff on top of its parent, so metadata keyed by the
+// snapshot names stays valid. Committed snapshots that already exist in the
+// target are skipped, so an interrupted migration can be run again. Active
+// snapshots can't be checked for completeness and are copied again. Views
+// are dropped, they are recreated when they are needed.
+func Migrate(ctx context.Context, opt Opt) error {
+	var infos, active, views []cdsnapshot.Info
+	if err := opt.From.Walk(ctx, func(ctx context.Context, info cdsnapshot.Info) error {
+		switch {
+		case info.Kind == cdsnapshot.KindCommitted:
+			infos = append(infos, info)
+		case info.Readonly:
+			views = append(views, info)
+		default:
+			active = append(active, info)
+		}
+		return nil
+	}); err != nil && !cdsnapshot.IsNotExist(err) {
+		// the metadata store returns not exist before the first snapshot
+		return errors.Wrap(err, "failed to list snapshots")
+	}
+
+	infos, err := parentsFirst(infos)
+	if err != nil {
+		return err
+	}
+
+	total := len(infos) + len(active)
+	for i, info := range infos {
+		status := "migrated"
+		if _, err := opt.To.Stat(ctx, info.Name); err == nil {
+			status = "exists"
+		} else if err := migrateSnapshot(ctx, opt, info); err != nil {
+			return errors.Wrapf(err, "failed to migrate %s", info.Name)
+		}
+		opt.progress("[%d/%d] %s %s", i+1, total, info.Name, status)
+	}
+	for i, info := range active {
+		if err := migrateActive(ctx, opt, info); err != nil {
+			return errors.Wrapf(err, "failed to migrate %s", info.Name)
+		}
+		opt.progress("[%d/%d] %s migrated (active)", len(infos)+i+1, total, info.Name)
+	}
+	for _, info := range views {
+		opt.progress("%s dropped, views are not migrated", info.Name)
+	}
+	return nil
+}
+
+func (opt Opt) progress(format string, args ...interface{}) {
+	if opt.Progress != nil {
+		fmt.Fprintf(opt.Progress, format+"\n", args...)
+	}
+}
+
+// parentsFirst sorts snapshots so that parents come before their children
+func parentsFirst(infos []cdsnapshot.Info) ([]cdsnapshot.Info, error) {
+	byName := make(map[string]cdsnapshot.Info, len(infos))
+	for _, info := range infos {
+		byName[info.Name] = info
+	}
+	out := make([]cdsnapshot.Info, 0, len(infos))
+	done := map[string]struct{}{}
+	var visit func(info cdsnapshot.Info, depth int) error
+	visit = func(info cdsnapshot.Info, depth int) error {
+		if _, ok := done[info.Name]; ok {
+			return nil
+		}
+		if depth > len(infos) {
+			return errors.Errorf("parent loop at %s", info.Name)
+		}
+		if info.Parent != "" {
+			parent, ok := byName[info.Parent]
+			if !ok {
+				return errors.Errorf("parent %s of %s not found", info.Parent, info.Name)
+			}
+			if err := visit(parent, depth+1); err != nil {
+				return err
+			}
+		}
+		done[info.Name] = struct{}{}
+		out = append(out, info)
+		return nil
+	}
+	for _, info := range infos {
+		if err := visit(info, 0); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func migrateSnapshot(ctx context.Context, opt Opt, info cdsnapshot.Info) error {
+	upper, releaseUpper, err := view(ctx, opt.From, info.Name)
+	if err != nil {
+		return err
+	}
+	defer releaseUpper()
+	lower, releaseLower, err := view(ctx, opt.From, info.Parent)
+	if err != nil {
+		return err
+	}
+	defer releaseLower()
+
+	key := keyPrefix + info.Name
+	// left behind by an interrupted migration
+	if _, err := opt.To.Stat(ctx, key); err == nil {
+		if err := opt.To.Remove(ctx, key); err != nil {
+			return err
+		}
+	}
+	mounts, err := opt.To.Prepare(ctx, key, info.Parent)
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare target")
+	}
+	if err := apply(ctx, mounts, lower, upper); err != nil {
+		opt.To.Remove(ctx, key)
+		return err
+	}
+	return opt.To.Commit(ctx, info.Name, key)
+}
+
+// migrateActive recreates an active snapshot under the same key. A copy
+// left by an earlier run is replaced.
+func migrateActive(ctx context.Context, opt Opt, info cdsnapshot.Info) error {
+	upper, err := opt.From.Mounts(ctx, info.Name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get mounts of %s", info.Name)
+	}
+	lower, releaseLower, err := view(ctx, opt.From, info.Parent)
+	if err != nil {
+		return err
+	}
+	defer releaseLower()
+
+	if _, err := opt.To.Stat(ctx, info.Name); err == nil {
+		if err := opt.To.Remove(ctx, info.Name); err != nil {
+			return err
+		}
+	}
+	mounts, err := opt.To.Prepare(ctx, info.Name, info.Parent)
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare target")
+	}
+	if err := apply(ctx, mounts, lower, upper); err != nil {
+		opt.To.Remove(ctx, info.Name)
+		return err
+	}
+	return nil
+}
+
+// apply writes the diff between lower and upper to the target mounts
+func apply(ctx context.Context, target, lower, upper []mount.Mount) error {
+	lm := snapshot.LocalMounter(target)
+	dir, err := lm.Mount()
+	if err != nil {
+		return err
+	}
+	defer lm.Unmount()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(differ.WriteDiff(ctx, pw, lower, upper))
+	}()
+	_, err = sparse.Apply(ctx, dir, pr)
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return errors.Wrap(err, "failed to apply diff")
+	}
+	return lm.Unmount()
+}
+
+// view returns read-only mounts of a committed snapshot. An empty name
+// returns an empty directory.
+func view(ctx context.Context, sn cdsnapshot.Snapshotter, name string) ([]mount.Mount, func(), error) {
+	if name == "" {
+		dir, err := ioutil.TempDir("", "buildkit-migrate")
+		if err != nil {
+			return nil, nil, err
+		}
+		return []mount.Mount{{Type: "bind", Source: dir, Options: []string{"rbind", "ro"}}}, func() { os.RemoveAll(dir) }, nil
+	}
+	key := keyPrefix + "view-" + name
+	if _, err := sn.Stat(ctx, key); err == nil {
+		if err := sn.Remove(ctx, key); err != nil {
+			return nil, nil, err
+		}
+	}
+	mounts, err := sn.View(ctx, key, name)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to view %s", name)
+	}
+	return mounts, func() { sn.Remove(ctx, key) }, nil
+}
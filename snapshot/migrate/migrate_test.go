@@ -0,0 +1,139 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cdsnapshot "github.com/containerd/containerd/snapshot"
+	"github.com/containerd/containerd/snapshot/naive"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "migratetest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	from, err := naive.NewSnapshotter(filepath.Join(tmpdir, "from"))
+	assert.NoError(t, err)
+	to, err := naive.NewSnapshotter(filepath.Join(tmpdir, "to"))
+	assert.NoError(t, err)
+
+	writeSnapshot(t, from, "base-active", "", func(dir string) {
+		write(t, dir, "foo", "foo")
+		write(t, dir, "dir/bar", "bar")
+	})
+	assert.NoError(t, from.Commit(ctx, "base", "base-active"))
+	writeSnapshot(t, from, "child-active", "base", func(dir string) {
+		write(t, dir, "foo", "foo2")
+		assert.NoError(t, os.RemoveAll(filepath.Join(dir, "dir")))
+		assert.NoError(t, os.Symlink("foo", filepath.Join(dir, "link")))
+	})
+	assert.NoError(t, from.Commit(ctx, "child", "child-active"))
+	writeSnapshot(t, from, "active", "child", func(dir string) {
+		write(t, dir, "cache", "cache")
+	})
+	_, err = from.View(ctx, "view", "child")
+	assert.NoError(t, err)
+
+	// left behind by an interrupted migration
+	_, err = to.Prepare(ctx, keyPrefix+"child", "")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Migrate(ctx, Opt{From: from, To: to, Progress: &buf}))
+	assert.Equal(t, "[1/3] base migrated\n[2/3] child migrated\n[3/3] active migrated (active)\nview dropped, views are not migrated\n", buf.String())
+
+	info, err := to.Stat(ctx, "child")
+	assert.NoError(t, err)
+	assert.Equal(t, cdsnapshot.KindCommitted, info.Kind)
+	assert.Equal(t, "base", info.Parent)
+	info, err = to.Stat(ctx, "active")
+	assert.NoError(t, err)
+	assert.Equal(t, cdsnapshot.KindActive, info.Kind)
+	assert.Equal(t, "child", info.Parent)
+	_, err = to.Stat(ctx, "view")
+	assert.Error(t, err)
+	_, err = to.Stat(ctx, keyPrefix+"child")
+	assert.Error(t, err)
+
+	mounts, err := to.View(ctx, "view", "child")
+	assert.NoError(t, err)
+	lm := snapshot.LocalMounter(mounts)
+	dir, err := lm.Mount()
+	assert.NoError(t, err)
+	dt, err := ioutil.ReadFile(filepath.Join(dir, "foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo2", string(dt))
+	_, err = os.Stat(filepath.Join(dir, "dir"))
+	assert.True(t, os.IsNotExist(err))
+	target, err := os.Readlink(filepath.Join(dir, "link"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", target)
+	assert.NoError(t, lm.Unmount())
+
+	mounts, err = to.Mounts(ctx, "active")
+	assert.NoError(t, err)
+	lm = snapshot.LocalMounter(mounts)
+	dir, err = lm.Mount()
+	assert.NoError(t, err)
+	dt, err = ioutil.ReadFile(filepath.Join(dir, "cache"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cache", string(dt))
+	dt, err = ioutil.ReadFile(filepath.Join(dir, "foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo2", string(dt))
+	assert.NoError(t, lm.Unmount())
+
+	// migrated snapshots are skipped when running again
+	buf.Reset()
+	assert.NoError(t, Migrate(ctx, Opt{From: from, To: to, Progress: &buf}))
+	assert.Equal(t, 2, strings.Count(buf.String(), " exists\n"))
+	assert.Contains(t, buf.String(), "[3/3] active migrated (active)\n")
+
+	// the views of the source are removed
+	var names []string
+	assert.NoError(t, from.Walk(ctx, func(ctx context.Context, info cdsnapshot.Info) error {
+		names = append(names, info.Name)
+		return nil
+	}))
+	assert.Equal(t, 4, len(names))
+}
+
+func TestParentsFirst(t *testing.T) {
+	infos, err := parentsFirst([]cdsnapshot.Info{
+		{Name: "c", Parent: "b"},
+		{Name: "a"},
+		{Name: "b", Parent: "a"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []cdsnapshot.Info{{Name: "a"}, {Name: "b", Parent: "a"}, {Name: "c", Parent: "b"}}, infos)
+
+	_, err = parentsFirst([]cdsnapshot.Info{{Name: "a", Parent: "missing"}})
+	assert.Error(t, err)
+	_, err = parentsFirst([]cdsnapshot.Info{{Name: "a", Parent: "b"}, {Name: "b", Parent: "a"}})
+	assert.Error(t, err)
+}
+
+func writeSnapshot(t *testing.T, sn cdsnapshot.Snapshotter, key, parent string, fn func(dir string)) {
+	mounts, err := sn.Prepare(context.TODO(), key, parent)
+	assert.NoError(t, err)
+	lm := snapshot.LocalMounter(mounts)
+	dir, err := lm.Mount()
+	assert.NoError(t, err)
+	fn(dir)
+	assert.NoError(t, lm.Unmount())
+}
+
+func write(t *testing.T, dir, p, dt string) {
+	p = filepath.Join(dir, p)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	assert.NoError(t, ioutil.WriteFile(p, []byte(dt), 0644))
+}
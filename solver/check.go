@@ -0,0 +1,32 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+)
+
+// checkOp warns about fields of the op that are deprecated or have no
+// effect. The warnings are sent for the vertex of the context.
+func checkOp(ctx context.Context, op *pb.Op) {
+	exec, ok := op.Op.(*pb.Op_Exec)
+	if !ok {
+		return
+	}
+	for _, m := range exec.Exec.Mounts {
+		if m.Selector != "" {
+			warnings.Warn(ctx, warnings.SeverityWarning, "selector %s of mount %s is deprecated and ignored, the root of the input is mounted", m.Selector, m.Dest)
+		}
+		switch m.MountType {
+		case pb.CACHE:
+			if m.Output != -1 {
+				warnings.Warn(ctx, warnings.SeverityWarning, "output of cache mount %s is ignored, cache mounts are not outputs", m.Dest)
+			}
+		case pb.BIND:
+			if m.CacheID != "" {
+				warnings.Warn(ctx, warnings.SeverityWarning, "cache ID %s of bind mount %s is ignored", m.CacheID, m.Dest)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package solver
+
+import (
+	"context"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+)
+
+type testWarnings []warnings.Warning
+
+func (w *testWarnings) Warn(wr warnings.Warning) {
+	*w = append(*w, wr)
+}
+
+func TestCheckOp(t *testing.T) {
+	var ws testWarnings
+	dgst := digest.FromBytes([]byte("foo"))
+	ctx := warnings.WithVertex(warnings.WithWriter(context.TODO(), &ws), dgst)
+
+	checkOp(ctx, &pb.Op{Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: "local://foo"}}})
+	checkOp(ctx, &pb.Op{Op: &pb.Op_Exec{Exec: &pb.ExecOp{
+		Meta: &pb.Meta{Args: []string{"true"}},
+		Mounts: []*pb.Mount{
+			{Input: 0, Dest: "/", Output: 0},
+			{Input: -1, Dest: "/cache", Output: -1, MountType: pb.CACHE, CacheID: "go"},
+		},
+	}}})
+	assert.Equal(t, 0, len(ws))
+
+	checkOp(ctx, &pb.Op{Op: &pb.Op_Exec{Exec: &pb.ExecOp{
+		Meta: &pb.Meta{Args: []string{"true"}},
+		Mounts: []*pb.Mount{
+			{Input: 0, Selector: "/src", Dest: "/", Output: 0, CacheID: "go"},
+			{Input: -1, Dest: "/cache", Output: 1, MountType: pb.CACHE, CacheID: "go"},
+		},
+	}}})
+	assert.Equal(t, 3, len(ws))
+	for _, w := range ws {
+		assert.Equal(t, dgst, w.Vertex)
+		assert.Equal(t, warnings.SeverityWarning, w.Severity)
+	}
+	assert.Contains(t, ws[0].Message, "selector /src")
+	assert.Contains(t, ws[1].Message, "cache ID go of bind mount /")
+	assert.Contains(t, ws[2].Message, "output of cache mount /cache")
+}
@@ -0,0 +1,53 @@
+package solver
+
+import (
+	"context"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/cache"
+)
+
+// Journal keeps the results of solved vertices so that an interrupted solve
+// can be resumed without running them again
+type Journal interface {
+	// Results returns the cache record IDs of a solved vertex
+	Results(dgst digest.Digest) ([]string, bool)
+	Record(dgst digest.Digest, ids []string) error
+}
+
+type journalKeyT string
+
+var journalKey = journalKeyT("buildkit/solver/journal")
+
+func WithJournal(ctx context.Context, j Journal) context.Context {
+	return context.WithValue(ctx, journalKey, j)
+}
+
+func getJournal(ctx context.Context) Journal {
+	j, _ := ctx.Value(journalKey).(Journal)
+	return j
+}
+
+func loadResults(cm cache.Accessor, ids []string) ([]cache.ImmutableRef, error) {
+	refs := make([]cache.ImmutableRef, 0, len(ids))
+	for _, id := range ids {
+		ref, err := cm.Get(id)
+		if err != nil {
+			for _, r := range refs {
+				r.Release()
+			}
+			return nil, errors.Wrapf(err, "failed to load result %s", id)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func recordResults(j Journal, dgst digest.Digest, refs []cache.ImmutableRef) error {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID())
+	}
+	return j.Record(dgst, ids)
+}
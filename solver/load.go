@@ -1,17 +1,20 @@
 package solver
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"sync"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/Sirupsen/logrus"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"github.com/tonistiigi/buildkit_poc/cache"
 	"github.com/tonistiigi/buildkit_poc/solver/pb"
 	"github.com/tonistiigi/buildkit_poc/source"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
 	"github.com/tonistiigi/buildkit_poc/worker"
 )
 
@@ -22,6 +25,18 @@ type opVertex struct {
 	refs   []cache.ImmutableRef
 	err    error
 	dgst   digest.Digest
+	// orig are the digests of the vertices of the definition an optimized
+	// vertex was created from. They are used for reporting to the client
+	// and for the journal.
+	orig []digest.Digest
+}
+
+// clientDigests returns the digests of the vertex in the definition
+func (g *opVertex) clientDigests() []digest.Digest {
+	if len(g.orig) > 0 {
+		return g.orig
+	}
+	return []digest.Digest{g.dgst}
 }
 
 func Load(ops [][]byte) (*opVertex, error) {
@@ -82,6 +97,8 @@ type Opt struct {
 	SourceManager *source.Manager
 	CacheManager  cache.Manager // TODO: this shouldn't be needed before instruction cache
 	Worker        worker.Worker
+	// DumpGraph logs the optimized graph of every solve
+	DumpGraph bool
 }
 
 func (g *opVertex) inputRequiresExport(i int) bool {
@@ -97,11 +114,47 @@ func New(opt Opt) *Solver {
 }
 
 func (s *Solver) Solve(ctx context.Context, g *opVertex) error {
-	err := g.solve(ctx, s.opt) // TODO: separate exporting
+	g, err := s.optimize(g)
+	if err != nil {
+		return err
+	}
+	err = g.solve(ctx, s.opt) // TODO: separate exporting
 	g.release()
 	return err
 }
 
+// Build solves g like Solve and returns a new reference to its first
+// output. The caller releases the reference.
+func (s *Solver) Build(ctx context.Context, g *opVertex) (cache.ImmutableRef, error) {
+	g, err := s.optimize(g)
+	if err != nil {
+		return nil, err
+	}
+	defer g.release()
+	if err := g.solve(ctx, s.opt); err != nil {
+		return nil, err
+	}
+	if len(g.refs) == 0 || g.refs[0] == nil {
+		return nil, errors.Errorf("%s has no result", g.dgst)
+	}
+	return s.opt.CacheManager.Get(g.refs[0].ID())
+}
+
+func (s *Solver) optimize(g *opVertex) (*opVertex, error) {
+	og, err := Optimize(g)
+	if err != nil {
+		return nil, err
+	}
+	if s.opt.DumpGraph {
+		var buf bytes.Buffer
+		if err := Dump(&buf, og); err != nil {
+			return nil, err
+		}
+		logrus.Infof("optimized graph of %s, %d vertices to %d:\n%s", g.dgst, count(g), count(og), buf.String())
+	}
+	return og, nil
+}
+
 func (g *opVertex) release() (retErr error) {
 	for _, i := range g.inputs {
 		if err := i.release(); err != nil {
@@ -115,6 +168,8 @@ func (g *opVertex) release() (retErr error) {
 			}
 		}
 	}
+	// vertices used by multiple inputs are only released once
+	g.refs = nil
 	return retErr
 }
 
@@ -145,6 +200,23 @@ func (g *opVertex) solve(ctx context.Context, opt Opt) (retErr error) {
 		}
 	}()
 
+	j := getJournal(ctx)
+	if j != nil {
+		for _, dgst := range g.clientDigests() {
+			ids, ok := j.Results(dgst)
+			if !ok {
+				continue
+			}
+			refs, err := loadResults(opt.CacheManager, ids)
+			if err == nil {
+				g.refs = refs
+				return nil
+			}
+			logrus.Warnf("failed to reuse results of %s, solving again: %v", dgst, err)
+			break
+		}
+	}
+
 	if len(g.inputs) > 0 {
 		eg, ctx := errgroup.WithContext(ctx)
 
@@ -163,6 +235,9 @@ func (g *opVertex) solve(ctx context.Context, opt Opt) (retErr error) {
 		}
 	}
 
+	ctx = warnings.WithVertex(ctx, g.clientDigests()...)
+	checkOp(ctx, g.op)
+
 	switch op := g.op.Op.(type) {
 	case *pb.Op_Source:
 		id, err := source.FromString(op.Source.Identifier)
@@ -178,7 +253,7 @@ func (g *opVertex) solve(ctx context.Context, opt Opt) (retErr error) {
 
 		mounts := make(map[string]cache.Mountable)
 
-		var outputs []cache.MutableRef
+		var outputs, cacheMounts []cache.MutableRef
 
 		defer func() {
 			for _, o := range outputs {
@@ -189,9 +264,24 @@ func (g *opVertex) solve(ctx context.Context, opt Opt) (retErr error) {
 					}
 				}
 			}
+			for _, m := range cacheMounts {
+				m.Release() // TODO: log error
+			}
 		}()
 
 		for _, m := range op.Exec.Mounts {
+			if m.MountType == pb.CACHE {
+				if m.CacheID == "" {
+					return errors.Errorf("cache mount %s has no ID", m.Dest)
+				}
+				active, err := opt.CacheManager.GetCacheMount(m.CacheID)
+				if err != nil {
+					return err
+				}
+				cacheMounts = append(cacheMounts, active)
+				mounts[m.Dest] = active
+				continue
+			}
 			var mountable cache.Mountable
 			ref := g.getInputRef(int(m.Input))
 			mountable = ref
@@ -207,9 +297,10 @@ func (g *opVertex) solve(ctx context.Context, opt Opt) (retErr error) {
 		}
 
 		meta := worker.Meta{
-			Args: op.Exec.Meta.Args,
-			Env:  op.Exec.Meta.Env,
-			Cwd:  op.Exec.Meta.Cwd,
+			Args:    op.Exec.Meta.Args,
+			Env:     op.Exec.Meta.Env,
+			Cwd:     op.Exec.Meta.Cwd,
+			Devices: op.Exec.Meta.Devices,
 		}
 
 		if err := opt.Worker.Exec(ctx, meta, mounts, os.Stderr, os.Stderr); err != nil {
@@ -230,5 +321,13 @@ func (g *opVertex) solve(ctx context.Context, opt Opt) (retErr error) {
 	default:
 		return errors.Errorf("invalid op type")
 	}
+
+	if j != nil {
+		for _, dgst := range g.clientDigests() {
+			if err := recordResults(j, dgst, g.refs); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,271 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+)
+
+// Optimize rewrites a loaded graph before solving. Ops are rewritten to a
+// canonical form, vertices that become equal are merged so they are solved
+// once, and inputs that no mount or copy source uses are dropped together
+// with the vertices only they referenced. Vertex digests are recomputed from
+// the rewritten ops, the digests of the vertices of the definition they were
+// created from are kept for reporting.
+func Optimize(g *opVertex) (*opVertex, error) {
+	o := &optimizer{
+		done:   map[*opVertex]*opVertex{},
+		merged: map[digest.Digest]*opVertex{},
+	}
+	return o.optimize(g)
+}
+
+type optimizer struct {
+	// done maps the loaded vertices to their optimized version
+	done   map[*opVertex]*opVertex
+	merged map[digest.Digest]*opVertex
+}
+
+func (o *optimizer) optimize(v *opVertex) (*opVertex, error) {
+	if nv, ok := o.done[v]; ok {
+		return nv, nil
+	}
+
+	op := *v.op
+	op.Inputs = nil
+	var inputs []*opVertex
+	index := map[int]int{} // old input index to new one
+	for i, in := range v.op.Inputs {
+		if !inputUsed(v.op, i) {
+			continue
+		}
+		src := v.inputFor(digest.Digest(in.Digest))
+		if src == nil {
+			return nil, errors.Errorf("failed to find input %s of %s", in.Digest, v.dgst)
+		}
+		nsrc, err := o.optimize(src)
+		if err != nil {
+			return nil, err
+		}
+		ni := &pb.Input{Digest: string(nsrc.dgst), Index: in.Index}
+		for j, prev := range op.Inputs {
+			if prev.Digest == ni.Digest && prev.Index == ni.Index {
+				index[i] = j
+				break
+			}
+		}
+		if _, ok := index[i]; !ok {
+			index[i] = len(op.Inputs)
+			op.Inputs = append(op.Inputs, ni)
+			inputs = appendVertex(inputs, nsrc)
+		}
+	}
+
+	canonicalize(&op, index)
+
+	dt, err := op.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	dgst := digest.FromBytes(dt)
+	nv, ok := o.merged[dgst]
+	if !ok {
+		nv = &opVertex{op: &op, inputs: inputs, dgst: dgst}
+		o.merged[dgst] = nv
+	}
+	for _, d := range v.clientDigests() {
+		nv.orig = appendDigest(nv.orig, d)
+	}
+	o.done[v] = nv
+	return nv, nil
+}
+
+// inputUsed returns true if input i of op is read by the op
+func inputUsed(op *pb.Op, i int) bool {
+	switch o := op.Op.(type) {
+	case *pb.Op_Exec:
+		for _, m := range o.Exec.Mounts {
+			if m.MountType != pb.CACHE && int(m.Input) == i {
+				return true
+			}
+		}
+		return false
+	case *pb.Op_Copy:
+		for _, s := range o.Copy.Src {
+			if int(s.Input) == i {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// canonicalize rewrites op to its canonical form. index maps the old input
+// indexes to the deduplicated ones. The parts of op that are changed are
+// copied so the loaded op is not modified.
+func canonicalize(op *pb.Op, index map[int]int) {
+	switch o := op.Op.(type) {
+	case *pb.Op_Exec:
+		exec := *o.Exec
+		exec.Mounts = make([]*pb.Mount, 0, len(o.Exec.Mounts))
+		for _, m := range o.Exec.Mounts {
+			nm := *m
+			if m.MountType != pb.CACHE {
+				nm.Input = int64(index[int(m.Input)])
+			}
+			exec.Mounts = append(exec.Mounts, &nm)
+		}
+		if exec.Meta != nil {
+			// devices are a set, their order doesn't matter
+			meta := *exec.Meta
+			meta.Devices = sortedUnique(meta.Devices)
+			exec.Meta = &meta
+		}
+		op.Op = &pb.Op_Exec{Exec: &exec}
+	case *pb.Op_Copy:
+		cp := *o.Copy
+		cp.Src = make([]*pb.CopySource, 0, len(o.Copy.Src))
+		for _, s := range o.Copy.Src {
+			ns := *s
+			ns.Input = int64(index[int(s.Input)])
+			cp.Src = append(cp.Src, &ns)
+		}
+		op.Op = &pb.Op_Copy{Copy: &cp}
+	}
+}
+
+func sortedUnique(in []string) []string {
+	if len(in) == 0 {
+		return in
+	}
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	j := 0
+	for i := 1; i < len(out); i++ {
+		if out[i] != out[j] {
+			j++
+			out[j] = out[i]
+		}
+	}
+	return out[:j+1]
+}
+
+func appendVertex(vs []*opVertex, v *opVertex) []*opVertex {
+	for _, prev := range vs {
+		if prev == v {
+			return vs
+		}
+	}
+	return append(vs, v)
+}
+
+func appendDigest(ds []digest.Digest, d digest.Digest) []digest.Digest {
+	for _, prev := range ds {
+		if prev == d {
+			return ds
+		}
+	}
+	return append(ds, d)
+}
+
+func (g *opVertex) inputFor(dgst digest.Digest) *opVertex {
+	for _, v := range g.inputs {
+		if v.dgst == dgst {
+			return v
+		}
+	}
+	return nil
+}
+
+// count returns the number of distinct vertices in the graph
+func count(g *opVertex) int {
+	seen := map[*opVertex]struct{}{}
+	g.walk(func(v *opVertex) { seen[v] = struct{}{} }, map[*opVertex]struct{}{})
+	return len(seen)
+}
+
+// Depth returns the number of vertices on the longest path from g to a
+// vertex without inputs
+func Depth(g *opVertex) int {
+	return depth(g, map[*opVertex]int{})
+}
+
+func depth(g *opVertex, memo map[*opVertex]int) int {
+	if d, ok := memo[g]; ok {
+		return d
+	}
+	d := 0
+	for _, in := range g.inputs {
+		if id := depth(in, memo); id > d {
+			d = id
+		}
+	}
+	memo[g] = d + 1
+	return d + 1
+}
+
+// Dump writes a readable description of the graph to w, inputs before the
+// vertices that use them
+func Dump(w io.Writer, g *opVertex) error {
+	var err error
+	g.walk(func(v *opVertex) {
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(w, "%s %s\n", v.dgst, describe(v.op))
+	}, map[*opVertex]struct{}{})
+	return err
+}
+
+func (g *opVertex) walk(f func(*opVertex), seen map[*opVertex]struct{}) {
+	if _, ok := seen[g]; ok {
+		return
+	}
+	seen[g] = struct{}{}
+	for _, in := range g.inputs {
+		in.walk(f, seen)
+	}
+	f(g)
+}
+
+func describe(op *pb.Op) string {
+	inputs := make([]string, 0, len(op.Inputs))
+	for _, in := range op.Inputs {
+		inputs = append(inputs, fmt.Sprintf("%s:%d", digest.Digest(in.Digest).Hex()[:12], in.Index))
+	}
+	var s string
+	switch o := op.Op.(type) {
+	case *pb.Op_Source:
+		s = "source " + o.Source.Identifier
+	case *pb.Op_Exec:
+		var args []string
+		if o.Exec.Meta != nil {
+			args = o.Exec.Meta.Args
+		}
+		s = fmt.Sprintf("exec %q", args)
+		for _, m := range o.Exec.Mounts {
+			if m.MountType == pb.CACHE {
+				s += fmt.Sprintf(" %s=cache:%s", m.Dest, m.CacheID)
+				continue
+			}
+			s += fmt.Sprintf(" %s=%d", m.Dest, m.Input)
+			if m.Output != -1 {
+				s += fmt.Sprintf("->%d", m.Output)
+			}
+		}
+	case *pb.Op_Copy:
+		s = "copy " + o.Copy.Dest
+	default:
+		s = "unknown"
+	}
+	if len(inputs) > 0 {
+		s += " inputs=" + strings.Join(inputs, ",")
+	}
+	return s
+}
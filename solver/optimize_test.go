@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/solver/pb"
+)
+
+func TestOptimize(t *testing.T) {
+	var def [][]byte
+	add := func(op *pb.Op) *pb.Input {
+		dt, err := op.Marshal()
+		assert.NoError(t, err)
+		def = append(def, dt)
+		return &pb.Input{Digest: string(digest.FromBytes(dt))}
+	}
+	exec := func(devices []string, inputs ...*pb.Input) *pb.Op {
+		op := &pb.Op{
+			Inputs: inputs,
+			Op: &pb.Op_Exec{Exec: &pb.ExecOp{
+				Meta: &pb.Meta{Args: []string{"true"}, Devices: devices},
+			}},
+		}
+		for i := range inputs {
+			op.GetExec().Mounts = append(op.GetExec().Mounts, &pb.Mount{Input: int64(i), Dest: "/" + strings.Repeat("a", i), Output: int64(i)})
+		}
+		return op
+	}
+
+	src := add(&pb.Op{Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: "docker-image://docker.io/library/busybox:latest"}}})
+	unused := add(&pb.Op{Op: &pb.Op_Source{Source: &pb.SourceOp{Identifier: "local://unused"}}})
+	// equal except for the order of the devices
+	a := add(exec([]string{"/dev/fuse", "/dev/kvm"}, src))
+	b := add(exec([]string{"/dev/kvm", "/dev/fuse", "/dev/kvm"}, src))
+
+	root := exec(nil, a, b)
+	root.Inputs = append(root.Inputs, unused)
+	// cache mounts don't read an input
+	root.GetExec().Mounts = append(root.GetExec().Mounts, &pb.Mount{Input: -1, Dest: "/cache", Output: -1, MountType: pb.CACHE, CacheID: "go"})
+	add(root)
+
+	g, err := Load(def)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count(g))
+	assert.Equal(t, 3, Depth(g))
+
+	og, err := Optimize(g)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count(og))
+
+	// both mounts read the merged vertex and the unused input is dropped
+	assert.Equal(t, 1, len(og.op.Inputs))
+	assert.Equal(t, 1, len(og.inputs))
+	mounts := og.op.GetExec().Mounts
+	assert.Equal(t, int64(0), mounts[0].Input)
+	assert.Equal(t, int64(0), mounts[1].Input)
+	assert.Equal(t, int64(-1), mounts[2].Input)
+	assert.Equal(t, []string{"/dev/fuse", "/dev/kvm"}, og.inputs[0].op.GetExec().Meta.Devices)
+
+	dt, err := og.op.Marshal()
+	assert.NoError(t, err)
+	assert.Equal(t, digest.FromBytes(dt), og.dgst)
+	assert.Equal(t, og.inputs[0].dgst, digest.Digest(og.op.Inputs[0].Digest))
+
+	// the digests of the definition are kept for reporting
+	assert.Equal(t, []digest.Digest{g.dgst}, og.clientDigests())
+	assert.Equal(t, []digest.Digest{digest.Digest(a.Digest), digest.Digest(b.Digest)}, og.inputs[0].clientDigests())
+	assert.Equal(t, []digest.Digest{digest.Digest(src.Digest)}, og.inputs[0].inputs[0].clientDigests())
+
+	// the loaded graph is not modified
+	assert.Equal(t, 3, len(g.op.Inputs))
+	assert.Equal(t, []string{"/dev/kvm", "/dev/fuse", "/dev/kvm"}, g.inputs[1].op.GetExec().Meta.Devices)
+
+	// optimizing again doesn't change the graph
+	og2, err := Optimize(og)
+	assert.NoError(t, err)
+	assert.Equal(t, og.dgst, og2.dgst)
+	assert.Equal(t, og.inputs[0].clientDigests(), og2.inputs[0].clientDigests())
+
+	var buf bytes.Buffer
+	assert.NoError(t, Dump(&buf, og))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Contains(t, lines[0], "source docker-image://docker.io/library/busybox:latest")
+	assert.Contains(t, lines[2], string(og.dgst)+` exec ["true"] /=0->0 /a=0->1 /cache=cache:go inputs=`)
+}
@@ -24,6 +24,8 @@ import proto "github.com/gogo/protobuf/proto"
 import fmt "fmt"
 import math "math"
 
+import strconv "strconv"
+
 import strings "strings"
 import reflect "reflect"
 
@@ -40,6 +42,24 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
+type MountType int32
+
+const (
+	BIND  MountType = 0
+	CACHE MountType = 1
+)
+
+var MountType_name = map[int32]string{
+	0: "BIND",
+	1: "CACHE",
+}
+var MountType_value = map[string]int32{
+	"BIND":  0,
+	"CACHE": 1,
+}
+
+func (MountType) EnumDescriptor() ([]byte, []int) { return fileDescriptorOps, []int{0} }
+
 type Op struct {
 	Inputs []*Input `protobuf:"bytes,1,rep,name=inputs" json:"inputs,omitempty"`
 	// Types that are valid to be assigned to Op:
@@ -249,9 +269,10 @@ func (m *ExecOp) GetMounts() []*Mount {
 }
 
 type Meta struct {
-	Args []string `protobuf:"bytes,1,rep,name=args" json:"args,omitempty"`
-	Env  []string `protobuf:"bytes,2,rep,name=env" json:"env,omitempty"`
-	Cwd  string   `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	Args    []string `protobuf:"bytes,1,rep,name=args" json:"args,omitempty"`
+	Env     []string `protobuf:"bytes,2,rep,name=env" json:"env,omitempty"`
+	Cwd     string   `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	Devices []string `protobuf:"bytes,4,rep,name=devices" json:"devices,omitempty"`
 }
 
 func (m *Meta) Reset()                    { *m = Meta{} }
@@ -279,11 +300,20 @@ func (m *Meta) GetCwd() string {
 	return ""
 }
 
+func (m *Meta) GetDevices() []string {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
 type Mount struct {
-	Input    int64  `protobuf:"varint,1,opt,name=input,proto3" json:"input,omitempty"`
-	Selector string `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
-	Dest     string `protobuf:"bytes,3,opt,name=dest,proto3" json:"dest,omitempty"`
-	Output   int64  `protobuf:"varint,4,opt,name=output,proto3" json:"output,omitempty"`
+	Input     int64     `protobuf:"varint,1,opt,name=input,proto3" json:"input,omitempty"`
+	Selector  string    `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	Dest      string    `protobuf:"bytes,3,opt,name=dest,proto3" json:"dest,omitempty"`
+	Output    int64     `protobuf:"varint,4,opt,name=output,proto3" json:"output,omitempty"`
+	MountType MountType `protobuf:"varint,5,opt,name=mountType,proto3,enum=pb.MountType" json:"mountType,omitempty"`
+	CacheID   string    `protobuf:"bytes,6,opt,name=cacheID,proto3" json:"cacheID,omitempty"`
 }
 
 func (m *Mount) Reset()                    { *m = Mount{} }
@@ -318,6 +348,20 @@ func (m *Mount) GetOutput() int64 {
 	return 0
 }
 
+func (m *Mount) GetMountType() MountType {
+	if m != nil {
+		return m.MountType
+	}
+	return BIND
+}
+
+func (m *Mount) GetCacheID() string {
+	if m != nil {
+		return m.CacheID
+	}
+	return ""
+}
+
 type CopyOp struct {
 	Src  []*CopySource `protobuf:"bytes,1,rep,name=src" json:"src,omitempty"`
 	Dest string        `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`
@@ -388,6 +432,14 @@ func init() {
 	proto.RegisterType((*CopyOp)(nil), "pb.CopyOp")
 	proto.RegisterType((*CopySource)(nil), "pb.CopySource")
 	proto.RegisterType((*SourceOp)(nil), "pb.SourceOp")
+	proto.RegisterEnum("pb.MountType", MountType_name, MountType_value)
+}
+func (x MountType) String() string {
+	s, ok := MountType_name[int32(x)]
+	if ok {
+		return s
+	}
+	return strconv.Itoa(int(x))
 }
 func (this *Op) Equal(that interface{}) bool {
 	if that == nil {
@@ -638,6 +690,14 @@ func (this *Meta) Equal(that interface{}) bool {
 	if this.Cwd != that1.Cwd {
 		return false
 	}
+	if len(this.Devices) != len(that1.Devices) {
+		return false
+	}
+	for i := range this.Devices {
+		if this.Devices[i] != that1.Devices[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *Mount) Equal(that interface{}) bool {
@@ -677,6 +737,12 @@ func (this *Mount) Equal(that interface{}) bool {
 	if this.Output != that1.Output {
 		return false
 	}
+	if this.MountType != that1.MountType {
+		return false
+	}
+	if this.CacheID != that1.CacheID {
+		return false
+	}
 	return true
 }
 func (this *CopyOp) Equal(that interface{}) bool {
@@ -849,11 +915,12 @@ func (this *Meta) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&pb.Meta{")
 	s = append(s, "Args: "+fmt.Sprintf("%#v", this.Args)+",\n")
 	s = append(s, "Env: "+fmt.Sprintf("%#v", this.Env)+",\n")
 	s = append(s, "Cwd: "+fmt.Sprintf("%#v", this.Cwd)+",\n")
+	s = append(s, "Devices: "+fmt.Sprintf("%#v", this.Devices)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -861,12 +928,14 @@ func (this *Mount) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 10)
 	s = append(s, "&pb.Mount{")
 	s = append(s, "Input: "+fmt.Sprintf("%#v", this.Input)+",\n")
 	s = append(s, "Selector: "+fmt.Sprintf("%#v", this.Selector)+",\n")
 	s = append(s, "Dest: "+fmt.Sprintf("%#v", this.Dest)+",\n")
 	s = append(s, "Output: "+fmt.Sprintf("%#v", this.Output)+",\n")
+	s = append(s, "MountType: "+fmt.Sprintf("%#v", this.MountType)+",\n")
+	s = append(s, "CacheID: "+fmt.Sprintf("%#v", this.CacheID)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1111,6 +1180,21 @@ func (m *Meta) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintOps(dAtA, i, uint64(len(m.Cwd)))
 		i += copy(dAtA[i:], m.Cwd)
 	}
+	if len(m.Devices) > 0 {
+		for _, s := range m.Devices {
+			dAtA[i] = 0x22
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -1151,6 +1235,17 @@ func (m *Mount) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintOps(dAtA, i, uint64(m.Output))
 	}
+	if m.MountType != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintOps(dAtA, i, uint64(m.MountType))
+	}
+	if len(m.CacheID) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintOps(dAtA, i, uint64(len(m.CacheID)))
+		i += copy(dAtA[i:], m.CacheID)
+	}
 	return i, nil
 }
 
@@ -1360,6 +1455,12 @@ func (m *Meta) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovOps(uint64(l))
 	}
+	if len(m.Devices) > 0 {
+		for _, s := range m.Devices {
+			l = len(s)
+			n += 1 + l + sovOps(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1380,6 +1481,13 @@ func (m *Mount) Size() (n int) {
 	if m.Output != 0 {
 		n += 1 + sovOps(uint64(m.Output))
 	}
+	if m.MountType != 0 {
+		n += 1 + sovOps(uint64(m.MountType))
+	}
+	l = len(m.CacheID)
+	if l > 0 {
+		n += 1 + l + sovOps(uint64(l))
+	}
 	return n
 }
 
@@ -1506,6 +1614,7 @@ func (this *Meta) String() string {
 		`Args:` + fmt.Sprintf("%v", this.Args) + `,`,
 		`Env:` + fmt.Sprintf("%v", this.Env) + `,`,
 		`Cwd:` + fmt.Sprintf("%v", this.Cwd) + `,`,
+		`Devices:` + fmt.Sprintf("%v", this.Devices) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1519,6 +1628,8 @@ func (this *Mount) String() string {
 		`Selector:` + fmt.Sprintf("%v", this.Selector) + `,`,
 		`Dest:` + fmt.Sprintf("%v", this.Dest) + `,`,
 		`Output:` + fmt.Sprintf("%v", this.Output) + `,`,
+		`MountType:` + fmt.Sprintf("%v", this.MountType) + `,`,
+		`CacheID:` + fmt.Sprintf("%v", this.CacheID) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2068,6 +2179,35 @@ func (m *Meta) Unmarshal(dAtA []byte) error {
 			}
 			m.Cwd = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Devices", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOps
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOps
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Devices = append(m.Devices, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipOps(dAtA[iNdEx:])
@@ -2214,6 +2354,54 @@ func (m *Mount) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MountType", wireType)
+			}
+			m.MountType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOps
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MountType |= (MountType(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CacheID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOps
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOps
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CacheID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipOps(dAtA[iNdEx:])
@@ -2630,32 +2818,37 @@ var (
 func init() { proto.RegisterFile("ops.proto", fileDescriptorOps) }
 
 var fileDescriptorOps = []byte{
-	// 422 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x52, 0xb1, 0x8e, 0xd3, 0x40,
-	0x10, 0xf5, 0xda, 0x8e, 0x15, 0x4f, 0x10, 0x42, 0x2b, 0x84, 0x2c, 0x84, 0x56, 0x66, 0x0b, 0x14,
-	0x21, 0x94, 0x22, 0x88, 0x36, 0xc5, 0x21, 0x24, 0xae, 0x38, 0x9d, 0xb4, 0x7c, 0x41, 0x62, 0x2f,
-	0x27, 0x4b, 0xc4, 0xbb, 0xb2, 0xd7, 0x90, 0x74, 0x7c, 0x02, 0x2d, 0x7f, 0xc0, 0xa7, 0x50, 0xa6,
-	0xa4, 0x24, 0xa6, 0xa1, 0xcc, 0x27, 0xa0, 0x99, 0x75, 0x62, 0xda, 0xeb, 0x66, 0xde, 0x9b, 0x7d,
-	0x7e, 0x6f, 0xc6, 0x90, 0x1a, 0xdb, 0x2e, 0x6c, 0x63, 0x9c, 0xe1, 0xa1, 0xdd, 0xc8, 0xef, 0x0c,
-	0xc2, 0x5b, 0xcb, 0x9f, 0x43, 0x52, 0xd5, 0xb6, 0x73, 0x6d, 0xc6, 0xf2, 0x68, 0x3e, 0x5b, 0xa6,
-	0x0b, 0xbb, 0x59, 0x5c, 0x23, 0xa2, 0x06, 0x82, 0xe7, 0x10, 0xeb, 0x9d, 0x2e, 0xb2, 0x30, 0x67,
-	0xf3, 0xd9, 0x12, 0x70, 0xe0, 0xdd, 0x4e, 0x17, 0xb7, 0xf6, 0x7d, 0xa0, 0x88, 0xe1, 0x2f, 0x20,
-	0x69, 0x4d, 0xd7, 0x14, 0x3a, 0x8b, 0x68, 0xe6, 0x01, 0xce, 0x7c, 0x20, 0x84, 0xa6, 0x06, 0x16,
-	0x95, 0x0a, 0x63, 0xf7, 0x59, 0x3c, 0x2a, 0xbd, 0x35, 0x76, 0xef, 0x95, 0x90, 0xb9, 0x8a, 0x21,
-	0x34, 0x56, 0xbe, 0x81, 0x09, 0x59, 0xe0, 0x4f, 0x20, 0x29, 0xab, 0x3b, 0xdd, 0xba, 0x8c, 0xe5,
-	0x6c, 0x9e, 0xaa, 0xa1, 0xe3, 0x8f, 0x61, 0x52, 0xd5, 0xa5, 0xde, 0x91, 0xa7, 0x48, 0xf9, 0x46,
-	0x5e, 0x43, 0xe2, 0x8d, 0xf1, 0x67, 0x10, 0x6f, 0xb5, 0x5b, 0xd3, 0xab, 0xd9, 0x72, 0x8a, 0x1f,
-	0xba, 0xd1, 0x6e, 0xad, 0x08, 0xc5, 0xcc, 0x5b, 0xd3, 0xd5, 0xae, 0xcd, 0xc2, 0x31, 0xf3, 0x0d,
-	0x22, 0x6a, 0x20, 0xe4, 0x0a, 0x62, 0x7c, 0xc0, 0x39, 0xc4, 0xeb, 0xe6, 0xce, 0x2f, 0x27, 0x55,
-	0x54, 0xf3, 0x47, 0x10, 0xe9, 0xfa, 0x33, 0xbd, 0x4d, 0x15, 0x96, 0x88, 0x14, 0x5f, 0x4a, 0x0a,
-	0x9f, 0x2a, 0x2c, 0xa5, 0x86, 0x09, 0x09, 0x7a, 0xa7, 0xb6, 0xf3, 0x01, 0xc8, 0x29, 0xe6, 0x7a,
-	0x0a, 0xd3, 0x56, 0x7f, 0xd2, 0x85, 0x33, 0x0d, 0x45, 0x48, 0xd5, 0xa5, 0xc7, 0x4f, 0x96, 0x98,
-	0xd8, 0xab, 0x51, 0x8d, 0x7b, 0x30, 0x9d, 0x43, 0x99, 0x98, 0x64, 0x86, 0x4e, 0xae, 0x20, 0xf1,
-	0x0b, 0xe4, 0x39, 0x44, 0x6d, 0x53, 0x0c, 0x47, 0x7c, 0x78, 0xde, 0xac, 0xbf, 0x81, 0x42, 0xea,
-	0xa2, 0x1b, 0x8e, 0xba, 0x72, 0x05, 0x30, 0x8e, 0xdd, 0xdf, 0xab, 0x7c, 0x09, 0xd3, 0xf3, 0x99,
-	0xb9, 0x00, 0xa8, 0x4a, 0x5d, 0xbb, 0xea, 0x63, 0xa5, 0x9b, 0xe1, 0x5e, 0xff, 0x21, 0x57, 0xaf,
-	0x0e, 0x47, 0x11, 0xfc, 0x3a, 0x8a, 0xe0, 0x74, 0x14, 0xec, 0x6b, 0x2f, 0xd8, 0x8f, 0x5e, 0xb0,
-	0x9f, 0xbd, 0x60, 0x87, 0x5e, 0xb0, 0xdf, 0xbd, 0x60, 0x7f, 0x7b, 0x11, 0x9c, 0x7a, 0xc1, 0xbe,
-	0xfd, 0x11, 0xc1, 0x26, 0xa1, 0x3f, 0xf5, 0xf5, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa9, 0xb7,
-	0xed, 0xb5, 0xb6, 0x02, 0x00, 0x00,
+	// 499 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x53, 0xbd, 0x8e, 0xd3, 0x4c,
+	0x14, 0xf5, 0x38, 0x8e, 0xbf, 0xcc, 0xcd, 0xc7, 0x2a, 0x1a, 0x21, 0x34, 0x42, 0x68, 0x64, 0x5c,
+	0xa0, 0x68, 0x41, 0x29, 0x82, 0x68, 0x57, 0x22, 0xd9, 0x95, 0x36, 0xc5, 0xb2, 0xd2, 0x40, 0x41,
+	0x9b, 0x8c, 0x87, 0xc5, 0x12, 0x9b, 0x19, 0xd9, 0xce, 0x92, 0x74, 0x3c, 0x02, 0x2d, 0x6f, 0x40,
+	0xc1, 0x83, 0x50, 0x6e, 0x49, 0x49, 0x4c, 0x43, 0xb9, 0x8f, 0x80, 0xee, 0xb5, 0x93, 0xd0, 0xd2,
+	0xdd, 0x7b, 0xce, 0x9d, 0x33, 0xe7, 0xcc, 0x0f, 0x70, 0xe7, 0xcb, 0x91, 0x2f, 0x5c, 0xe5, 0x44,
+	0xe8, 0x17, 0xe9, 0x17, 0x06, 0xe1, 0xa5, 0x17, 0x8f, 0x21, 0xce, 0x97, 0x7e, 0x55, 0x95, 0x92,
+	0x25, 0x9d, 0x61, 0x7f, 0xcc, 0x47, 0x7e, 0x31, 0x9a, 0x21, 0xa2, 0x5b, 0x42, 0x24, 0x10, 0xd9,
+	0xb5, 0x35, 0x32, 0x4c, 0xd8, 0xb0, 0x3f, 0x06, 0x1c, 0x38, 0x5b, 0x5b, 0x73, 0xe9, 0xcf, 0x03,
+	0x4d, 0x8c, 0x78, 0x02, 0x71, 0xe9, 0x56, 0x85, 0xb1, 0xb2, 0x43, 0x33, 0xff, 0xe3, 0xcc, 0x6b,
+	0x42, 0x68, 0xaa, 0x65, 0x51, 0xc9, 0x38, 0xbf, 0x91, 0xd1, 0x41, 0x69, 0xea, 0xfc, 0xa6, 0x51,
+	0x42, 0x66, 0x12, 0x41, 0xe8, 0x7c, 0xfa, 0x02, 0xba, 0x64, 0x41, 0x3c, 0x80, 0x38, 0xcb, 0xaf,
+	0x6c, 0x59, 0x49, 0x96, 0xb0, 0x21, 0xd7, 0x6d, 0x27, 0xee, 0x43, 0x37, 0x5f, 0x66, 0x76, 0x4d,
+	0x9e, 0x3a, 0xba, 0x69, 0xd2, 0x19, 0xc4, 0x8d, 0x31, 0xf1, 0x08, 0xa2, 0x6b, 0x5b, 0xcd, 0x69,
+	0x55, 0x7f, 0xdc, 0xc3, 0x8d, 0x2e, 0x6c, 0x35, 0xd7, 0x84, 0x62, 0xe6, 0x6b, 0xb7, 0x5a, 0x56,
+	0xa5, 0x0c, 0x0f, 0x99, 0x2f, 0x10, 0xd1, 0x2d, 0x91, 0xbe, 0x85, 0x08, 0x17, 0x08, 0x01, 0xd1,
+	0xbc, 0xb8, 0x6a, 0x0e, 0x87, 0x6b, 0xaa, 0xc5, 0x00, 0x3a, 0x76, 0x79, 0x43, 0x6b, 0xb9, 0xc6,
+	0x12, 0x11, 0xf3, 0x31, 0xa3, 0xf0, 0x5c, 0x63, 0x29, 0x24, 0xfc, 0x97, 0xd9, 0x9b, 0xdc, 0xd8,
+	0x52, 0x46, 0x34, 0xb7, 0x6b, 0xd3, 0x6f, 0x0c, 0xba, 0xb4, 0x57, 0x13, 0xc2, 0xaf, 0x9a, 0x6c,
+	0x14, 0x02, 0x23, 0x3f, 0x84, 0x5e, 0x69, 0x3f, 0x58, 0x53, 0xb9, 0x82, 0xd2, 0x71, 0xbd, 0xef,
+	0xd1, 0x4d, 0x86, 0x87, 0xd1, 0x6c, 0x44, 0x35, 0x1e, 0x91, 0x5b, 0x55, 0x28, 0x13, 0x91, 0x4c,
+	0xdb, 0x89, 0xa7, 0xc0, 0x29, 0xcb, 0x9b, 0x8d, 0xb7, 0xb2, 0x9b, 0xb0, 0xe1, 0xd1, 0xf8, 0xde,
+	0x3e, 0x27, 0x82, 0xfa, 0xc0, 0xa3, 0x5d, 0x33, 0x37, 0xef, 0xed, 0xec, 0x54, 0xc6, 0xa4, 0xbd,
+	0x6b, 0xd3, 0x13, 0x88, 0x9b, 0x2b, 0x12, 0x09, 0x74, 0xca, 0xc2, 0xb4, 0xcf, 0xe4, 0x68, 0x77,
+	0x77, 0xcd, 0x2d, 0x6b, 0xa4, 0xf6, 0xf6, 0xc2, 0x83, 0xbd, 0xf4, 0x04, 0xe0, 0x30, 0xf6, 0xef,
+	0x91, 0xd3, 0x63, 0xe8, 0xed, 0x1e, 0x92, 0x50, 0x00, 0x79, 0x66, 0x97, 0x55, 0xfe, 0x2e, 0xb7,
+	0x45, 0xfb, 0x22, 0xfe, 0x42, 0x8e, 0x13, 0xe0, 0xfb, 0x74, 0xa2, 0x07, 0xd1, 0x64, 0xf6, 0xea,
+	0x74, 0x10, 0x08, 0x0e, 0xdd, 0xe9, 0xcb, 0xe9, 0xf9, 0xd9, 0x80, 0x4d, 0x9e, 0xdd, 0x6e, 0x55,
+	0xf0, 0x63, 0xab, 0x82, 0xbb, 0xad, 0x62, 0x9f, 0x6a, 0xc5, 0xbe, 0xd6, 0x8a, 0x7d, 0xaf, 0x15,
+	0xbb, 0xad, 0x15, 0xfb, 0x59, 0x2b, 0xf6, 0xbb, 0x56, 0xc1, 0x5d, 0xad, 0xd8, 0xe7, 0x5f, 0x2a,
+	0x58, 0xc4, 0xf4, 0x5b, 0x9e, 0xff, 0x09, 0x00, 0x00, 0xff, 0xff, 0xe1, 0x28, 0xf6, 0xd7, 0x3a,
+	0x03, 0x00, 0x00,
 }
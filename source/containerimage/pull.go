@@ -17,11 +17,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tonistiigi/buildkit_poc/cache"
 	"github.com/tonistiigi/buildkit_poc/source"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
 )
 
 // TODO: break apart containerd specifics like contentstore so the resolver
 // code can be used with any implementation
 
+// layers bigger than this are reported with a warning
+const largeLayerSize = 1 << 30
+
 type SourceOpt struct {
 	Snapshotter   snapshot.Snapshotter
 	ContentStore  content.Store
@@ -32,6 +36,8 @@ type SourceOpt struct {
 type blobmapper interface {
 	GetBlob(ctx context.Context, key string) (digest.Digest, error)
 	SetBlob(ctx context.Context, key string, blob digest.Digest) error
+	GetConversion(ctx context.Context, blob digest.Digest, mediaType string) (digest.Digest, error)
+	SetConversion(ctx context.Context, blob digest.Digest, mediaType string, converted digest.Digest) error
 }
 
 type imageSource struct {
@@ -67,6 +73,10 @@ func (is *imageSource) Pull(ctx context.Context, id source.Identifier) (cache.Im
 		return nil, errors.New("invalid identifier")
 	}
 
+	if imageIdentifier.Reference.Digest() == "" {
+		warnings.Warn(ctx, warnings.SeverityWarning, "%s is not pinned to a digest", imageIdentifier.Reference.String())
+	}
+
 	ref, desc, err := is.resolver.Resolve(ctx, imageIdentifier.Reference.String())
 	if err != nil {
 		return nil, err
@@ -76,6 +86,14 @@ func (is *imageSource) Pull(ctx context.Context, id source.Identifier) (cache.Im
 		return nil, err
 	}
 
+	// the converted manifest and config are only in the content store, the
+	// fetch below skips them
+	if isSchema1(desc.MediaType) {
+		if desc, err = is.convertSchema1(ctx, fetcher, desc); err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: need a wrapper snapshot interface that combines content
 	// and snapshots as 1) buildkit shouldn't have a dependency on contentstore
 	// or 2) cachemanager should manage the contentstore
@@ -95,12 +113,48 @@ func (is *imageSource) Pull(ctx context.Context, id source.Identifier) (cache.Im
 	return is.CacheAccessor.Get(chainid)
 }
 
+// convertSchema1 converts a schema1 image unless it was converted before.
+// A new conversion fetches all layers to calculate their diffIDs.
+func (is *imageSource) convertSchema1(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	bm := is.Snapshotter.(blobmapper)
+	dgst, err := bm.GetConversion(ctx, desc.Digest, images.MediaTypeDockerSchema2Manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if dgst != "" {
+		info, err := is.ContentStore.Info(ctx, dgst)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		return ocispec.Descriptor{
+			MediaType: images.MediaTypeDockerSchema2Manifest,
+			Digest:    info.Digest,
+			Size:      info.Size,
+		}, nil
+	}
+
+	converted, err := convertSchema1(ctx, is.ContentStore, fetcher, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to convert schema1 image %s", desc.Digest)
+	}
+	if err := bm.SetConversion(ctx, desc.Digest, images.MediaTypeDockerSchema2Manifest, converted.Digest); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return converted, nil
+}
+
 func (is *imageSource) unpack(ctx context.Context, desc ocispec.Descriptor) (string, error) {
 	layers, err := getLayers(ctx, is.ContentStore, desc)
 	if err != nil {
 		return "", err
 	}
 
+	for _, l := range layers {
+		if l.Blob.Size > largeLayerSize {
+			warnings.Warn(ctx, warnings.SeverityWarning, "layer %s is %d MB", l.Blob.Digest, l.Blob.Size>>20)
+		}
+	}
+
 	chainID, err := rootfs.ApplyLayers(ctx, layers, is.Snapshotter, is.Applier)
 	if err != nil {
 		return "", err
@@ -0,0 +1,253 @@
+package containerimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/archive/compression"
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	mediaTypeDockerSchema1Manifest       = "application/vnd.docker.distribution.manifest.v1+json"
+	mediaTypeDockerSchema1SignedManifest = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+)
+
+// emptyGzipLayer is the gzipped empty tar that schema1 images use for
+// commands that don't change the filesystem
+const emptyGzipLayer = digest.Digest("sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4")
+
+func isSchema1(mediaType string) bool {
+	return mediaType == mediaTypeDockerSchema1Manifest || mediaType == mediaTypeDockerSchema1SignedManifest
+}
+
+type schema1Manifest struct {
+	SchemaVersion int `json:"schemaVersion"`
+	FSLayers      []struct {
+		BlobSum digest.Digest `json:"blobSum"`
+	} `json:"fsLayers"`
+	History []struct {
+		V1Compatibility string `json:"v1Compatibility"`
+	} `json:"history"`
+}
+
+type v1Compatibility struct {
+	Created         *time.Time `json:"created,omitempty"`
+	Author          string     `json:"author,omitempty"`
+	Comment         string     `json:"comment,omitempty"`
+	ThrowAway       bool       `json:"throwaway,omitempty"`
+	ContainerConfig struct {
+		Cmd []string `json:"Cmd"`
+	} `json:"container_config"`
+}
+
+// v1 image fields that are not part of an image config
+var v1OnlyFields = []string{"id", "parent", "parent_id", "layer_id", "Size", "throwaway"}
+
+// convertSchema1 fetches a schema1 manifest and its layers and stores an
+// equivalent schema2 manifest and config in the content store. The config is
+// rebuilt from the v1 history of the image and empty layers are dropped.
+// Signatures are stripped but not verified, the manifest is only checked
+// against its digest.
+func convertSchema1(ctx context.Context, cs content.Store, fetcher remotes.Fetcher, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	// the fetcher only uses the manifests endpoint for known manifest types
+	rc, err := fetcher.Fetch(ctx, ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2Manifest,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to fetch schema1 manifest")
+	}
+	dt, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to read schema1 manifest")
+	}
+	payload, err := stripSignatures(dt)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if dgst := desc.Digest.Algorithm().FromBytes(payload); dgst != desc.Digest {
+		return ocispec.Descriptor{}, errors.Errorf("schema1 manifest digest mismatch: %s != %s", dgst, desc.Digest)
+	}
+
+	var m schema1Manifest
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to parse schema1 manifest")
+	}
+	if m.SchemaVersion != 1 {
+		return ocispec.Descriptor{}, errors.Errorf("invalid schema1 manifest version %d", m.SchemaVersion)
+	}
+	if len(m.FSLayers) == 0 || len(m.FSLayers) != len(m.History) {
+		return ocispec.Descriptor{}, errors.New("mismatched schema1 layers and history")
+	}
+
+	// schema1 lists layers and history from top to bottom
+	var (
+		layers  []ocispec.Descriptor
+		diffIDs []digest.Digest
+		history []ocispec.History
+	)
+	for i := len(m.History) - 1; i >= 0; i-- {
+		var v1 v1Compatibility
+		if err := json.Unmarshal([]byte(m.History[i].V1Compatibility), &v1); err != nil {
+			return ocispec.Descriptor{}, errors.Wrap(err, "failed to parse schema1 history")
+		}
+		blob := m.FSLayers[i].BlobSum
+		empty := v1.ThrowAway || blob == emptyGzipLayer
+		history = append(history, ocispec.History{
+			Created:    v1.Created,
+			CreatedBy:  strings.Join(v1.ContainerConfig.Cmd, " "),
+			Author:     v1.Author,
+			Comment:    v1.Comment,
+			EmptyLayer: empty,
+		})
+		if empty {
+			continue
+		}
+
+		layer, diffID, err := fetchSchema1Layer(ctx, cs, fetcher, blob)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		layers = append(layers, layer)
+		diffIDs = append(diffIDs, diffID)
+	}
+
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(m.History[0].V1Compatibility), &config); err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to parse schema1 history")
+	}
+	for _, k := range v1OnlyFields {
+		delete(config, k)
+	}
+	if config["rootfs"], err = json.Marshal(ocispec.RootFS{Type: "layers", DiffIDs: diffIDs}); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if config["history"], err = json.Marshal(history); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	configDesc, err := writeJSON(ctx, cs, images.MediaTypeDockerSchema2Config, config)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	return writeJSON(ctx, cs, images.MediaTypeDockerSchema2Manifest, struct {
+		specs.Versioned
+		MediaType string               `json:"mediaType"`
+		Config    ocispec.Descriptor   `json:"config"`
+		Layers    []ocispec.Descriptor `json:"layers"`
+	}{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: images.MediaTypeDockerSchema2Manifest,
+		Config:    configDesc,
+		Layers:    layers,
+	})
+}
+
+// fetchSchema1Layer fetches a layer blob and calculates its diffID.
+// Schema1 manifests contain neither.
+func fetchSchema1Layer(ctx context.Context, cs content.Store, fetcher remotes.Fetcher, blob digest.Digest) (ocispec.Descriptor, digest.Digest, error) {
+	desc := ocispec.Descriptor{
+		MediaType: images.MediaTypeDockerSchema2LayerGzip,
+		Digest:    blob,
+	}
+	if _, err := remotes.FetchHandler(cs, fetcher)(ctx, desc); err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrapf(err, "failed to fetch layer %s", blob)
+	}
+	info, err := cs.Info(ctx, blob)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	desc.Size = info.Size
+
+	rc, err := cs.Reader(ctx, blob)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer rc.Close()
+	ds, err := compression.DecompressStream(rc)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer ds.Close()
+	digester := digest.Canonical.Digester()
+	if _, err := io.Copy(digester.Hash(), ds); err != nil {
+		return ocispec.Descriptor{}, "", errors.Wrapf(err, "failed to read layer %s", blob)
+	}
+	return desc, digester.Digest(), nil
+}
+
+// stripSignatures returns the payload of a signed schema1 manifest. The
+// protected header of a signature records how the payload was formatted
+// before the signatures were added.
+func stripSignatures(dt []byte) ([]byte, error) {
+	var sm struct {
+		Signatures []struct {
+			Protected string `json:"protected"`
+		} `json:"signatures"`
+	}
+	if err := json.Unmarshal(dt, &sm); err != nil {
+		return nil, errors.Wrap(err, "failed to parse schema1 manifest")
+	}
+	if len(sm.Signatures) == 0 {
+		return dt, nil
+	}
+	protected, err := decodeBase64URL(sm.Signatures[0].Protected)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid schema1 signature")
+	}
+	var header struct {
+		FormatLength int    `json:"formatLength"`
+		FormatTail   string `json:"formatTail"`
+	}
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return nil, errors.Wrap(err, "invalid schema1 signature")
+	}
+	tail, err := decodeBase64URL(header.FormatTail)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid schema1 signature")
+	}
+	if header.FormatLength < 0 || header.FormatLength > len(dt) {
+		return nil, errors.Errorf("invalid schema1 format length %d", header.FormatLength)
+	}
+	payload := make([]byte, 0, header.FormatLength+len(tail))
+	payload = append(payload, dt[:header.FormatLength]...)
+	return append(payload, tail...), nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func writeJSON(ctx context.Context, cs content.Store, mediaType string, v interface{}) (ocispec.Descriptor, error) {
+	dt, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	if err := content.WriteBlob(ctx, cs, "schema1-"+desc.Digest.String(), bytes.NewReader(dt), desc.Size, desc.Digest); err != nil {
+		return ocispec.Descriptor{}, errors.Wrap(err, "failed to write converted schema1 image")
+	}
+	return desc, nil
+}
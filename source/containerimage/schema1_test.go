@@ -0,0 +1,155 @@
+package containerimage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertSchema1(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "schema1")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(tmpdir)
+	assert.NoError(t, err)
+
+	f := fetcher{}
+	base, baseDiffID := f.layer("base")
+	app, appDiffID := f.layer("app")
+
+	// top to bottom
+	payload, err := json.MarshalIndent(map[string]interface{}{
+		"schemaVersion": 1,
+		"name":          "library/test",
+		"tag":           "latest",
+		"architecture":  "amd64",
+		"fsLayers": []map[string]interface{}{
+			{"blobSum": emptyGzipLayer},
+			{"blobSum": app},
+			{"blobSum": digest.FromString("not fetched")},
+			{"blobSum": base},
+		},
+		"history": []map[string]interface{}{
+			v1History(t, `{"id":"4","parent":"3","architecture":"amd64","os":"linux","config":{"Env":["FOO=bar"],"Cmd":["sh"]},"container_config":{"Cmd":["/bin/sh","-c","#(nop) CMD [\"sh\"]"]}}`),
+			v1History(t, `{"id":"3","parent":"2","container_config":{"Cmd":["/bin/sh","-c","touch app"]},"author":"me"}`),
+			v1History(t, `{"id":"2","parent":"1","throwaway":true,"container_config":{"Cmd":["/bin/sh","-c","#(nop) ENV FOO=bar"]}}`),
+			v1History(t, `{"id":"1","container_config":{"Cmd":["/bin/sh","-c","#(nop) ADD file:base in /"]}}`),
+		},
+	}, "", "   ")
+	assert.NoError(t, err)
+	signed := sign(t, payload)
+	manifest := digest.FromBytes(payload)
+	f[manifest] = signed
+
+	desc, err := convertSchema1(ctx, cs, f, ocispec.Descriptor{MediaType: mediaTypeDockerSchema1SignedManifest, Digest: manifest, Size: int64(len(signed))})
+	assert.NoError(t, err)
+	assert.Equal(t, images.MediaTypeDockerSchema2Manifest, desc.MediaType)
+
+	layers, err := getLayers(ctx, cs, desc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(layers))
+	assert.Equal(t, base, layers[0].Blob.Digest)
+	assert.Equal(t, baseDiffID, layers[0].Diff.Digest)
+	assert.Equal(t, app, layers[1].Blob.Digest)
+	assert.Equal(t, appDiffID, layers[1].Diff.Digest)
+	assert.Equal(t, int64(len(f[app])), layers[1].Blob.Size)
+
+	configDesc, err := (&images.Image{Target: desc}).Config(ctx, cs)
+	assert.NoError(t, err)
+	dt, err := content.ReadBlob(ctx, cs, configDesc.Digest)
+	assert.NoError(t, err)
+	var raw map[string]interface{}
+	assert.NoError(t, json.Unmarshal(dt, &raw))
+	assert.Nil(t, raw["id"])
+	assert.Nil(t, raw["parent"])
+	var config ocispec.Image
+	assert.NoError(t, json.Unmarshal(dt, &config))
+	assert.Equal(t, "linux", config.OS)
+	assert.Equal(t, []string{"FOO=bar"}, config.Config.Env)
+	assert.Equal(t, 4, len(config.History))
+	assert.Equal(t, "/bin/sh -c #(nop) ADD file:base in /", config.History[0].CreatedBy)
+	assert.True(t, config.History[1].EmptyLayer)
+	assert.Equal(t, "me", config.History[2].Author)
+	assert.False(t, config.History[2].EmptyLayer)
+	assert.True(t, config.History[3].EmptyLayer)
+
+	// the signed bytes don't match the digest
+	_, err = convertSchema1(ctx, cs, f, ocispec.Descriptor{MediaType: mediaTypeDockerSchema1SignedManifest, Digest: digest.FromBytes(signed)})
+	assert.Error(t, err)
+}
+
+func TestStripSignatures(t *testing.T) {
+	payload := []byte("{\n   \"schemaVersion\": 1\n}")
+	dt, err := stripSignatures(sign(t, payload))
+	assert.NoError(t, err)
+	assert.Equal(t, string(payload), string(dt))
+
+	// unsigned manifests are kept
+	dt, err = stripSignatures(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, string(payload), string(dt))
+}
+
+func v1History(t *testing.T, v1 string) map[string]interface{} {
+	assert.True(t, json.Valid([]byte(v1)))
+	return map[string]interface{}{"v1Compatibility": v1}
+}
+
+// sign adds a signature block the way libtrust does: the payload is cut
+// before its closing brace and the cut off tail is recorded in the
+// protected header
+func sign(t *testing.T, payload []byte) []byte {
+	i := bytes.LastIndex(payload, []byte("\n}"))
+	assert.True(t, i > 0)
+	protected, err := json.Marshal(map[string]interface{}{
+		"formatLength": i,
+		"formatTail":   base64.RawURLEncoding.EncodeToString(payload[i:]),
+	})
+	assert.NoError(t, err)
+	dt := append([]byte{}, payload[:i]...)
+	dt = append(dt, []byte(`,
+   "signatures": [
+      {
+         "header": {"alg": "ES256"},
+         "signature": "c2lnbmF0dXJl",
+         "protected": "`+base64.URLEncoding.EncodeToString(protected)+`"
+      }
+   ]`)...)
+	return append(dt, payload[i:]...)
+}
+
+type fetcher map[digest.Digest][]byte
+
+func (f fetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	dt, ok := f[desc.Digest]
+	if !ok {
+		return nil, errors.Errorf("%s not found", desc.Digest)
+	}
+	return ioutil.NopCloser(bytes.NewReader(dt)), nil
+}
+
+// layer adds a gzipped layer blob and returns its digest and diffID
+func (f fetcher) layer(s string) (digest.Digest, digest.Digest) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	dgst := digest.FromBytes(buf.Bytes())
+	f[dgst] = buf.Bytes()
+	return dgst, digest.FromString(s)
+}
@@ -14,6 +14,7 @@ var (
 
 const (
 	DockerImageScheme = "docker-image"
+	LocalScheme       = "local"
 )
 
 type Identifier interface {
@@ -30,6 +31,8 @@ func FromString(s string) (Identifier, error) {
 	switch parts[0] {
 	case DockerImageScheme:
 		return NewImageIdentifier(parts[1])
+	case LocalScheme:
+		return NewLocalIdentifier(parts[1])
 	default:
 		return nil, errors.Wrapf(errNotFound, "unknown schema %s", parts[0])
 	}
@@ -54,3 +57,18 @@ func NewImageIdentifier(str string) (*ImageIdentifier, error) {
 func (i *ImageIdentifier) ID() string {
 	return DockerImageScheme
 }
+
+type LocalIdentifier struct {
+	Name string
+}
+
+func NewLocalIdentifier(str string) (*LocalIdentifier, error) {
+	if str == "" {
+		return nil, errors.Wrapf(errInvalid, "empty local name")
+	}
+	return &LocalIdentifier{Name: str}, nil
+}
+
+func (*LocalIdentifier) ID() string {
+	return LocalScheme
+}
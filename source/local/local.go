@@ -0,0 +1,309 @@
+package local
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/archive/compression"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/source"
+	"github.com/tonistiigi/buildkit_poc/util/sparse"
+)
+
+type solveRefKey struct{}
+
+// WithSolveRef returns a context for pulling the contexts uploaded for a
+// solve request
+func WithSolveRef(ctx context.Context, ref string) context.Context {
+	return context.WithValue(ctx, solveRefKey{}, ref)
+}
+
+type SourceOpt struct {
+	CacheAccessor cache.Accessor
+	// UploadTimeout is how long uploads are kept for a solve request that
+	// hasn't started. DefaultUploadTimeout is used if zero.
+	UploadTimeout time.Duration
+}
+
+// DefaultUploadTimeout is the default time uploads wait for their solve
+const DefaultUploadTimeout = 5 * time.Minute
+
+// Source provides build contexts uploaded by the client as tar archives.
+// Uploads are scoped to a solve request and looked up by name. A solve
+// request belongs to the client that first uploads to or claims it.
+type Source struct {
+	SourceOpt
+	mu       sync.Mutex
+	uploads  map[string]*upload               // solve ref
+	contents map[digest.Digest]*contentRecord // tar digest
+}
+
+type upload struct {
+	owner   string
+	refs    map[string]cache.ImmutableRef // name
+	digests map[string]digest.Digest      // name
+	claimed bool
+	timer   *time.Timer
+}
+
+// contentRecord is the snapshot shared by the uploads of the same archive
+type contentRecord struct {
+	id    string
+	count int
+}
+
+func NewSource(opt SourceOpt) (*Source, error) {
+	if opt.UploadTimeout == 0 {
+		opt.UploadTimeout = DefaultUploadTimeout
+	}
+	return &Source{
+		SourceOpt: opt,
+		uploads:   make(map[string]*upload),
+		contents:  make(map[digest.Digest]*contentRecord),
+	}, nil
+}
+
+func (ls *Source) ID() string {
+	return source.LocalScheme
+}
+
+// Upload unpacks a tar archive, optionally compressed, into a new snapshot
+// and makes it available for the solve request. Returned digest is of the
+// uncompressed archive and archives with same content share a snapshot.
+// Uploads that no solve claims before the upload timeout are released.
+func (ls *Source) Upload(ctx context.Context, solveRef, owner, name string, r io.Reader) (digest.Digest, error) {
+	if solveRef == "" || name == "" {
+		return "", errors.New("upload requires a solve ref and a name")
+	}
+
+	ls.mu.Lock()
+	err := ls.checkUpload(solveRef, owner)
+	ls.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	dgst, active, err := ls.unpack(ctx, r)
+	if err != nil {
+		return "", err
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.checkUpload(solveRef, owner); err != nil {
+		active.Discard(ctx)
+		return "", err
+	}
+
+	ref, err := ls.commit(ctx, dgst, active)
+	if err != nil {
+		return "", err
+	}
+	c, ok := ls.contents[dgst]
+	if !ok || c.id != ref.ID() {
+		c = &contentRecord{id: ref.ID()}
+		ls.contents[dgst] = c
+	}
+	c.count++
+
+	u, ok := ls.uploads[solveRef]
+	if !ok {
+		u = &upload{
+			owner:   owner,
+			refs:    make(map[string]cache.ImmutableRef),
+			digests: make(map[string]digest.Digest),
+		}
+		u.timer = time.AfterFunc(ls.UploadTimeout, func() {
+			ls.expire(solveRef, u)
+		})
+		ls.uploads[solveRef] = u
+	}
+	if prev, ok := u.refs[name]; ok {
+		ls.releaseRef(prev, u.digests[name])
+	}
+	u.refs[name] = ref
+	u.digests[name] = dgst
+	return dgst, nil
+}
+
+// commit returns the snapshot for unpacked content. Content that was
+// already uploaded reuses the earlier snapshot and the new one is removed.
+// Hold the lock before calling.
+func (ls *Source) commit(ctx context.Context, dgst digest.Digest, active cache.MutableRef) (cache.ImmutableRef, error) {
+	if c, ok := ls.contents[dgst]; ok {
+		if existing, err := ls.CacheAccessor.Get(c.id); err == nil {
+			if err := active.Discard(ctx); err != nil {
+				existing.Release()
+				return nil, err
+			}
+			return existing, nil
+		}
+	}
+	return active.ReleaseAndCommit(ctx)
+}
+
+// checkUpload returns an error if owner can't upload to the solve request.
+// Hold the lock before calling.
+func (ls *Source) checkUpload(solveRef, owner string) error {
+	u, ok := ls.uploads[solveRef]
+	if !ok {
+		return nil
+	}
+	if u.owner != owner {
+		return errors.Errorf("solve %s belongs to another client", solveRef)
+	}
+	if u.claimed {
+		return errors.Errorf("solve %s has already started", solveRef)
+	}
+	return nil
+}
+
+// Claim marks the uploads of a solve request as used by a solve so they
+// don't expire. Release must be called when the solve finishes.
+func (ls *Source) Claim(solveRef, owner string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	u, ok := ls.uploads[solveRef]
+	if !ok {
+		// later uploads to the ref are rejected
+		ls.uploads[solveRef] = &upload{owner: owner, claimed: true}
+		return nil
+	}
+	if u.owner != owner {
+		return errors.Errorf("solve %s belongs to another client", solveRef)
+	}
+	if u.claimed {
+		return errors.Errorf("solve %s has already started", solveRef)
+	}
+	u.claimed = true
+	u.timer.Stop()
+	return nil
+}
+
+// HasUploads returns true if contexts were uploaded for a solve request
+func (ls *Source) HasUploads(solveRef string) bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	u, ok := ls.uploads[solveRef]
+	return ok && len(u.refs) > 0
+}
+
+func (ls *Source) expire(solveRef string, u *upload) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.uploads[solveRef] != u || u.claimed {
+		return
+	}
+	ls.releaseUpload(u)
+	delete(ls.uploads, solveRef)
+}
+
+// releaseRef releases an uploaded ref and forgets its content when no other
+// upload uses it. Hold the lock before calling.
+func (ls *Source) releaseRef(ref cache.ImmutableRef, dgst digest.Digest) error {
+	if c, ok := ls.contents[dgst]; ok && c.id == ref.ID() {
+		if c.count--; c.count == 0 {
+			delete(ls.contents, dgst)
+		}
+	}
+	return ref.Release()
+}
+
+// releaseUpload releases all refs of an upload. Hold the lock before
+// calling.
+func (ls *Source) releaseUpload(u *upload) error {
+	var retErr error
+	for name, ref := range u.refs {
+		if err := ls.releaseRef(ref, u.digests[name]); err != nil {
+			retErr = err
+		}
+	}
+	return retErr
+}
+
+// unpack extracts an archive into a new snapshot and returns the digest of
+// the uncompressed archive. The snapshot is left uncommitted.
+func (ls *Source) unpack(ctx context.Context, r io.Reader) (_ digest.Digest, _ cache.MutableRef, retErr error) {
+	active, err := ls.CacheAccessor.New(nil)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			active.Discard(context.TODO()) // TODO: log error
+		}
+	}()
+
+	m, err := active.Mount()
+	if err != nil {
+		return "", nil, err
+	}
+	lm := snapshot.LocalMounter(m)
+	dir, err := lm.Mount()
+	if err != nil {
+		return "", nil, err
+	}
+	defer lm.Unmount()
+
+	ds, err := compression.DecompressStream(r)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to detect compression")
+	}
+	defer ds.Close()
+
+	digester := digest.Canonical.Digester()
+	tr := io.TeeReader(ds, digester.Hash())
+	if _, err := sparse.Apply(ctx, dir, tr); err != nil {
+		return "", nil, errors.Wrap(err, "failed to unpack context")
+	}
+	// read any trailing data
+	if _, err := io.Copy(ioutil.Discard, tr); err != nil {
+		return "", nil, err
+	}
+
+	if err := lm.Unmount(); err != nil {
+		return "", nil, err
+	}
+	return digester.Digest(), active, nil
+}
+
+func (ls *Source) Pull(ctx context.Context, id source.Identifier) (cache.ImmutableRef, error) {
+	localIdentifier, ok := id.(*source.LocalIdentifier)
+	if !ok {
+		return nil, errors.New("invalid identifier")
+	}
+	solveRef, _ := ctx.Value(solveRefKey{}).(string)
+
+	ls.mu.Lock()
+	var ref cache.ImmutableRef
+	if u, ok := ls.uploads[solveRef]; ok {
+		ref = u.refs[localIdentifier.Name]
+	}
+	ls.mu.Unlock()
+	if ref == nil {
+		return nil, errors.Errorf("no context named %s was uploaded", localIdentifier.Name)
+	}
+	return ls.CacheAccessor.Get(ref.ID())
+}
+
+// Release releases the contexts uploaded for a solve request
+func (ls *Source) Release(solveRef string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	u, ok := ls.uploads[solveRef]
+	if !ok {
+		return nil
+	}
+	if u.timer != nil {
+		u.timer.Stop()
+	}
+	delete(ls.uploads, solveRef)
+	return ls.releaseUpload(u)
+}
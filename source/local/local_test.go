@@ -0,0 +1,150 @@
+package local
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cdsnapshot "github.com/containerd/containerd/snapshot"
+	"github.com/containerd/containerd/snapshot/naive"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/snapshot"
+	"github.com/tonistiigi/buildkit_poc/source"
+)
+
+func TestUpload(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	ctx := context.TODO()
+
+	tmpdir, err := ioutil.TempDir("", "localsource")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	snapshotter, err := naive.NewSnapshotter(filepath.Join(tmpdir, "snapshots"))
+	assert.NoError(t, err)
+
+	cm, err := cache.NewManager(cache.ManagerOpt{
+		Root:        tmpdir,
+		Snapshotter: snapshotter,
+	})
+	assert.NoError(t, err)
+
+	ls, err := NewSource(SourceOpt{CacheAccessor: cm, UploadTimeout: 100 * time.Millisecond})
+	assert.NoError(t, err)
+
+	dt := testTar(t, map[string]string{"foo": "foo", "dir/bar": "bar"})
+	dgst, err := ls.Upload(ctx, "solve1", "client1", "context", bytes.NewReader(dt))
+	assert.NoError(t, err)
+
+	// compressed archive is detected and gets the same content digest
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	_, err = gw.Write(dt)
+	assert.NoError(t, err)
+	assert.NoError(t, gw.Close())
+	dgst2, err := ls.Upload(ctx, "solve2", "client1", "context", buf)
+	assert.NoError(t, err)
+	assert.Equal(t, dgst, dgst2)
+	assert.Equal(t, 2, ls.contents[dgst].count)
+	// the duplicate snapshot is removed
+	assert.Equal(t, 1, countSnapshots(t, snapshotter))
+
+	// other clients can't use the solve ref
+	_, err = ls.Upload(ctx, "solve1", "client2", "other", bytes.NewReader(dt))
+	assert.Error(t, err)
+	assert.Error(t, ls.Claim("solve1", "client2"))
+	assert.NoError(t, ls.Claim("solve1", "client1"))
+	assert.NoError(t, ls.Claim("solve2", "client1"))
+	assert.True(t, ls.HasUploads("solve1"))
+	// claiming a ref without uploads doesn't add any
+	assert.NoError(t, ls.Claim("solve4", "client1"))
+	assert.False(t, ls.HasUploads("solve4"))
+	assert.NoError(t, ls.Release("solve4"))
+	// uploads after the solve started are rejected
+	_, err = ls.Upload(ctx, "solve1", "client1", "other", bytes.NewReader(dt))
+	assert.Error(t, err)
+
+	id, err := source.FromString("local://context")
+	assert.NoError(t, err)
+
+	_, err = ls.Pull(ctx, id)
+	assert.Error(t, err)
+
+	ref, err := ls.Pull(WithSolveRef(ctx, "solve1"), id)
+	assert.NoError(t, err)
+	ref2, err := ls.Pull(WithSolveRef(ctx, "solve2"), id)
+	assert.NoError(t, err)
+	assert.Equal(t, ref.ID(), ref2.ID())
+
+	m, err := ref.Mount()
+	assert.NoError(t, err)
+	lm := snapshot.LocalMounter(m)
+	dir, err := lm.Mount()
+	assert.NoError(t, err)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "dir/bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", string(b))
+	assert.NoError(t, lm.Unmount())
+
+	assert.NoError(t, ref.Release())
+	assert.NoError(t, ref2.Release())
+
+	assert.NoError(t, ls.Release("solve1"))
+	_, err = ls.Pull(WithSolveRef(ctx, "solve1"), id)
+	assert.Error(t, err)
+	assert.Equal(t, 1, ls.contents[dgst].count)
+	assert.NoError(t, ls.Release("solve2"))
+	assert.Equal(t, 0, len(ls.contents))
+
+	_, err = ls.Upload(ctx, "solve1", "client1", "context", bytes.NewReader([]byte("invalid")))
+	assert.Error(t, err)
+	assert.Equal(t, 1, countSnapshots(t, snapshotter))
+
+	// uploads that no solve claims expire
+	_, err = ls.Upload(ctx, "solve3", "client1", "context", bytes.NewReader(dt))
+	assert.NoError(t, err)
+	time.Sleep(300 * time.Millisecond)
+	ls.mu.Lock()
+	assert.Equal(t, 0, len(ls.uploads))
+	assert.Equal(t, 0, len(ls.contents))
+	ls.mu.Unlock()
+	// the ref can be used by another client after expiring
+	assert.NoError(t, ls.Claim("solve3", "client2"))
+	assert.NoError(t, ls.Release("solve3"))
+}
+
+func countSnapshots(t *testing.T, sn cdsnapshot.Snapshotter) int {
+	var n int
+	err := sn.Walk(context.TODO(), func(context.Context, cdsnapshot.Info) error {
+		n++
+		return nil
+	})
+	assert.NoError(t, err)
+	return n
+}
+
+func testTar(t *testing.T, files map[string]string) []byte {
+	buf := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(buf)
+	for name, dt := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(dt)),
+		})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(dt))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
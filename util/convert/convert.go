@@ -0,0 +1,431 @@
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	"github.com/klauspost/compress/zstd"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/util/pgzip"
+)
+
+// MediaTypeImageLayerZstd is the OCI media type of zstd compressed layers.
+// Docker media types have no zstd variant.
+const MediaTypeImageLayerZstd = "application/vnd.oci.image.layer.v1.tar+zstd"
+
+type Compression int
+
+const (
+	Uncompressed Compression = iota
+	Gzip
+	Zstd
+)
+
+func ParseCompression(s string) (Compression, error) {
+	switch s {
+	case "uncompressed":
+		return Uncompressed, nil
+	case "gzip":
+		return Gzip, nil
+	case "zstd":
+		return Zstd, nil
+	default:
+		return 0, errors.Errorf("invalid compression %q, must be uncompressed, gzip or zstd", s)
+	}
+}
+
+func (c Compression) String() string {
+	switch c {
+	case Uncompressed:
+		return "uncompressed"
+	case Gzip:
+		return "gzip"
+	case Zstd:
+		return "zstd"
+	default:
+		return "unknown"
+	}
+}
+
+// BlobMapper remembers converted layer blobs so that they are only
+// converted once
+type BlobMapper interface {
+	GetConversion(ctx context.Context, blob digest.Digest, mediaType string) (digest.Digest, error)
+	SetConversion(ctx context.Context, blob digest.Digest, mediaType string, converted digest.Digest) error
+}
+
+type Opt struct {
+	ContentStore content.Store
+	BlobMapper   BlobMapper
+}
+
+// Target is the format an image is converted to
+type Target struct {
+	// OCI selects OCI media types instead of Docker v2 ones
+	OCI         bool
+	Compression Compression
+}
+
+// Converter rewrites images with different media types and layer
+// compression. Layers are decompressed and compressed again so the
+// uncompressed tar streams, and with them the image config, stay the same.
+type Converter struct {
+	opt Opt
+}
+
+func New(opt Opt) *Converter {
+	return &Converter{opt: opt}
+}
+
+// Convert converts the image with the manifest or index desc and returns
+// the descriptor of the new manifest or index. Every manifest of an index is
+// converted. All blobs of the image need to be in the content store.
+func (c *Converter) Convert(ctx context.Context, desc ocispec.Descriptor, t Target) (ocispec.Descriptor, error) {
+	if !t.OCI && t.Compression == Zstd {
+		return ocispec.Descriptor{}, errors.New("zstd compression requires OCI media types")
+	}
+	switch desc.MediaType {
+	case images.MediaTypeDockerSchema2Manifest, ocispec.MediaTypeImageManifest:
+		return c.convertManifest(ctx, desc, t)
+	case images.MediaTypeDockerSchema2ManifestList, ocispec.MediaTypeImageIndex:
+		return c.convertIndex(ctx, desc, t)
+	default:
+		return ocispec.Descriptor{}, errors.Errorf("unsupported manifest type %s", desc.MediaType)
+	}
+}
+
+func (c *Converter) convertIndex(ctx context.Context, desc ocispec.Descriptor, t Target) (ocispec.Descriptor, error) {
+	dt, err := content.ReadBlob(ctx, c.opt.ContentStore, desc.Digest)
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to read index %s", desc.Digest)
+	}
+	var idx ocispec.Index
+	if err := json.Unmarshal(dt, &idx); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to parse index %s", desc.Digest)
+	}
+	// unknown fields of the index are kept
+	var raw map[string]interface{}
+	if err := json.Unmarshal(dt, &raw); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to parse index %s", desc.Digest)
+	}
+
+	manifests := make([]ocispec.Descriptor, len(idx.Manifests))
+	for i, m := range idx.Manifests {
+		switch m.MediaType {
+		case images.MediaTypeDockerSchema2Manifest, ocispec.MediaTypeImageManifest:
+		default:
+			return ocispec.Descriptor{}, errors.Errorf("unsupported manifest type %s in index %s", m.MediaType, desc.Digest)
+		}
+		out, err := c.convertManifest(ctx, m, t)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		// platform and annotations stay with the converted manifest
+		m.MediaType, m.Digest, m.Size, m.URLs = out.MediaType, out.Digest, out.Size, nil
+		manifests[i] = m
+	}
+
+	indexType := images.MediaTypeDockerSchema2ManifestList
+	if t.OCI {
+		indexType = ocispec.MediaTypeImageIndex
+	}
+	raw["mediaType"] = indexType
+	raw["manifests"] = manifests
+	return c.writeJSON(ctx, indexType, raw)
+}
+
+func (c *Converter) convertManifest(ctx context.Context, desc ocispec.Descriptor, t Target) (ocispec.Descriptor, error) {
+	dt, err := content.ReadBlob(ctx, c.opt.ContentStore, desc.Digest)
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to read manifest %s", desc.Digest)
+	}
+	var mfst ocispec.Manifest
+	if err := json.Unmarshal(dt, &mfst); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to parse manifest %s", desc.Digest)
+	}
+	// unknown fields of the manifest are kept
+	var raw map[string]interface{}
+	if err := json.Unmarshal(dt, &raw); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to parse manifest %s", desc.Digest)
+	}
+
+	layers := make([]ocispec.Descriptor, len(mfst.Layers))
+	for i, l := range mfst.Layers {
+		if layers[i], err = c.convertLayer(ctx, l, t); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	config := mfst.Config
+	manifestType := images.MediaTypeDockerSchema2Manifest
+	config.MediaType = images.MediaTypeDockerSchema2Config
+	if t.OCI {
+		manifestType = ocispec.MediaTypeImageManifest
+		config.MediaType = ocispec.MediaTypeImageConfig
+	}
+
+	raw["mediaType"] = manifestType
+	raw["config"] = config
+	raw["layers"] = layers
+	return c.writeJSON(ctx, manifestType, raw)
+}
+
+// writeJSON stores a manifest or index in the content store
+func (c *Converter) writeJSON(ctx context.Context, mediaType string, v interface{}) (ocispec.Descriptor, error) {
+	dt, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to marshal %s", mediaType)
+	}
+	out := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	if err := content.WriteBlob(ctx, c.opt.ContentStore, "convert-"+out.Digest.String(), bytes.NewReader(dt), out.Size, out.Digest); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to write %s", mediaType)
+	}
+	return out, nil
+}
+
+func (c *Converter) convertLayer(ctx context.Context, desc ocispec.Descriptor, t Target) (ocispec.Descriptor, error) {
+	from, ok := layerCompression(desc.MediaType)
+	if !ok {
+		// foreign and non-distributable layers are kept as is
+		return desc, nil
+	}
+	mediaType := layerMediaType(t)
+	out := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+		URLs:      desc.URLs,
+	}
+	if from == t.Compression {
+		return out, nil
+	}
+
+	if c.opt.BlobMapper != nil {
+		dgst, err := c.opt.BlobMapper.GetConversion(ctx, desc.Digest, mediaType)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if dgst != "" {
+			info, err := c.opt.ContentStore.Info(ctx, dgst)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			out.Digest, out.Size, out.URLs = info.Digest, info.Size, nil
+			return out, nil
+		}
+	}
+
+	dgst, err := c.recompress(ctx, desc.Digest, from, t.Compression)
+	if err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to convert layer %s", desc.Digest)
+	}
+	info, err := c.opt.ContentStore.Info(ctx, dgst)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if c.opt.BlobMapper != nil {
+		if err := c.opt.BlobMapper.SetConversion(ctx, desc.Digest, mediaType, dgst); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	out.Digest, out.Size, out.URLs = info.Digest, info.Size, nil
+	return out, nil
+}
+
+func (c *Converter) recompress(ctx context.Context, dgst digest.Digest, from, to Compression) (digest.Digest, error) {
+	rc, err := c.opt.ContentStore.Reader(ctx, dgst)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var r io.Reader = rc
+	switch from {
+	case Gzip:
+		zr, err := gzip.NewReader(rc)
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		r = zr
+	case Zstd:
+		zr, err := zstd.NewReader(rc)
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	cw, err := c.opt.ContentStore.Writer(ctx, "convert-"+dgst.String()+"-"+to.String(), 0, "")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open writer")
+	}
+	defer cw.Close()
+
+	var zw io.WriteCloser
+	switch to {
+	case Gzip:
+		zw = pgzip.NewWriter(cw)
+	case Zstd:
+		if zw, err = zstd.NewWriter(cw); err != nil {
+			return "", err
+		}
+	default:
+		zw = nopCloser{cw}
+	}
+	if _, err := io.Copy(zw, r); err != nil {
+		zw.Close()
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	out := cw.Digest()
+	if err := cw.Commit(0, out); err != nil {
+		if !content.IsExists(err) {
+			return "", errors.Wrap(err, "failed to commit")
+		}
+	}
+	return out, nil
+}
+
+func layerCompression(mediaType string) (Compression, bool) {
+	switch mediaType {
+	case images.MediaTypeDockerSchema2Layer, ocispec.MediaTypeImageLayer:
+		return Uncompressed, true
+	case images.MediaTypeDockerSchema2LayerGzip, ocispec.MediaTypeImageLayerGzip:
+		return Gzip, true
+	case MediaTypeImageLayerZstd:
+		return Zstd, true
+	default:
+		return 0, false
+	}
+}
+
+func layerMediaType(t Target) string {
+	switch t.Compression {
+	case Gzip:
+		if t.OCI {
+			return ocispec.MediaTypeImageLayerGzip
+		}
+		return images.MediaTypeDockerSchema2LayerGzip
+	case Zstd:
+		return MediaTypeImageLayerZstd
+	default:
+		if t.OCI {
+			return ocispec.MediaTypeImageLayer
+		}
+		return images.MediaTypeDockerSchema2Layer
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// Fetch resolves an image reference and stores its manifest, config and
+// layers in the content store
+func Fetch(ctx context.Context, cs content.Store, resolver remotes.Resolver, ref string) (ocispec.Descriptor, error) {
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	handler := images.Handlers(
+		remotes.FetchHandler(cs, fetcher),
+		children(cs),
+	)
+	if err := images.Dispatch(ctx, handler, desc); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// Push uploads the image with the manifest or index desc to ref. Layers and
+// config are pushed before their manifest and manifests before the index.
+func Push(ctx context.Context, cs content.Store, resolver remotes.Resolver, ref string, desc ocispec.Descriptor) error {
+	pusher, err := resolver.Pusher(ctx, ref)
+	if err != nil {
+		return err
+	}
+	return push(ctx, cs, remotes.PushHandler(cs, pusher), desc)
+}
+
+func push(ctx context.Context, cs content.Store, handler images.HandlerFunc, desc ocispec.Descriptor) error {
+	descs, err := children(cs)(ctx, desc)
+	if err != nil {
+		return err
+	}
+	for i, d := range descs {
+		switch d.MediaType {
+		case images.MediaTypeDockerSchema2Manifest, ocispec.MediaTypeImageManifest,
+			images.MediaTypeDockerSchema2ManifestList, ocispec.MediaTypeImageIndex:
+			if err := push(ctx, cs, handler, d); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := layerCompression(d.MediaType); i > 0 && !ok {
+			continue // foreign layers are not pushed to the registry
+		}
+		if _, err := handler(ctx, d); err != nil {
+			return errors.Wrapf(err, "failed to push %s", d.Digest)
+		}
+	}
+	if _, err := handler(ctx, desc); err != nil {
+		return errors.Wrapf(err, "failed to push %s", desc.Digest)
+	}
+	return nil
+}
+
+// children returns the config and layers of manifests and the manifests of
+// indexes. The containerd handler rejects the OCI layer types.
+func children(p content.Provider) images.HandlerFunc {
+	return func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		switch desc.MediaType {
+		case images.MediaTypeDockerSchema2Manifest, ocispec.MediaTypeImageManifest:
+		case images.MediaTypeDockerSchema2ManifestList, ocispec.MediaTypeImageIndex:
+			dt, err := content.ReadBlob(ctx, p, desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			var idx ocispec.Index
+			if err := json.Unmarshal(dt, &idx); err != nil {
+				return nil, err
+			}
+			return idx.Manifests, nil
+		default:
+			return nil, nil
+		}
+		dt, err := content.ReadBlob(ctx, p, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		var mfst ocispec.Manifest
+		if err := json.Unmarshal(dt, &mfst); err != nil {
+			return nil, err
+		}
+		return append([]ocispec.Descriptor{mfst.Config}, mfst.Layers...), nil
+	}
+}
@@ -0,0 +1,201 @@
+package convert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/klauspost/compress/zstd"
+	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "convert")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(tmpdir)
+	assert.NoError(t, err)
+
+	layerData := []byte("layer contents")
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	zw.Write(layerData)
+	assert.NoError(t, zw.Close())
+	layer := writeBlob(t, cs, images.MediaTypeDockerSchema2LayerGzip, buf.Bytes())
+	config := writeBlob(t, cs, images.MediaTypeDockerSchema2Config, []byte(`{"architecture":"amd64","os":"linux"}`))
+	dt, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     images.MediaTypeDockerSchema2Manifest,
+		"config":        config,
+		"layers":        []ocispec.Descriptor{layer},
+	})
+	assert.NoError(t, err)
+	mfst := writeBlob(t, cs, images.MediaTypeDockerSchema2Manifest, dt)
+
+	bm := &blobMapper{}
+	c := New(Opt{ContentStore: cs, BlobMapper: bm})
+
+	_, err = c.Convert(ctx, mfst, Target{Compression: Zstd})
+	assert.Error(t, err)
+
+	desc, err := c.Convert(ctx, mfst, Target{OCI: true, Compression: Zstd})
+	assert.NoError(t, err)
+	assert.Equal(t, ocispec.MediaTypeImageManifest, desc.MediaType)
+
+	converted := readManifest(t, cs, desc)
+	assert.Equal(t, ocispec.MediaTypeImageConfig, converted.Config.MediaType)
+	assert.Equal(t, config.Digest, converted.Config.Digest)
+	assert.Equal(t, 1, len(converted.Layers))
+	assert.Equal(t, MediaTypeImageLayerZstd, converted.Layers[0].MediaType)
+
+	blob, err := content.ReadBlob(ctx, cs, converted.Layers[0].Digest)
+	assert.NoError(t, err)
+	zr, err := zstd.NewReader(bytes.NewReader(blob))
+	assert.NoError(t, err)
+	dt, err = ioutil.ReadAll(zr)
+	zr.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, layerData, dt)
+	assert.Equal(t, 1, bm.sets)
+
+	// the converted layer is reused
+	desc2, err := c.Convert(ctx, mfst, Target{OCI: true, Compression: Zstd})
+	assert.NoError(t, err)
+	assert.Equal(t, desc.Digest, desc2.Digest)
+	assert.Equal(t, 1, bm.sets)
+
+	// same compression only changes the media types
+	desc, err = c.Convert(ctx, mfst, Target{OCI: true, Compression: Gzip})
+	assert.NoError(t, err)
+	converted = readManifest(t, cs, desc)
+	assert.Equal(t, ocispec.MediaTypeImageLayerGzip, converted.Layers[0].MediaType)
+	assert.Equal(t, layer.Digest, converted.Layers[0].Digest)
+
+	desc, err = c.Convert(ctx, mfst, Target{Compression: Uncompressed})
+	assert.NoError(t, err)
+	converted = readManifest(t, cs, desc)
+	assert.Equal(t, images.MediaTypeDockerSchema2Layer, converted.Layers[0].MediaType)
+	assert.Equal(t, digest.FromBytes(layerData), converted.Layers[0].Digest)
+}
+
+func TestConvertIndex(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "convert")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(tmpdir)
+	assert.NoError(t, err)
+
+	var manifests []ocispec.Descriptor
+	for _, arch := range []string{"amd64", "arm64"} {
+		layer := writeBlob(t, cs, ocispec.MediaTypeImageLayer, []byte("layer "+arch))
+		config := writeBlob(t, cs, ocispec.MediaTypeImageConfig, []byte(`{"architecture":"`+arch+`","os":"linux"}`))
+		dt, err := json.Marshal(ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			Config:    config,
+			Layers:    []ocispec.Descriptor{layer},
+		})
+		assert.NoError(t, err)
+		mfst := writeBlob(t, cs, ocispec.MediaTypeImageManifest, dt)
+		mfst.Platform = &ocispec.Platform{Architecture: arch, OS: "linux"}
+		manifests = append(manifests, mfst)
+	}
+	dt, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: manifests,
+	})
+	assert.NoError(t, err)
+	idx := writeBlob(t, cs, ocispec.MediaTypeImageIndex, dt)
+
+	c := New(Opt{ContentStore: cs})
+	desc, err := c.Convert(ctx, idx, Target{Compression: Gzip})
+	assert.NoError(t, err)
+	assert.Equal(t, images.MediaTypeDockerSchema2ManifestList, desc.MediaType)
+
+	dt, err = content.ReadBlob(ctx, cs, desc.Digest)
+	assert.NoError(t, err)
+	var converted ocispec.Index
+	assert.NoError(t, json.Unmarshal(dt, &converted))
+	assert.Equal(t, 2, len(converted.Manifests))
+	for i, m := range converted.Manifests {
+		assert.Equal(t, images.MediaTypeDockerSchema2Manifest, m.MediaType)
+		assert.Equal(t, manifests[i].Platform, m.Platform)
+		mfst := readManifest(t, cs, m)
+		assert.Equal(t, images.MediaTypeDockerSchema2LayerGzip, mfst.Layers[0].MediaType)
+	}
+
+	// blobs are pushed before the manifests that reference them
+	var pushed []string
+	handler := images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		pushed = append(pushed, desc.MediaType)
+		return nil, nil
+	})
+	assert.NoError(t, push(ctx, cs, handler, desc))
+	assert.Equal(t, []string{
+		images.MediaTypeDockerSchema2Config,
+		images.MediaTypeDockerSchema2LayerGzip,
+		images.MediaTypeDockerSchema2Manifest,
+		images.MediaTypeDockerSchema2Config,
+		images.MediaTypeDockerSchema2LayerGzip,
+		images.MediaTypeDockerSchema2Manifest,
+		images.MediaTypeDockerSchema2ManifestList,
+	}, pushed)
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range []Compression{Uncompressed, Gzip, Zstd} {
+		parsed, err := ParseCompression(c.String())
+		assert.NoError(t, err)
+		assert.Equal(t, c, parsed)
+	}
+	_, err := ParseCompression("lz4")
+	assert.Error(t, err)
+}
+
+type blobMapper struct {
+	m    map[string]digest.Digest
+	sets int
+}
+
+func (b *blobMapper) GetConversion(ctx context.Context, blob digest.Digest, mediaType string) (digest.Digest, error) {
+	return b.m[blob.String()+mediaType], nil
+}
+
+func (b *blobMapper) SetConversion(ctx context.Context, blob digest.Digest, mediaType string, converted digest.Digest) error {
+	if b.m == nil {
+		b.m = map[string]digest.Digest{}
+	}
+	b.m[blob.String()+mediaType] = converted
+	b.sets++
+	return nil
+}
+
+func writeBlob(t *testing.T, cs content.Store, mediaType string, dt []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	assert.NoError(t, content.WriteBlob(context.TODO(), cs, desc.Digest.String(), bytes.NewReader(dt), desc.Size, desc.Digest))
+	return desc
+}
+
+func readManifest(t *testing.T, cs content.Store, desc ocispec.Descriptor) ocispec.Manifest {
+	dt, err := content.ReadBlob(context.TODO(), cs, desc.Digest)
+	assert.NoError(t, err)
+	var mfst ocispec.Manifest
+	assert.NoError(t, json.Unmarshal(dt, &mfst))
+	return mfst
+}
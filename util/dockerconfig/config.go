@@ -0,0 +1,94 @@
+package dockerconfig
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Config holds the registry credentials of a docker cli config file.
+// Credential helpers are not supported.
+type Config struct {
+	Auths map[string]AuthConfig `json:"auths"`
+}
+
+type AuthConfig struct {
+	Auth          string `json:"auth,omitempty"` // base64 of username:password
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// Dir returns the directory of the docker cli config, $DOCKER_CONFIG or
+// ~/.docker
+func Dir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".docker")
+}
+
+// Load reads config.json in dir. An empty config is returned if the file
+// doesn't exist.
+func Load(dir string) (*Config, error) {
+	c := &Config{}
+	dt, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(dt, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filepath.Join(dir, "config.json"))
+	}
+	return c, nil
+}
+
+// Credentials returns the username and secret for a registry host the way
+// the containerd docker resolver expects them. An identity token is returned
+// as the secret with an empty username.
+func (c *Config) Credentials(host string) (string, string, error) {
+	host = normalizeHost(host)
+	for k, a := range c.Auths {
+		if normalizeHost(k) != host {
+			continue
+		}
+		if a.IdentityToken != "" {
+			return "", a.IdentityToken, nil
+		}
+		if a.Auth == "" {
+			return a.Username, a.Password, nil
+		}
+		dt, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "invalid auth for %s", k)
+		}
+		parts := strings.SplitN(string(dt), ":", 2)
+		if len(parts) != 2 {
+			return "", "", errors.Errorf("invalid auth for %s", k)
+		}
+		return parts[0], parts[1], nil
+	}
+	return "", "", nil
+}
+
+// normalizeHost strips the scheme and path of config keys and maps the
+// Docker Hub names to docker.io
+func normalizeHost(s string) string {
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	if i := strings.Index(s, "/"); i >= 0 {
+		s = s[:i]
+	}
+	switch s {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return s
+}
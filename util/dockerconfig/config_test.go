@@ -0,0 +1,59 @@
+package dockerconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "dockerconfig")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	c, err := Load(tmpdir)
+	assert.NoError(t, err)
+	user, secret, err := c.Credentials("registry-1.docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, "", user)
+	assert.Equal(t, "", secret)
+
+	err = ioutil.WriteFile(filepath.Join(tmpdir, "config.json"), []byte(`{
+	"auths": {
+		"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNzOndvcmQ="},
+		"registry.example.com": {"identitytoken": "token"},
+		"http://localhost:5000": {"username": "foo", "password": "bar"},
+		"invalid.example.com": {"auth": "Zm9v"}
+	}
+}`), 0600)
+	assert.NoError(t, err)
+
+	c, err = Load(tmpdir)
+	assert.NoError(t, err)
+
+	user, secret, err = c.Credentials("registry-1.docker.io")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass:word", secret)
+
+	user, secret, err = c.Credentials("registry.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "", user)
+	assert.Equal(t, "token", secret)
+
+	user, secret, err = c.Credentials("localhost:5000")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", user)
+	assert.Equal(t, "bar", secret)
+
+	_, _, err = c.Credentials("invalid.example.com")
+	assert.Error(t, err)
+
+	user, secret, err = c.Credentials("other.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "", user)
+	assert.Equal(t, "", secret)
+}
@@ -0,0 +1,239 @@
+package egressproxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/boltdb/bolt"
+	"github.com/containerd/containerd/content"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+const dbFile = "httpcache.db"
+
+var bucketByURL = []byte("url")
+
+type CacheMode int
+
+const (
+	// CacheRecord stores responses and revalidates them with the origin on
+	// every request. Stored responses are served if the origin can't be
+	// reached.
+	CacheRecord CacheMode = iota
+	// CacheReplay serves only stored responses and never goes to the
+	// network. HTTPS and other requests that are not recorded fail.
+	CacheReplay
+)
+
+func ParseCacheMode(s string) (CacheMode, error) {
+	switch s {
+	case "record":
+		return CacheRecord, nil
+	case "replay":
+		return CacheReplay, nil
+	}
+	return 0, errors.Errorf("invalid cache mode %q, expected record or replay", s)
+}
+
+type CacheOpt struct {
+	Root         string
+	ContentStore content.Store
+	Mode         CacheMode
+}
+
+// Cache stores the bodies of plain HTTP GET responses in the content store. An index
+// maps the URL to the body and the validators of the response.
+type Cache struct {
+	opt CacheOpt
+	db  *bolt.DB
+}
+
+type cacheRecord struct {
+	Digest digest.Digest
+	Size   int64
+	Header http.Header
+}
+
+// storedHeaders are kept with the body, the validators are also used for
+// revalidating
+var storedHeaders = []string{"Content-Type", "Content-Encoding", "ETag", "Last-Modified"}
+
+func NewCache(opt CacheOpt) (*Cache, error) {
+	if err := os.MkdirAll(opt.Root, 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", opt.Root)
+	}
+
+	p := filepath.Join(opt.Root, dbFile)
+	db, err := bolt.Open(p, 0600, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open database file %s", p)
+	}
+
+	return &Cache{opt: opt, db: db}, nil
+}
+
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+func (c *Cache) Mode() CacheMode {
+	return c.opt.Mode
+}
+
+func cacheable(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.Header.Get("Range") == "" && r.Header.Get("Authorization") == ""
+}
+
+// serve handles a GET request. It returns false if the response was not
+// recorded in replay mode.
+func (c *Cache) serve(w http.ResponseWriter, r *http.Request, rt http.RoundTripper) bool {
+	key := r.URL.String()
+	rec, err := c.get(key)
+	if err != nil {
+		logrus.Errorf("%+v", err)
+	}
+
+	if c.opt.Mode == CacheReplay {
+		if rec == nil || !c.serveRecord(w, r, rec) {
+			http.Error(w, key+" was not recorded", http.StatusGatewayTimeout)
+			return false
+		}
+		return true
+	}
+
+	req := proxyRequest(r)
+	for _, h := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since"} {
+		req.Header.Del(h)
+	}
+	if rec != nil {
+		if v := rec.Header.Get("ETag"); v != "" {
+			req.Header.Set("If-None-Match", v)
+		}
+		if v := rec.Header.Get("Last-Modified"); v != "" {
+			req.Header.Set("If-Modified-Since", v)
+		}
+	}
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		if rec != nil && c.serveRecord(w, r, rec) {
+			return true
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return true
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && rec != nil && c.serveRecord(w, r, rec) {
+		return true
+	}
+
+	copyHeader(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		io.Copy(w, resp.Body)
+		return true
+	}
+
+	if err := c.record(r.Context(), key, w, resp); err != nil {
+		logrus.Errorf("failed to record %s: %+v", key, err)
+	}
+	return true
+}
+
+// record streams the response body to the client while storing it
+func (c *Cache) record(ctx context.Context, key string, w io.Writer, resp *http.Response) error {
+	// concurrent requests for the same URL are not recorded twice, the
+	// writer for the same ref is locked
+	ref := "httpcache-" + digest.FromString(key).Hex()
+	cw, err := c.opt.ContentStore.Writer(ctx, ref, resp.ContentLength, "")
+	if err != nil {
+		io.Copy(w, resp.Body)
+		return err
+	}
+	defer cw.Close()
+	if err := cw.Truncate(0); err != nil { // leftover from an interrupted write
+		io.Copy(w, resp.Body)
+		return err
+	}
+
+	digester := digest.Canonical.Digester()
+	n, err := io.Copy(io.MultiWriter(w, cw, digester.Hash()), resp.Body)
+	if err != nil {
+		c.opt.ContentStore.Abort(ctx, ref)
+		return err
+	}
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		c.opt.ContentStore.Abort(ctx, ref)
+		return errors.Errorf("short response body %d/%d", n, resp.ContentLength)
+	}
+	dgst := digester.Digest()
+	if err := cw.Commit(n, dgst); err != nil && !content.IsExists(err) {
+		return err
+	}
+
+	rec := &cacheRecord{Digest: dgst, Size: n, Header: http.Header{}}
+	for _, h := range storedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			rec.Header.Set(h, v)
+		}
+	}
+	return c.put(key, rec)
+}
+
+func (c *Cache) serveRecord(w http.ResponseWriter, r *http.Request, rec *cacheRecord) bool {
+	rc, err := c.opt.ContentStore.Reader(r.Context(), rec.Digest)
+	if err != nil {
+		logrus.Errorf("failed to read recorded %s: %v", rec.Digest, err)
+		return false
+	}
+	defer rc.Close()
+	copyHeader(w.Header(), rec.Header)
+	w.Header().Set("Content-Length", strconv.FormatInt(rec.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, rc)
+	return true
+}
+
+func (c *Cache) get(key string) (*cacheRecord, error) {
+	var rec *cacheRecord
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketByURL)
+		if b == nil {
+			return nil
+		}
+		dt := b.Get([]byte(key))
+		if dt == nil {
+			return nil
+		}
+		rec = &cacheRecord{}
+		return json.Unmarshal(dt, rec)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cache record for %s", key)
+	}
+	return rec, nil
+}
+
+func (c *Cache) put(key string, rec *cacheRecord) error {
+	dt, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucketByURL)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), dt)
+	})
+}
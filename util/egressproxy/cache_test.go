@@ -0,0 +1,128 @@
+package egressproxy
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "httpcache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "content"))
+	assert.NoError(t, err)
+
+	var requests, notModified int
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("package " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	get := func(p *Proxy, u string) (int, string) {
+		srv := httptest.NewServer(p)
+		defer srv.Close()
+		proxyURL, err := url.Parse(srv.URL)
+		assert.NoError(t, err)
+		c := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+		resp, err := c.Get(u)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		dt, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return resp.StatusCode, string(dt)
+	}
+
+	cache, err := NewCache(CacheOpt{Root: tmpdir, ContentStore: cs, Mode: CacheRecord})
+	assert.NoError(t, err)
+	var unrecorded []string
+	// all hosts can be reached when recording without an allowlist
+	p := New(Opt{Cache: cache, NotRecorded: func(u, reason string) { unrecorded = append(unrecorded, u) }})
+	assert.True(t, p.Allowed("example.com"))
+
+	for i := 0; i < 2; i++ {
+		code, body := get(p, upstream.URL+"/foo")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "package /foo", body)
+	}
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, notModified)
+
+	// https is tunneled and reported as not recorded
+	recordSrv := httptest.NewServer(p)
+	defer recordSrv.Close()
+	conn, err := net.Dial("tcp", recordSrv.Listener.Addr().String())
+	assert.NoError(t, err)
+	host := upstream.Listener.Addr().String()
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", host, host)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	conn.Close()
+	assert.Equal(t, []string{"https://" + host}, unrecorded)
+	assert.NoError(t, cache.Close())
+
+	cache, err = NewCache(CacheOpt{Root: tmpdir, ContentStore: cs, Mode: CacheReplay})
+	assert.NoError(t, err)
+	defer cache.Close()
+	var missing []string
+	p = New(Opt{Cache: cache, NotRecorded: func(u, reason string) { missing = append(missing, u) }})
+
+	code, body := get(p, upstream.URL+"/foo")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "package /foo", body)
+	assert.Equal(t, 2, requests)
+
+	code, _ = get(p, upstream.URL+"/bar")
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	assert.Equal(t, []string{upstream.URL + "/bar"}, missing)
+	assert.Equal(t, 2, requests)
+
+	// requests that can't be recorded never reach the network
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+	do := func(method, u string, header http.Header) int {
+		req, err := http.NewRequest(method, u, nil)
+		assert.NoError(t, err)
+		req.Header = header
+		proxyURL, err := url.Parse(srv.URL)
+		assert.NoError(t, err)
+		resp, err := (&http.Transport{Proxy: http.ProxyURL(proxyURL)}).RoundTrip(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusGatewayTimeout, do(http.MethodPost, upstream.URL+"/foo", http.Header{}))
+	assert.Equal(t, http.StatusGatewayTimeout, do(http.MethodGet, upstream.URL+"/foo", http.Header{"Range": {"bytes=0-1"}}))
+	assert.Equal(t, http.StatusGatewayTimeout, do(http.MethodGet, upstream.URL+"/foo", http.Header{"Authorization": {"Basic Zm9v"}}))
+
+	conn, err = net.Dial("tcp", srv.Listener.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", host, host)
+	resp, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 5, len(missing))
+	assert.Equal(t, "https://"+host, missing[4])
+}
@@ -0,0 +1,304 @@
+package egressproxy
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Env is the environment that makes HTTP clients use a proxy listening on
+// addr
+func Env(addr string) []string {
+	u := "http://" + addr
+	return []string{
+		"HTTP_PROXY=" + u,
+		"HTTPS_PROXY=" + u,
+		"http_proxy=" + u,
+		"https_proxy=" + u,
+	}
+}
+
+type Opt struct {
+	// Allow lists the hosts that can be reached. "*.example.com" matches all
+	// subdomains of example.com.
+	Allow []string
+	// Denied is called for every request to a host that is not allowed
+	Denied func(host string)
+	// Cache stores GET responses if set. Only plain HTTP is cached, HTTPS
+	// is tunneled with CONNECT and can't be seen by the proxy, it is never
+	// recorded or replayed. In record mode all hosts can be reached if
+	// Allow is empty. In replay mode requests are only served from the
+	// cache, every other request fails, and hosts don't need to be allowed.
+	Cache *Cache
+	// NotRecorded is called with the reason for requests that are not
+	// recorded in record mode or can't be served from the cache in replay
+	// mode
+	NotRecorded func(url, reason string)
+}
+
+const notRecordedHTTPS = "https is not recorded, only plain http downloads are cached"
+
+// Proxy is a HTTP proxy that only forwards requests to allowed hosts. HTTPS
+// is supported with CONNECT.
+type Proxy struct {
+	opt       Opt
+	transport *http.Transport
+
+	mu sync.Mutex
+	// tunnels are the connections of the open CONNECT requests. They are
+	// hijacked from the server so it can't close them.
+	tunnels map[net.Conn]struct{}
+	closed  bool
+}
+
+func New(opt Opt) *Proxy {
+	return &Proxy{
+		opt: opt,
+		transport: &http.Transport{
+			Proxy:             nil,
+			DisableKeepAlives: true,
+		},
+		tunnels: make(map[net.Conn]struct{}),
+	}
+}
+
+// Allowed returns true if a host can be reached. All hosts are allowed when
+// recording downloads without an allowlist.
+func (p *Proxy) Allowed(host string) bool {
+	if len(p.opt.Allow) == 0 && p.opt.Cache != nil && p.opt.Cache.Mode() == CacheRecord {
+		return true
+	}
+	return allowed(p.opt.Allow, host)
+}
+
+func allowed(allow []string, host string) bool {
+	host = normalizeHost(host)
+	for _, a := range allow {
+		a = normalizeHost(a)
+		if strings.HasPrefix(a, "*.") {
+			if strings.HasSuffix(host, a[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers returns true if all the hosts matched by pattern are matched by
+// the allowed hosts
+func Covers(allow []string, pattern string) bool {
+	pattern = normalizeHost(pattern)
+	if !strings.HasPrefix(pattern, "*.") {
+		return allowed(allow, pattern)
+	}
+	for _, a := range allow {
+		a = normalizeHost(a)
+		if strings.HasPrefix(a, "*.") && strings.HasSuffix(pattern[1:], a[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// Close closes the open CONNECT tunnels. The server serving the proxy has
+// to be closed too.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for c := range p.tunnels {
+		c.Close()
+	}
+	p.tunnels = nil
+	p.transport.CloseIdleConnections()
+	return nil
+}
+
+// track adds the connections of a tunnel. It returns false if the proxy is
+// closed.
+func (p *Proxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	for _, c := range conns {
+		p.tunnels[c] = struct{}{}
+	}
+	return true
+}
+
+func (p *Proxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range conns {
+		delete(p.tunnels, c)
+	}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Host
+	if r.Method == http.MethodConnect {
+		host = r.Host
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "" {
+		http.Error(w, "not a proxy request", http.StatusBadRequest)
+		return
+	}
+	if p.opt.Cache != nil && p.opt.Cache.Mode() == CacheReplay {
+		p.replay(w, r, host)
+		return
+	}
+	if !p.Allowed(hostname) {
+		if p.opt.Denied != nil {
+			p.opt.Denied(hostname)
+		}
+		http.Error(w, "host "+hostname+" is not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodConnect {
+		if p.opt.Cache != nil {
+			p.notRecorded("https://"+host, notRecordedHTTPS)
+		}
+		p.connect(w, r, host)
+		return
+	}
+	if p.opt.Cache != nil && cacheable(r) {
+		p.opt.Cache.serve(w, r, p.transport)
+		return
+	}
+	p.forward(w, r)
+}
+
+// replay serves a request from the cache without going to the network.
+// Requests that can't have been recorded fail.
+func (p *Proxy) replay(w http.ResponseWriter, r *http.Request, host string) {
+	u := r.URL.String()
+	var reason string
+	switch {
+	case r.Method == http.MethodConnect:
+		u = "https://" + host
+		reason = notRecordedHTTPS
+	case r.Method != http.MethodGet:
+		reason = r.Method + " requests are not recorded"
+	case !cacheable(r):
+		reason = "requests with range or authorization headers are not recorded"
+	default:
+		if !p.opt.Cache.serve(w, r, p.transport) {
+			p.notRecorded(u, "it was not recorded")
+		}
+		return
+	}
+	http.Error(w, u+" can't be replayed: "+reason, http.StatusGatewayTimeout)
+	p.notRecorded(u, reason)
+}
+
+func (p *Proxy) notRecorded(u, reason string) {
+	if p.opt.NotRecorded != nil {
+		p.opt.NotRecorded(u, reason)
+	}
+}
+
+func (p *Proxy) connect(w http.ResponseWriter, r *http.Request, host string) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+	upstream, err := net.Dial("tcp", host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		logrus.Errorf("failed to hijack proxy connection: %v", err)
+		return
+	}
+	defer conn.Close()
+	if !p.track(conn, upstream) {
+		return
+	}
+	defer p.untrack(conn, upstream)
+
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(upstream, buf) // buf may hold data the client sent early
+		closeWrite(upstream)
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(conn, upstream)
+		closeWrite(conn)
+	}()
+	wg.Wait()
+}
+
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	resp, err := p.transport.RoundTrip(proxyRequest(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	copyHeader(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// proxyRequest returns the request to send to the origin
+func proxyRequest(r *http.Request) *http.Request {
+	req := new(http.Request)
+	*req = *r
+	req.RequestURI = ""
+	req.Header = make(http.Header, len(r.Header))
+	copyHeader(req.Header, r.Header)
+	for _, h := range []string{"Proxy-Connection", "Proxy-Authorization", "Connection", "Keep-Alive", "Te", "Trailer", "Transfer-Encoding", "Upgrade"} {
+		req.Header.Del(h)
+	}
+	return req
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, vv := range src {
+		for _, v := range vv {
+			dst.Add(k, v)
+		}
+	}
+}
+
+func closeWrite(c net.Conn) {
+	if cw, ok := c.(interface {
+		CloseWrite() error
+	}); ok {
+		cw.CloseWrite()
+	}
+}
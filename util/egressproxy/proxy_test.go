@@ -0,0 +1,100 @@
+package egressproxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowed(t *testing.T) {
+	p := New(Opt{Allow: []string{"mirror.example.com", "*.golang.org"}})
+	assert.True(t, p.Allowed("mirror.example.com"))
+	assert.True(t, p.Allowed("Mirror.Example.com."))
+	assert.True(t, p.Allowed("proxy.golang.org"))
+	assert.False(t, p.Allowed("golang.org"))
+	assert.False(t, p.Allowed("example.com"))
+	assert.False(t, p.Allowed("mirror.example.com.evil.com"))
+}
+
+func TestCovers(t *testing.T) {
+	allow := []string{"mirror.example.com", "*.golang.org"}
+	assert.True(t, Covers(allow, "mirror.example.com"))
+	assert.True(t, Covers(allow, "proxy.golang.org"))
+	assert.True(t, Covers(allow, "*.golang.org"))
+	assert.True(t, Covers(allow, "*.proxy.golang.org"))
+	assert.False(t, Covers(allow, "*.example.com"))
+	assert.False(t, Covers(allow, "golang.org"))
+	assert.False(t, Covers(nil, "mirror.example.com"))
+}
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s", r.URL.Path)
+	}))
+	defer upstream.Close()
+
+	var denied []string
+	p := New(Opt{
+		Allow:  []string{"127.0.0.1"},
+		Denied: func(host string) { denied = append(denied, host) },
+	})
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	proxyURL, err := url.Parse(srv.URL)
+	assert.NoError(t, err)
+	c := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := c.Get(upstream.URL + "/foo")
+	assert.NoError(t, err)
+	dt, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello /foo", string(dt))
+
+	resp, err = c.Get("http://denied.invalid/bar")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, []string{"denied.invalid"}, denied)
+
+	// CONNECT is tunneled as is
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+	host := upstream.Listener.Addr().String()
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", host, host)
+	br := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(br, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	fmt.Fprintf(conn, "GET /baz HTTP/1.1\r\nHost: %s\r\nConnection: close\r\n\r\n", host)
+	resp, err = http.ReadResponse(br, nil)
+	assert.NoError(t, err)
+	dt, err = ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello /baz", string(dt))
+
+	// open tunnels are closed with the proxy
+	conn2, err := net.Dial("tcp", srv.Listener.Addr().String())
+	assert.NoError(t, err)
+	defer conn2.Close()
+	fmt.Fprintf(conn2, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", host, host)
+	br = bufio.NewReader(conn2)
+	resp, err = http.ReadResponse(br, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, p.Close())
+	_, err = br.ReadByte()
+	assert.Equal(t, io.EOF, err)
+}
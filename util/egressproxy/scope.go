@@ -0,0 +1,71 @@
+package egressproxy
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type scopeKeyT string
+
+var scopeKey = scopeKeyT("buildkit/util/egressproxy")
+
+// Scope is the network access of a solve. All exec steps of the solve share
+// one proxy. The worker starts it for the first step that needs it and it is
+// stopped when the scope is closed at the end of the solve.
+type Scope struct {
+	// Allow lists the hosts the steps of the solve can reach
+	Allow []string
+
+	mu     sync.Mutex
+	proxy  io.Closer
+	closed bool
+}
+
+func NewScope(allow []string) *Scope {
+	return &Scope{Allow: allow}
+}
+
+// WithScope sets the scope of the exec steps run with the context
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey, s)
+}
+
+// GetScope returns the scope of the context, nil if there is none
+func GetScope(ctx context.Context) *Scope {
+	s, _ := ctx.Value(scopeKey).(*Scope)
+	return s
+}
+
+// Proxy returns the proxy of the scope. It is created with start if it
+// hasn't been started yet and closed with the scope.
+func (s *Scope) Proxy(start func() (io.Closer, error)) (io.Closer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errors.New("network scope is closed")
+	}
+	if s.proxy == nil {
+		p, err := start()
+		if err != nil {
+			return nil, err
+		}
+		s.proxy = p
+	}
+	return s.proxy, nil
+}
+
+// Close stops the proxy of the scope
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.proxy == nil {
+		return nil
+	}
+	err := s.proxy.Close()
+	s.proxy = nil
+	return err
+}
@@ -0,0 +1,45 @@
+package egressproxy
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testProxy struct {
+	closed bool
+}
+
+func (p *testProxy) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestScope(t *testing.T) {
+	assert.Nil(t, GetScope(context.TODO()))
+	s := NewScope([]string{"example.com"})
+	ctx := WithScope(context.TODO(), s)
+	assert.Equal(t, s, GetScope(ctx))
+
+	// the proxy is started once and shared
+	var started int
+	p := &testProxy{}
+	for i := 0; i < 2; i++ {
+		c, err := GetScope(ctx).Proxy(func() (io.Closer, error) {
+			started++
+			return p, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, p, c)
+	}
+	assert.Equal(t, 1, started)
+
+	assert.NoError(t, s.Close())
+	assert.True(t, p.closed)
+	_, err := s.Proxy(func() (io.Closer, error) {
+		return &testProxy{}, nil
+	})
+	assert.Error(t, err)
+}
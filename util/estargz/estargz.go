@@ -0,0 +1,374 @@
+package estargz
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// estargz writes a tar stream as a seekable gzip blob. The header and the
+// content of every file are separate gzip members so a single file can be
+// extracted from a known offset. A table of contents with the offsets is
+// appended as the last tar entry and located by a fixed size footer. The
+// blob is still an ordinary gzip compressed tar for clients that don't know
+// the format.
+
+const (
+	// TOCDigestAnnotation is the digest of the uncompressed table of contents
+	TOCDigestAnnotation = "containerd.io/snapshot/stargz/toc.digest"
+	// UncompressedSizeAnnotation is the size of the uncompressed tar
+	UncompressedSizeAnnotation = "io.containers.estargz.uncompressed-size"
+
+	// TOCName is the name of the tar entry holding the table of contents
+	TOCName = "stargz.index.json"
+	// FooterSize is the size of the gzip member pointing to the table of
+	// contents at the end of the blob
+	FooterSize = 51
+
+	noPrefetchLandmark = ".no.prefetch.landmark"
+	landmarkContents   = 0xf
+)
+
+type TOC struct {
+	Version int         `json:"version"`
+	Entries []*TOCEntry `json:"entries"`
+}
+
+type TOCEntry struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Size        int64             `json:"size,omitempty"`
+	ModTime3339 string            `json:"modtime,omitempty"`
+	LinkName    string            `json:"linkName,omitempty"`
+	Mode        int64             `json:"mode,omitempty"`
+	UID         int               `json:"uid,omitempty"`
+	GID         int               `json:"gid,omitempty"`
+	Uname       string            `json:"userName,omitempty"`
+	Gname       string            `json:"groupName,omitempty"`
+	Offset      int64             `json:"offset,omitempty"`
+	DevMajor    int               `json:"devMajor,omitempty"`
+	DevMinor    int               `json:"devMinor,omitempty"`
+	Xattrs      map[string][]byte `json:"xattrs,omitempty"`
+	Digest      string            `json:"digest,omitempty"`
+	ChunkSize   int64             `json:"chunkSize,omitempty"`
+	ChunkDigest string            `json:"chunkDigest,omitempty"`
+}
+
+// Writer converts tar streams to the seekable format
+type Writer struct {
+	cw    *countWriter
+	gz    *gzip.Writer
+	tw    *tar.Writer
+	diff  digest.Digester
+	tarW  *countWriter // uncompressed tar
+	toc   TOC
+	level int
+
+	tocDigest digest.Digest
+	closed    bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return NewWriterLevel(w, gzip.DefaultCompression)
+}
+
+func NewWriterLevel(w io.Writer, level int) *Writer {
+	sw := &Writer{
+		cw:    &countWriter{w: w},
+		diff:  digest.Canonical.Digester(),
+		toc:   TOC{Version: 1},
+		level: level,
+	}
+	sw.tarW = &countWriter{w: writerFunc(sw.writeTar)}
+	sw.tw = tar.NewWriter(sw.tarW)
+	return sw
+}
+
+// AppendTar adds the entries of a tar stream. The stream must not contain
+// the reserved entry names.
+func (w *Writer) AppendTar(r io.Reader) error {
+	if len(w.toc.Entries) == 0 {
+		if err := w.writeLandmark(); err != nil {
+			return err
+		}
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read tar")
+		}
+		if name := cleanName(h.Name); name == TOCName || name == noPrefetchLandmark {
+			return errors.Errorf("reserved name %s in tar", h.Name)
+		}
+		if err := w.appendEntry(h, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Writer) writeLandmark() error {
+	h := &tar.Header{
+		Name:     noPrefetchLandmark,
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     1,
+	}
+	return w.appendEntry(h, bytes.NewReader([]byte{landmarkContents}))
+}
+
+func (w *Writer) appendEntry(h *tar.Header, r io.Reader) error {
+	e := &TOCEntry{
+		Name:     cleanName(h.Name),
+		LinkName: h.Linkname,
+		Mode:     h.Mode,
+		UID:      h.Uid,
+		GID:      h.Gid,
+		Uname:    h.Uname,
+		Gname:    h.Gname,
+		DevMajor: int(h.Devmajor),
+		DevMinor: int(h.Devminor),
+	}
+	if !h.ModTime.IsZero() {
+		e.ModTime3339 = h.ModTime.UTC().Format(time.RFC3339)
+	}
+	if len(h.Xattrs) > 0 {
+		e.Xattrs = make(map[string][]byte, len(h.Xattrs))
+		for k, v := range h.Xattrs {
+			e.Xattrs[k] = []byte(v)
+		}
+	}
+	switch h.Typeflag {
+	case tar.TypeReg, tar.TypeRegA:
+		e.Type = "reg"
+		e.Size = h.Size
+	case tar.TypeDir:
+		e.Type = "dir"
+	case tar.TypeSymlink:
+		e.Type = "symlink"
+	case tar.TypeLink:
+		e.Type = "hardlink"
+	case tar.TypeChar:
+		e.Type = "char"
+	case tar.TypeBlock:
+		e.Type = "block"
+	case tar.TypeFifo:
+		e.Type = "fifo"
+	default:
+		return errors.Errorf("unsupported tar entry type %q for %s", h.Typeflag, h.Name)
+	}
+
+	if err := w.openGz(); err != nil {
+		return err
+	}
+	if err := w.tw.WriteHeader(h); err != nil {
+		return errors.Wrapf(err, "failed to write header for %s", h.Name)
+	}
+
+	if e.Type == "reg" && h.Size > 0 {
+		// the content starts a new member so it can be read from the offset
+		if err := w.closeGz(); err != nil {
+			return err
+		}
+		e.Offset = w.cw.n
+		if err := w.openGz(); err != nil {
+			return err
+		}
+		dgstr := digest.Canonical.Digester()
+		n, err := io.Copy(io.MultiWriter(w.tw, dgstr.Hash()), r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to write %s", h.Name)
+		}
+		if n != h.Size {
+			return errors.Errorf("short read for %s: %d/%d", h.Name, n, h.Size)
+		}
+		e.Digest = dgstr.Digest().String()
+		e.ChunkDigest = e.Digest
+		// padding is written to the same member so the next one starts
+		// with a header
+		if err := w.tw.Flush(); err != nil {
+			return errors.Wrapf(err, "failed to write %s", h.Name)
+		}
+		if err := w.closeGz(); err != nil {
+			return err
+		}
+	}
+	w.toc.Entries = append(w.toc.Entries, e)
+	return nil
+}
+
+// Close writes the table of contents, the end of the tar archive and the
+// footer
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if len(w.toc.Entries) == 0 {
+		if err := w.writeLandmark(); err != nil {
+			return err
+		}
+	}
+	if err := w.closeGz(); err != nil {
+		return err
+	}
+
+	dt, err := json.MarshalIndent(w.toc, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal table of contents")
+	}
+	w.tocDigest = digest.FromBytes(dt)
+
+	tocOffset := w.cw.n
+	if err := w.openGz(); err != nil {
+		return err
+	}
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:     TOCName,
+		Typeflag: tar.TypeReg,
+		Mode:     0444,
+		Size:     int64(len(dt)),
+	}); err != nil {
+		return errors.Wrap(err, "failed to write table of contents")
+	}
+	if _, err := w.tw.Write(dt); err != nil {
+		return errors.Wrap(err, "failed to write table of contents")
+	}
+	if err := w.tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to close tar")
+	}
+	if err := w.closeGz(); err != nil {
+		return err
+	}
+
+	footer, err := footerBytes(tocOffset)
+	if err != nil {
+		return err
+	}
+	_, err = w.cw.Write(footer)
+	return err
+}
+
+// TOCDigest is the digest of the table of contents, set after Close
+func (w *Writer) TOCDigest() digest.Digest {
+	return w.tocDigest
+}
+
+// DiffID is the digest of the uncompressed tar, set after Close
+func (w *Writer) DiffID() digest.Digest {
+	return w.diff.Digest()
+}
+
+// UncompressedSize is the size of the uncompressed tar
+func (w *Writer) UncompressedSize() int64 {
+	return w.tarW.n
+}
+
+func (w *Writer) openGz() error {
+	if w.gz != nil {
+		return nil
+	}
+	gz, err := gzip.NewWriterLevel(w.cw, w.level)
+	if err != nil {
+		return err
+	}
+	w.gz = gz
+	return nil
+}
+
+func (w *Writer) closeGz() error {
+	if w.gz == nil {
+		return nil
+	}
+	err := w.gz.Close()
+	w.gz = nil
+	return errors.Wrap(err, "failed to close gzip member")
+}
+
+func (w *Writer) writeTar(p []byte) (int, error) {
+	if err := w.openGz(); err != nil {
+		return 0, err
+	}
+	w.diff.Hash().Write(p)
+	return w.gz.Write(p)
+}
+
+// footerBytes is an empty gzip member with the table of contents offset in
+// the extra header field. It is built by hand because the size of an empty
+// deflate stream depends on the compressor.
+func footerBytes(tocOffset int64) ([]byte, error) {
+	subfield := fmt.Sprintf("%016xSTARGZ", tocOffset)
+	buf := make([]byte, 0, FooterSize)
+	// magic, deflate, FEXTRA, no mtime, no extra flags, unknown OS
+	buf = append(buf, 0x1f, 0x8b, 8, 4, 0, 0, 0, 0, 0, 0xff)
+	buf = appendUint16(buf, uint16(4+len(subfield)))
+	buf = append(buf, 'S', 'G')
+	buf = appendUint16(buf, uint16(len(subfield)))
+	buf = append(buf, subfield...)
+	// final stored block without data, then crc32 and size of zero
+	buf = append(buf, 1, 0, 0, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0)
+	if len(buf) != FooterSize {
+		return nil, errors.Errorf("invalid footer size %d", len(buf))
+	}
+	return buf, nil
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	var dt [2]byte
+	binary.LittleEndian.PutUint16(dt[:], v)
+	return append(b, dt[:]...)
+}
+
+// OpenFooter returns the offset of the table of contents from the footer
+func OpenFooter(footer []byte) (int64, error) {
+	if len(footer) != FooterSize {
+		return 0, errors.Errorf("invalid footer size %d", len(footer))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(footer))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read footer")
+	}
+	extra := zr.Header.Extra
+	if len(extra) != 4+16+len("STARGZ") || extra[0] != 'S' || extra[1] != 'G' || !strings.HasSuffix(string(extra), "STARGZ") {
+		return 0, errors.New("invalid footer")
+	}
+	var off int64
+	if _, err := fmt.Sscanf(string(extra[4:20]), "%016x", &off); err != nil {
+		return 0, errors.Wrap(err, "invalid footer")
+	}
+	return off, nil
+}
+
+func cleanName(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
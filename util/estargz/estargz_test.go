@@ -0,0 +1,107 @@
+package estargz
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	files := map[string]string{
+		"foo/bar": "bar contents",
+		"foo/baz": string(bytes.Repeat([]byte("baz"), 10000)),
+	}
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "foo/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for _, name := range []string{"foo/bar", "foo/baz"} {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(files[name]))}))
+		_, err := tw.Write([]byte(files[name]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "foo/empty", Typeflag: tar.TypeReg, Mode: 0644}))
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "foo/link", Typeflag: tar.TypeSymlink, Linkname: "bar"}))
+	assert.NoError(t, tw.Close())
+
+	out := &bytes.Buffer{}
+	w := NewWriter(out)
+	assert.NoError(t, w.AppendTar(buf))
+	assert.NoError(t, w.Close())
+	blob := out.Bytes()
+
+	// ordinary gzip tar
+	zr, err := gzip.NewReader(bytes.NewReader(blob))
+	assert.NoError(t, err)
+	dt, err := ioutil.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, digest.FromBytes(dt), w.DiffID())
+	assert.Equal(t, int64(len(dt)), w.UncompressedSize())
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(dt))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, h.Name)
+	}
+	assert.Equal(t, []string{noPrefetchLandmark, "foo/", "foo/bar", "foo/baz", "foo/empty", "foo/link", TOCName}, names)
+
+	// table of contents from the footer
+	tocOffset, err := OpenFooter(blob[len(blob)-FooterSize:])
+	assert.NoError(t, err)
+	zr, err = gzip.NewReader(bytes.NewReader(blob[tocOffset:]))
+	assert.NoError(t, err)
+	tr = tar.NewReader(zr)
+	h, err := tr.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, TOCName, h.Name)
+	tocData, err := ioutil.ReadAll(tr)
+	assert.NoError(t, err)
+	assert.Equal(t, digest.FromBytes(tocData), w.TOCDigest())
+
+	var toc TOC
+	assert.NoError(t, json.Unmarshal(tocData, &toc))
+	assert.Equal(t, 1, toc.Version)
+	assert.Equal(t, 6, len(toc.Entries))
+
+	// every file can be read from its offset alone
+	var regs int
+	for _, e := range toc.Entries {
+		if e.Type != "reg" || e.Size == 0 {
+			continue
+		}
+		regs++
+		zr, err := gzip.NewReader(bytes.NewReader(blob[e.Offset:]))
+		assert.NoError(t, err)
+		zr.Multistream(false)
+		dt := make([]byte, e.Size)
+		_, err = io.ReadFull(zr, dt)
+		assert.NoError(t, err)
+		assert.Equal(t, e.Digest, digest.FromBytes(dt).String())
+		if e.Name != noPrefetchLandmark {
+			assert.Equal(t, files[e.Name], string(dt))
+		}
+	}
+	assert.Equal(t, 3, regs)
+}
+
+func TestReservedName(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + TOCName, Typeflag: tar.TypeReg}))
+	assert.NoError(t, tw.Close())
+
+	w := NewWriter(ioutil.Discard)
+	assert.Error(t, w.AppendTar(buf))
+}
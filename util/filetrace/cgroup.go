@@ -0,0 +1,34 @@
+package filetrace
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Filter returns true if the accesses of a process are traced
+type Filter func(pid int) bool
+
+// InCgroup matches the processes in a cgroup with a path containing name.
+// Processes whose cgroup can't be read, like the ones that have already
+// exited, are not matched. The reads of very short-lived processes of the
+// cgroup can be missed.
+func InCgroup(name string) Filter {
+	return func(pid int) bool {
+		f, err := os.Open(fmt.Sprintf("/proc/%d/cgroup", pid))
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			// hierarchy-ID:controller-list:cgroup-path
+			parts := strings.SplitN(s.Text(), ":", 3)
+			if len(parts) == 3 && strings.Contains(parts[2], name) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,24 @@
+package filetrace
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInCgroup(t *testing.T) {
+	dt, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		t.Skip("no cgroups")
+	}
+	line := strings.SplitN(strings.Split(strings.TrimSpace(string(dt)), "\n")[0], ":", 3)
+	assert.Equal(t, 3, len(line))
+
+	assert.True(t, InCgroup(line[2])(os.Getpid()))
+	assert.False(t, InCgroup("buildkit-doesnotexist")(os.Getpid()))
+	// processes whose cgroup can't be read are not matched
+	assert.False(t, InCgroup(line[2])(1<<30))
+}
@@ -0,0 +1,61 @@
+package filetrace
+
+import (
+	"context"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+)
+
+// Access lists the files an exec step read from one of its mounts
+type Access struct {
+	Vertex digest.Digest
+	// Mount is the destination of the mount in the container
+	Mount string
+	// Paths are relative to the mount and sorted
+	Paths []string
+	// Traced is false if the mount could not be traced. Paths is empty then.
+	Traced bool
+}
+
+type Recorder interface {
+	RecordAccess(Access)
+}
+
+type recorderKeyT string
+
+var recorderKey = recorderKeyT("buildkit/util/filetrace")
+
+// WithRecorder enables tracing for the exec steps run with the context
+func WithRecorder(ctx context.Context, r Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey, r)
+}
+
+// Enabled returns true if the context has a recorder
+func Enabled(ctx context.Context) bool {
+	_, ok := ctx.Value(recorderKey).(Recorder)
+	return ok
+}
+
+// Record sends the access of a mount to the recorder of the context. If the
+// vertex is empty the access is sent for every warnings vertex of the
+// context.
+func Record(ctx context.Context, a Access) {
+	r, ok := ctx.Value(recorderKey).(Recorder)
+	if !ok {
+		return
+	}
+	if a.Vertex != "" {
+		r.RecordAccess(a)
+		return
+	}
+	dgsts := warnings.Vertices(ctx)
+	if len(dgsts) == 0 {
+		r.RecordAccess(a)
+		return
+	}
+	for _, dgst := range dgsts {
+		a.Vertex = dgst
+		r.RecordAccess(a)
+	}
+}
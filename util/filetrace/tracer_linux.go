@@ -0,0 +1,213 @@
+// +build linux,amd64 linux,arm64
+
+package filetrace
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// from linux/fanotify.h, not in x/sys/unix
+const (
+	fanClassNotif     = 0x0
+	fanCloexec        = 0x1
+	fanNonblock       = 0x2
+	fanMarkAdd        = 0x1
+	fanMarkFilesystem = 0x100
+	fanAccess         = 0x1
+	fanCloseNowrite   = 0x10
+	fanQOverflow      = 0x4000
+	fanMetadataVer    = 3
+)
+
+type eventMetadata struct {
+	EventLen    uint32
+	Vers        uint8
+	Reserved    uint8
+	MetadataLen uint16
+	Mask        uint64
+	Fd          int32
+	Pid         int32
+}
+
+const metadataLen = int(unsafe.Sizeof(eventMetadata{}))
+
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// Tracer records the files read on the filesystems of a set of directories
+// with fanotify. Files are read if they were accessed or closed without
+// being written. Whole filesystems are marked so that reads through other
+// mounts of the same filesystem, like the bind mounts of a container, are
+// seen, and the filter drops the reads of unrelated processes. Marking
+// filesystems needs CAP_SYS_ADMIN and Linux 4.20.
+type Tracer struct {
+	fd     int
+	dirs   map[string]string
+	filter Filter
+
+	mu       sync.Mutex
+	read     map[fileID]struct{}
+	pids     map[int32]bool
+	overflow bool
+
+	stop chan struct{}
+	done chan error
+}
+
+// Start starts tracing the directories, keyed by a name that is used for
+// them in the result of Stop. Only the reads of processes matching the
+// filter are recorded.
+func Start(dirs map[string]string, filter Filter) (*Tracer, error) {
+	fd, _, errno := unix.Syscall(unix.SYS_FANOTIFY_INIT, fanClassNotif|fanCloexec|fanNonblock, unix.O_RDONLY|unix.O_LARGEFILE|unix.O_CLOEXEC, 0)
+	if errno != 0 {
+		return nil, errors.Wrap(errno, "fanotify_init")
+	}
+	t := &Tracer{
+		fd:     int(fd),
+		dirs:   dirs,
+		filter: filter,
+		read:   map[fileID]struct{}{},
+		pids:   map[int32]bool{},
+		stop:   make(chan struct{}),
+		done:   make(chan error, 1),
+	}
+	atFdcwd := unix.AT_FDCWD
+	for _, dir := range dirs {
+		p, err := unix.BytePtrFromString(dir)
+		if err != nil {
+			unix.Close(t.fd)
+			return nil, err
+		}
+		if _, _, errno := unix.Syscall6(unix.SYS_FANOTIFY_MARK, fd, fanMarkAdd|fanMarkFilesystem, fanAccess|fanCloseNowrite, uintptr(atFdcwd), uintptr(unsafe.Pointer(p)), 0); errno != 0 {
+			unix.Close(t.fd)
+			return nil, errors.Wrapf(errno, "fanotify_mark %s", dir)
+		}
+	}
+	go func() {
+		t.done <- t.readEvents()
+	}()
+	return t, nil
+}
+
+func (t *Tracer) readEvents() error {
+	buf := make([]byte, 64*1024)
+	fds := []unix.PollFd{{Fd: int32(t.fd), Events: unix.POLLIN}}
+	stopped := false
+	for {
+		if !stopped {
+			select {
+			case <-t.stop:
+				// read the events that are left
+				stopped = true
+			default:
+				if _, err := unix.Poll(fds, 100); err != nil && err != unix.EINTR {
+					return errors.Wrap(err, "failed to poll fanotify")
+				}
+			}
+		}
+		n, err := unix.Read(t.fd, buf)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				if stopped {
+					return nil
+				}
+				continue
+			}
+			return errors.Wrap(err, "failed to read fanotify events")
+		}
+		t.handle(buf[:n])
+	}
+}
+
+func (t *Tracer) handle(b []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for len(b) >= metadataLen {
+		ev := (*eventMetadata)(unsafe.Pointer(&b[0]))
+		if ev.EventLen < uint32(metadataLen) || int(ev.EventLen) > len(b) || ev.Vers != fanMetadataVer {
+			return
+		}
+		if ev.Mask&fanQOverflow != 0 {
+			t.overflow = true
+		}
+		if ev.Fd >= 0 {
+			var st unix.Stat_t
+			if err := unix.Fstat(int(ev.Fd), &st); err == nil && st.Mode&unix.S_IFMT != unix.S_IFDIR && t.match(ev.Pid) {
+				t.read[fileID{dev: uint64(st.Dev), ino: st.Ino}] = struct{}{}
+			}
+			unix.Close(int(ev.Fd))
+		}
+		b = b[ev.EventLen:]
+	}
+}
+
+// match returns true if the reads of a process are recorded. The filter is
+// called once per process.
+func (t *Tracer) match(pid int32) bool {
+	if t.filter == nil {
+		return true
+	}
+	m, ok := t.pids[pid]
+	if !ok {
+		m = t.filter(int(pid))
+		t.pids[pid] = m
+	}
+	return m
+}
+
+// Stop stops tracing and returns the files read in every directory,
+// relative to it. Files are matched by inode so every name of a hardlinked
+// file is returned.
+func (t *Tracer) Stop() (map[string][]string, error) {
+	close(t.stop)
+	err := <-t.done
+	unix.Close(t.fd)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.overflow {
+		return nil, errors.New("fanotify event queue overflowed")
+	}
+
+	res := make(map[string][]string, len(t.dirs))
+	for name, dir := range t.dirs {
+		paths := []string{}
+		if len(t.read) > 0 {
+			if err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+				if err != nil || fi.IsDir() {
+					return nil
+				}
+				st, ok := fi.Sys().(*syscall.Stat_t)
+				if !ok {
+					return nil
+				}
+				if _, ok := t.read[fileID{dev: uint64(st.Dev), ino: st.Ino}]; ok {
+					rel, err := filepath.Rel(dir, p)
+					if err != nil {
+						return err
+					}
+					paths = append(paths, rel)
+				}
+				return nil
+			}); err != nil {
+				return nil, errors.Wrapf(err, "failed to walk %s", dir)
+			}
+		}
+		sort.Strings(paths)
+		res[name] = paths
+	}
+	return res, nil
+}
@@ -0,0 +1,84 @@
+// +build linux,amd64 linux,arm64
+
+package filetrace
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracer(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	tmpdir, err := ioutil.TempDir("", "filetrace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	for _, p := range []string{"a", "b", "dir/c", "dir/d"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(tmpdir, filepath.Dir(p)), 0700))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpdir, p), []byte(p), 0600))
+	}
+	assert.NoError(t, os.Link(filepath.Join(tmpdir, "a"), filepath.Join(tmpdir, "dir/link")))
+
+	tr, err := Start(map[string]string{"/": tmpdir, "/dir": filepath.Join(tmpdir, "dir")}, nil)
+	if err != nil {
+		t.Skipf("fanotify filesystem marks are not available: %v", err)
+	}
+	_, err = ioutil.ReadFile(filepath.Join(tmpdir, "a"))
+	assert.NoError(t, err)
+	_, err = ioutil.ReadFile(filepath.Join(tmpdir, "dir/c"))
+	assert.NoError(t, err)
+	_, err = ioutil.ReadDir(tmpdir)
+	assert.NoError(t, err)
+	// written files are not reads
+	f, err := os.OpenFile(filepath.Join(tmpdir, "b"), os.O_RDWR, 0)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("b"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	res, err := tr.Stop()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"/":    {"a", "dir/c", "dir/link"},
+		"/dir": {"c", "link"},
+	}, res)
+}
+
+func TestTracerConcurrent(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	tmpdir, err := ioutil.TempDir("", "filetrace")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	for _, p := range []string{"a", "b"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpdir, p), []byte(p), 0600))
+	}
+
+	self := os.Getpid()
+	tr1, err := Start(map[string]string{"/": tmpdir}, func(pid int) bool { return pid == self })
+	if err != nil {
+		t.Skipf("fanotify filesystem marks are not available: %v", err)
+	}
+	tr2, err := Start(map[string]string{"/": tmpdir}, func(pid int) bool { return pid != self })
+	assert.NoError(t, err)
+
+	_, err = ioutil.ReadFile(filepath.Join(tmpdir, "a"))
+	assert.NoError(t, err)
+	assert.NoError(t, exec.Command("cat", filepath.Join(tmpdir, "b")).Run())
+
+	res1, err := tr1.Stop()
+	assert.NoError(t, err)
+	res2, err := tr2.Stop()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"/": {"a"}}, res1)
+	assert.Equal(t, map[string][]string{"/": {"b"}}, res2)
+}
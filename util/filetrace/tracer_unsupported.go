@@ -0,0 +1,15 @@
+// +build !linux !amd64,!arm64
+
+package filetrace
+
+import "github.com/pkg/errors"
+
+type Tracer struct{}
+
+func Start(dirs map[string]string, filter Filter) (*Tracer, error) {
+	return nil, errors.New("file access tracing is not supported on this platform")
+}
+
+func (t *Tracer) Stop() (map[string][]string, error) {
+	return nil, errors.New("file access tracing is not supported on this platform")
+}
@@ -0,0 +1,913 @@
+package fsimage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	blockSize      = 4096
+	blocksPerGroup = 8 * blockSize
+	inodeSize      = 256
+	inodeExtraSize = 32
+	inodesPerBlock = blockSize / inodeSize
+	ptrsPerBlock   = blockSize / 4
+	descSize       = 32
+
+	rootIno      = 2
+	lostFoundIno = 11
+	firstIno     = 11
+
+	maxNameLen    = 255
+	fastLinkLen   = 60
+	lostFoundName = "lost+found"
+
+	featureIncompatFiletype   = 0x2
+	featureROCompatSparse     = 0x1
+	featureROCompatLargeFile  = 0x2
+	featureROCompatExtraIsize = 0x40
+)
+
+type Opt struct {
+	// Size of the image in bytes. Defaults to the size of the contents with
+	// some free space.
+	Size int64
+	// Inodes is the total number of inodes. Defaults to the number of files
+	// with some headroom.
+	Inodes int
+	// Epoch is used for all timestamps if set. Together with a fixed tree
+	// this makes the output reproducible.
+	Epoch *time.Time
+	Label string
+}
+
+type inode struct {
+	ino     uint32
+	mode    uint32
+	uid     uint32
+	gid     uint32
+	size    uint64
+	mtime   time.Time
+	links   uint16
+	rdev    uint64
+	path    string
+	link    string
+	entries []dirent
+
+	dirData [][]byte
+	iblock  [15]uint32
+	nblocks uint64
+
+	xattrs  []byte // attribute block
+	fileACL uint32
+	holes   []blockRange
+}
+
+// blockRange is a range of blocks of a file
+type blockRange struct {
+	start uint64
+	n     uint64
+}
+
+type dirent struct {
+	name  string
+	ino   uint32
+	ftype uint8
+}
+
+type extent struct {
+	start uint64
+	n     uint64
+	write func(w io.Writer) error
+}
+
+// WriteExt4 writes a filesystem image of the directory tree at root to w.
+// The image uses the plain ext2 revision 1 layout (no journal, no extents),
+// which the ext2, ext3 and ext4 drivers all mount. The image is produced in a
+// single sequential pass so w does not need to be seekable.
+func WriteExt4(ctx context.Context, w io.Writer, root string, opt Opt) error {
+	inodes, err := scan(root)
+	if err != nil {
+		return err
+	}
+
+	var dataBlocks uint64
+	for _, in := range inodes {
+		if in == nil {
+			continue
+		}
+		if in.mode&syscall.S_IFMT == syscall.S_IFDIR {
+			in.dirData, err = dirBlocks(in.entries)
+			if err != nil {
+				return err
+			}
+			in.size = uint64(len(in.dirData)) * blockSize
+		}
+		n := in.dataBlocks()
+		dataBlocks += n - in.holeBlocks() + indirectBlocks(n)
+		if in.xattrs != nil {
+			dataBlocks++
+		}
+	}
+
+	inodeCount := uint64(len(inodes))
+	if opt.Inodes > 0 {
+		if uint64(opt.Inodes) < inodeCount {
+			return errors.Errorf("%d inodes requested but %d needed", opt.Inodes, inodeCount)
+		}
+		inodeCount = uint64(opt.Inodes)
+	} else {
+		inodeCount += inodeCount/10 + 16
+	}
+
+	l, err := newLayout(dataBlocks, inodeCount, opt.Size)
+	if err != nil {
+		return err
+	}
+
+	var extents []extent
+	for _, in := range inodes {
+		if in == nil {
+			continue
+		}
+		e, err := l.allocInode(in)
+		if err != nil {
+			return err
+		}
+		extents = append(extents, e...)
+	}
+
+	now := time.Now()
+	if opt.Epoch != nil {
+		now = *opt.Epoch
+		for _, in := range inodes {
+			if in != nil {
+				in.mtime = *opt.Epoch
+			}
+		}
+	}
+
+	sb := l.superblock(inodes, now, opt.Label)
+	gdt := l.groupDescriptors(inodes)
+	for g := uint32(0); g < l.groups; g++ {
+		extents = append(extents, l.groupExtents(g, sb, gdt, inodes)...)
+	}
+
+	sort.Slice(extents, func(i, j int) bool {
+		return extents[i].start < extents[j].start
+	})
+
+	var pos uint64
+	for _, e := range extents {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err := writeZeros(w, e.start-pos); err != nil {
+			return err
+		}
+		if err := e.write(w); err != nil {
+			return err
+		}
+		pos = e.start + e.n
+	}
+	return writeZeros(w, l.blocks-pos)
+}
+
+// scan walks the tree and returns inodes indexed by inode number - 1
+func scan(root string) ([]*inode, error) {
+	var inodes []*inode
+	dirs := map[string]*inode{}
+	hardlinks := map[uint64]*inode{}
+	next := uint32(firstIno + 1)
+
+	add := func(in *inode) {
+		for uint32(len(inodes)) < in.ino {
+			inodes = append(inodes, nil)
+		}
+		inodes[in.ino-1] = in
+	}
+
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			return errors.Errorf("unsupported stat type for %s", path)
+		}
+
+		if path == root {
+			in := newInode(rootIno, fi, st)
+			if err := in.readXattrs(path); err != nil {
+				return err
+			}
+			in.links = 2
+			in.entries = []dirent{{".", rootIno, ftype(in.mode)}, {"..", rootIno, ftype(in.mode)}}
+			dirs[path] = in
+			add(in)
+			return nil
+		}
+
+		name := filepath.Base(path)
+		if len(name) > maxNameLen {
+			return errors.Errorf("name too long: %s", path)
+		}
+		parent := dirs[filepath.Dir(path)]
+
+		if !fi.IsDir() && st.Nlink > 1 {
+			if in, ok := hardlinks[uint64(st.Ino)]; ok {
+				in.links++
+				parent.entries = append(parent.entries, dirent{name, in.ino, ftype(in.mode)})
+				return nil
+			}
+		}
+
+		ino := next
+		if parent.ino == rootIno && name == lostFoundName && fi.IsDir() {
+			ino = lostFoundIno
+		} else {
+			next++
+		}
+		in := newInode(ino, fi, st)
+
+		switch in.mode & syscall.S_IFMT {
+		case syscall.S_IFDIR:
+			in.links = 2
+			in.entries = []dirent{{".", ino, ftype(in.mode)}, {"..", parent.ino, ftype(parent.mode)}}
+			parent.links++
+			dirs[path] = in
+		case syscall.S_IFREG:
+			in.size = uint64(fi.Size())
+			in.path = path
+			if in.holes, err = fileHoles(path, fi.Size()); err != nil {
+				return err
+			}
+		case syscall.S_IFLNK:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if len(link) >= blockSize {
+				return errors.Errorf("symlink target too long: %s", path)
+			}
+			in.link = link
+			in.size = uint64(len(link))
+		case syscall.S_IFCHR, syscall.S_IFBLK:
+			in.rdev = uint64(st.Rdev)
+		}
+		if err := in.readXattrs(path); err != nil {
+			return err
+		}
+		if !fi.IsDir() && st.Nlink > 1 {
+			hardlinks[uint64(st.Ino)] = in
+		}
+
+		parent.entries = append(parent.entries, dirent{name, ino, ftype(in.mode)})
+		add(in)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inodes) < lostFoundIno || inodes[lostFoundIno-1] == nil {
+		root := inodes[rootIno-1]
+		in := &inode{
+			ino:   lostFoundIno,
+			mode:  syscall.S_IFDIR | 0700,
+			mtime: root.mtime,
+			links: 2,
+		}
+		in.entries = []dirent{{".", lostFoundIno, ftype(in.mode)}, {"..", rootIno, ftype(root.mode)}}
+		root.entries = append(root.entries, dirent{lostFoundName, lostFoundIno, ftype(in.mode)})
+		root.links++
+		add(in)
+	}
+	return inodes, nil
+}
+
+func newInode(ino uint32, fi os.FileInfo, st *syscall.Stat_t) *inode {
+	return &inode{
+		ino:   ino,
+		mode:  uint32(st.Mode),
+		uid:   st.Uid,
+		gid:   st.Gid,
+		mtime: fi.ModTime(),
+		links: 1,
+	}
+}
+
+func (in *inode) readXattrs(path string) error {
+	xattrs, err := readXattrs(path)
+	if err != nil || len(xattrs) == 0 {
+		return err
+	}
+	in.xattrs, err = xattrBlock(xattrs)
+	return errors.Wrapf(err, "failed to store xattrs of %s", path)
+}
+
+func ftype(mode uint32) uint8 {
+	switch mode & syscall.S_IFMT {
+	case syscall.S_IFREG:
+		return 1
+	case syscall.S_IFDIR:
+		return 2
+	case syscall.S_IFCHR:
+		return 3
+	case syscall.S_IFBLK:
+		return 4
+	case syscall.S_IFIFO:
+		return 5
+	case syscall.S_IFSOCK:
+		return 6
+	case syscall.S_IFLNK:
+		return 7
+	}
+	return 0
+}
+
+func (in *inode) dataBlocks() uint64 {
+	switch in.mode & syscall.S_IFMT {
+	case syscall.S_IFREG:
+		return (in.size + blockSize - 1) / blockSize
+	case syscall.S_IFDIR:
+		return uint64(len(in.dirData))
+	case syscall.S_IFLNK:
+		if len(in.link) >= fastLinkLen {
+			return 1
+		}
+	}
+	return 0
+}
+
+func (in *inode) holeBlocks() uint64 {
+	var n uint64
+	for _, h := range in.holes {
+		n += h.n
+	}
+	return n
+}
+
+// dirBlocks packs directory entries into blocks. The last entry of every
+// block is extended to the end of the block.
+func dirBlocks(entries []dirent) ([][]byte, error) {
+	var blocks [][]byte
+	var b []byte
+	var off, last int
+	for _, e := range entries {
+		l := (8 + len(e.name) + 3) &^ 3
+		if b == nil || off+l > blockSize {
+			if b != nil {
+				binary.LittleEndian.PutUint16(b[last+4:], uint16(blockSize-last))
+			}
+			b = make([]byte, blockSize)
+			blocks = append(blocks, b)
+			off = 0
+		}
+		binary.LittleEndian.PutUint32(b[off:], e.ino)
+		binary.LittleEndian.PutUint16(b[off+4:], uint16(l))
+		b[off+6] = uint8(len(e.name))
+		b[off+7] = e.ftype
+		copy(b[off+8:], e.name)
+		last = off
+		off += l
+	}
+	if b != nil {
+		binary.LittleEndian.PutUint16(b[last+4:], uint16(blockSize-last))
+	}
+	return blocks, nil
+}
+
+// indirectBlocks returns the number of indirect blocks needed to address n
+// data blocks
+func indirectBlocks(n uint64) uint64 {
+	const p = ptrsPerBlock
+	if n <= 12 {
+		return 0
+	}
+	n -= 12
+	if n <= p {
+		return 1
+	}
+	n -= p
+	if n <= p*p {
+		return 1 + 1 + (n+p-1)/p
+	}
+	n -= p * p
+	return 1 + 1 + p + 1 + (n+p*p-1)/(p*p) + (n+p-1)/p
+}
+
+type layout struct {
+	blocks    uint64
+	inodes    uint64
+	groups    uint32
+	ipg       uint32
+	itBlocks  uint32
+	gdtBlocks uint32
+	used      []uint32
+	cur       uint32
+}
+
+func newLayout(dataBlocks, inodes uint64, size int64) (*layout, error) {
+	blocks := uint64(size) / blockSize
+	if size <= 0 {
+		blocks = dataBlocks + dataBlocks/10 + 64
+	}
+	for {
+		l := &layout{blocks: blocks}
+		l.groups = uint32((blocks + blocksPerGroup - 1) / blocksPerGroup)
+		ipg := (inodes + uint64(l.groups) - 1) / uint64(l.groups)
+		ipg = (ipg + inodesPerBlock - 1) / inodesPerBlock * inodesPerBlock
+		l.ipg = uint32(ipg)
+		l.inodes = ipg * uint64(l.groups)
+		l.itBlocks = l.ipg / inodesPerBlock
+		l.gdtBlocks = (l.groups*descSize + blockSize - 1) / blockSize
+
+		var meta uint64
+		for g := uint32(0); g < l.groups; g++ {
+			meta += uint64(l.metaBlocks(g))
+		}
+		lastLen, lastMeta := l.groupLen(l.groups-1), l.metaBlocks(l.groups-1)
+
+		if ipg <= blocksPerGroup && meta+dataBlocks <= blocks && lastLen > lastMeta && l.inodes <= 1<<32-1 {
+			l.used = make([]uint32, l.groups)
+			for g := range l.used {
+				l.used[g] = l.metaBlocks(uint32(g))
+			}
+			return l, nil
+		}
+		if size > 0 {
+			return nil, errors.Errorf("image size %d is too small for %d blocks of data and %d inodes", size, dataBlocks, inodes)
+		}
+		next := meta + dataBlocks + (meta+dataBlocks)/10 + 64
+		if lastLen <= lastMeta {
+			next += uint64(lastMeta - lastLen + 64)
+		}
+		if ipg > blocksPerGroup {
+			next += blocksPerGroup
+		}
+		if next <= blocks {
+			next = blocks + 64
+		}
+		blocks = next
+	}
+}
+
+func hasSuper(g uint32) bool {
+	if g <= 1 {
+		return true
+	}
+	for _, p := range []uint32{3, 5, 7} {
+		n := p
+		for n < g {
+			n *= p
+		}
+		if n == g {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *layout) metaBlocks(g uint32) uint32 {
+	n := 2 + l.itBlocks
+	if hasSuper(g) {
+		n += 1 + l.gdtBlocks
+	}
+	return n
+}
+
+func (l *layout) groupLen(g uint32) uint32 {
+	if g == l.groups-1 {
+		return uint32(l.blocks - uint64(g)*blocksPerGroup)
+	}
+	return blocksPerGroup
+}
+
+// groupStart returns the first block of the group and the locations of the
+// block bitmap, inode bitmap and inode table
+func (l *layout) groupStart(g uint32) (start, bb, ib, it uint64) {
+	start = uint64(g) * blocksPerGroup
+	bb = start
+	if hasSuper(g) {
+		bb += 1 + uint64(l.gdtBlocks)
+	}
+	return start, bb, bb + 1, bb + 2
+}
+
+// alloc returns the next free block. Blocks are handed out sequentially so
+// the used blocks of every group are always a prefix of it.
+func (l *layout) alloc() (uint64, error) {
+	for ; l.cur < l.groups; l.cur++ {
+		if l.used[l.cur] < l.groupLen(l.cur) {
+			b := uint64(l.cur)*blocksPerGroup + uint64(l.used[l.cur])
+			l.used[l.cur]++
+			return b, nil
+		}
+	}
+	return 0, errors.New("no space left in image")
+}
+
+func (l *layout) allocInode(in *inode) ([]extent, error) {
+	var extents []extent
+	if in.xattrs != nil {
+		b, err := l.alloc()
+		if err != nil {
+			return nil, err
+		}
+		in.fileACL = uint32(b)
+		in.nblocks++
+		extents = append(extents, bytesExtent(b, in.xattrs))
+	}
+
+	n := in.dataBlocks()
+	if n == 0 {
+		if in.link != "" {
+			// fast symlinks keep the target in the block pointers
+			b := make([]byte, fastLinkLen)
+			copy(b, in.link)
+			for i := range in.iblock {
+				in.iblock[i] = binary.LittleEndian.Uint32(b[i*4:])
+			}
+		}
+		return extents, nil
+	}
+
+	// holes are left unallocated
+	data := make([]uint32, n)
+	holes := in.holes
+	for i := range data {
+		for len(holes) > 0 && holes[0].start+holes[0].n <= uint64(i) {
+			holes = holes[1:]
+		}
+		if len(holes) > 0 && holes[0].start <= uint64(i) {
+			continue
+		}
+		b, err := l.alloc()
+		if err != nil {
+			return nil, err
+		}
+		data[i] = uint32(b)
+	}
+
+	switch in.mode & syscall.S_IFMT {
+	case syscall.S_IFREG:
+		extents = append(extents, fileExtents(in, data)...)
+	case syscall.S_IFDIR:
+		for i, b := range data {
+			extents = append(extents, bytesExtent(uint64(b), in.dirData[i]))
+		}
+	case syscall.S_IFLNK:
+		b := make([]byte, blockSize)
+		copy(b, in.link)
+		extents = append(extents, bytesExtent(uint64(data[0]), b))
+	}
+
+	rest := data
+	for i := 0; i < 12 && len(rest) > 0; i++ {
+		in.iblock[i] = rest[0]
+		rest = rest[1:]
+	}
+	var ind []extent
+	for level := 1; level <= 3 && len(rest) > 0; level++ {
+		b, r, e, err := l.indirect(level, rest)
+		if err != nil {
+			return nil, err
+		}
+		in.iblock[11+level] = b
+		rest = r
+		ind = append(ind, e...)
+	}
+	if len(rest) > 0 {
+		return nil, errors.Errorf("file too large: %s", in.path)
+	}
+	in.nblocks += n - in.holeBlocks() + uint64(len(ind))
+	return append(extents, ind...), nil
+}
+
+func (l *layout) indirect(level int, data []uint32) (uint32, []uint32, []extent, error) {
+	b, err := l.alloc()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	buf := make([]byte, blockSize)
+	extents := []extent{bytesExtent(b, buf)}
+	for i := 0; i < ptrsPerBlock && len(data) > 0; i++ {
+		var p uint32
+		if level == 1 {
+			p = data[0]
+			data = data[1:]
+		} else {
+			var e []extent
+			p, data, e, err = l.indirect(level-1, data)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			extents = append(extents, e...)
+		}
+		binary.LittleEndian.PutUint32(buf[i*4:], p)
+	}
+	return uint32(b), data, extents, nil
+}
+
+// fileExtents splits the data blocks of a file into contiguous runs that are
+// copied from the source file
+func fileExtents(in *inode, data []uint32) []extent {
+	var extents []extent
+	for i := 0; i < len(data); {
+		if data[i] == 0 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(data) && data[j] == data[j-1]+1 {
+			j++
+		}
+		off := int64(i) * blockSize
+		n := uint64(j - i)
+		size := int64(n) * blockSize
+		if off+size > int64(in.size) {
+			size = int64(in.size) - off
+		}
+		path := in.path
+		extents = append(extents, extent{
+			start: uint64(data[i]),
+			n:     n,
+			write: func(w io.Writer) error {
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				if _, err := f.Seek(off, io.SeekStart); err != nil {
+					return err
+				}
+				if _, err := io.CopyN(w, f, size); err != nil {
+					return errors.Wrapf(err, "failed to copy %s", path)
+				}
+				return writePadding(w, uint64(size))
+			},
+		})
+		i = j
+	}
+	return extents
+}
+
+func (l *layout) superblock(inodes []*inode, now time.Time, label string) []byte {
+	var usedBlocks uint64
+	for _, u := range l.used {
+		usedBlocks += uint64(u)
+	}
+	usedInodes := uint64(len(inodes))
+	if usedInodes < firstIno {
+		usedInodes = firstIno
+	}
+
+	b := make([]byte, 1024)
+	le := binary.LittleEndian
+	le.PutUint32(b[0:], uint32(l.inodes))
+	le.PutUint32(b[4:], uint32(l.blocks))
+	le.PutUint32(b[12:], uint32(l.blocks-usedBlocks))
+	le.PutUint32(b[16:], uint32(l.inodes-usedInodes))
+	le.PutUint32(b[24:], 2) // 1024 << 2
+	le.PutUint32(b[28:], 2)
+	le.PutUint32(b[32:], blocksPerGroup)
+	le.PutUint32(b[36:], blocksPerGroup)
+	le.PutUint32(b[40:], l.ipg)
+	le.PutUint32(b[48:], uint32(now.Unix()))
+	le.PutUint16(b[54:], 0xffff)
+	le.PutUint16(b[56:], 0xef53)
+	le.PutUint16(b[58:], 1) // clean
+	le.PutUint16(b[60:], 1) // continue on errors
+	le.PutUint32(b[64:], uint32(now.Unix()))
+	le.PutUint32(b[76:], 1) // dynamic revision
+	le.PutUint32(b[84:], firstIno)
+	le.PutUint16(b[88:], inodeSize)
+	for _, in := range inodes {
+		if in != nil && in.xattrs != nil {
+			le.PutUint32(b[92:], featureCompatExtAttr)
+			break
+		}
+	}
+	le.PutUint32(b[96:], featureIncompatFiletype)
+	le.PutUint32(b[100:], featureROCompatSparse|featureROCompatLargeFile|featureROCompatExtraIsize)
+	copy(b[104:120], uuid(inodes, l))
+	copy(b[120:136], label)
+	le.PutUint32(b[264:], uint32(now.Unix()))
+	le.PutUint16(b[348:], inodeExtraSize)
+	le.PutUint16(b[350:], inodeExtraSize)
+	return b
+}
+
+// uuid is derived from the contents so that identical input gives an
+// identical image
+func uuid(inodes []*inode, l *layout) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d %d\n", l.blocks, l.inodes)
+	for _, in := range inodes {
+		if in != nil {
+			fmt.Fprintf(h, "%d %o %d %d %d %d\n", in.ino, in.mode, in.size, in.uid, in.gid, in.mtime.UnixNano())
+			for _, e := range in.entries {
+				fmt.Fprintf(h, "%s %d\n", e.name, e.ino)
+			}
+			h.Write(in.xattrs)
+		}
+	}
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+func (l *layout) groupDescriptors(inodes []*inode) []byte {
+	b := make([]byte, l.gdtBlocks*blockSize)
+	le := binary.LittleEndian
+	for g := uint32(0); g < l.groups; g++ {
+		_, bb, ib, it := l.groupStart(g)
+		usedInodes, dirs := l.groupInodes(g, inodes)
+		d := b[g*descSize:]
+		le.PutUint32(d[0:], uint32(bb))
+		le.PutUint32(d[4:], uint32(ib))
+		le.PutUint32(d[8:], uint32(it))
+		le.PutUint16(d[12:], uint16(l.groupLen(g)-l.used[g]))
+		le.PutUint16(d[14:], uint16(l.ipg-usedInodes))
+		le.PutUint16(d[16:], uint16(dirs))
+	}
+	return b
+}
+
+// groupInodes returns the number of used inodes and directories in a group.
+// Like blocks, used inodes are always a prefix of the group.
+func (l *layout) groupInodes(g uint32, inodes []*inode) (uint32, uint32) {
+	first := g * l.ipg
+	total := uint32(len(inodes))
+	if total < firstIno {
+		total = firstIno
+	}
+	if total <= first {
+		return 0, 0
+	}
+	used := total - first
+	if used > l.ipg {
+		used = l.ipg
+	}
+	var dirs uint32
+	for i := first; i < first+used && i < uint32(len(inodes)); i++ {
+		if in := inodes[i]; in != nil && in.mode&syscall.S_IFMT == syscall.S_IFDIR {
+			dirs++
+		}
+	}
+	return used, dirs
+}
+
+func (l *layout) groupExtents(g uint32, sb, gdt []byte, inodes []*inode) []extent {
+	start, bb, ib, it := l.groupStart(g)
+	var extents []extent
+
+	if hasSuper(g) {
+		b := make([]byte, blockSize)
+		s := b
+		if g == 0 {
+			s = b[1024:]
+		}
+		copy(s, sb)
+		binary.LittleEndian.PutUint16(s[90:], uint16(g))
+		extents = append(extents, bytesExtent(start, b))
+		extents = append(extents, extent{
+			start: start + 1,
+			n:     uint64(l.gdtBlocks),
+			write: func(w io.Writer) error {
+				_, err := w.Write(gdt)
+				return err
+			},
+		})
+	}
+
+	blockBitmap := make([]byte, blockSize)
+	setBits(blockBitmap, 0, l.used[g])
+	setBits(blockBitmap, l.groupLen(g), blocksPerGroup)
+	extents = append(extents, bytesExtent(bb, blockBitmap))
+
+	usedInodes, _ := l.groupInodes(g, inodes)
+	inodeBitmap := make([]byte, blockSize)
+	setBits(inodeBitmap, 0, usedInodes)
+	setBits(inodeBitmap, l.ipg, blocksPerGroup)
+	extents = append(extents, bytesExtent(ib, inodeBitmap))
+
+	extents = append(extents, extent{
+		start: it,
+		n:     uint64(l.itBlocks),
+		write: func(w io.Writer) error {
+			b := make([]byte, blockSize)
+			first := g * l.ipg
+			for i := uint32(0); i < l.itBlocks; i++ {
+				for j := range b {
+					b[j] = 0
+				}
+				for k := uint32(0); k < inodesPerBlock; k++ {
+					idx := first + i*inodesPerBlock + k
+					if idx < uint32(len(inodes)) && inodes[idx] != nil {
+						inodes[idx].marshal(b[k*inodeSize : (k+1)*inodeSize])
+					}
+				}
+				if _, err := w.Write(b); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	return extents
+}
+
+func (in *inode) marshal(b []byte) {
+	le := binary.LittleEndian
+	le.PutUint16(b[0:], uint16(in.mode))
+	le.PutUint16(b[2:], uint16(in.uid))
+	le.PutUint32(b[4:], uint32(in.size))
+	sec, extra := encodeTime(in.mtime)
+	for _, off := range []int{8, 12, 16} { // atime, ctime, mtime
+		le.PutUint32(b[off:], sec)
+	}
+	le.PutUint16(b[24:], uint16(in.gid))
+	le.PutUint16(b[26:], in.links)
+	le.PutUint32(b[28:], uint32(in.nblocks*(blockSize/512)))
+
+	switch in.mode & syscall.S_IFMT {
+	case syscall.S_IFCHR, syscall.S_IFBLK:
+		major, minor := (in.rdev>>8)&0xfff, (in.rdev&0xff)|((in.rdev>>12)&0xfff00)
+		if major < 256 && minor < 256 {
+			in.iblock[0] = uint32(major<<8 | minor)
+		} else {
+			in.iblock[1] = uint32(minor&0xff | major<<8 | (minor&^0xff)<<12)
+		}
+	}
+	for i, p := range in.iblock {
+		le.PutUint32(b[40+i*4:], p)
+	}
+
+	le.PutUint32(b[104:], in.fileACL)
+	le.PutUint32(b[108:], uint32(in.size>>32))
+	le.PutUint16(b[120:], uint16(in.uid>>16))
+	le.PutUint16(b[122:], uint16(in.gid>>16))
+	le.PutUint16(b[128:], inodeExtraSize)
+	for _, off := range []int{132, 136, 140} { // ctime, mtime, atime
+		le.PutUint32(b[off:], extra)
+	}
+}
+
+// encodeTime returns the low 32 bits of the seconds and the extra field
+// holding nanoseconds and the epoch bits
+func encodeTime(t time.Time) (uint32, uint32) {
+	sec := t.Unix()
+	epoch := uint32((sec-int64(int32(sec)))>>32) & 3
+	return uint32(sec), uint32(t.Nanosecond())<<2 | epoch
+}
+
+func bytesExtent(start uint64, b []byte) extent {
+	return extent{
+		start: start,
+		n:     uint64(len(b)) / blockSize,
+		write: func(w io.Writer) error {
+			_, err := w.Write(b)
+			return err
+		},
+	}
+}
+
+func setBits(b []byte, from, to uint32) {
+	for i := from; i < to; i++ {
+		b[i/8] |= 1 << (i % 8)
+	}
+}
+
+var zeroBlock = make([]byte, blockSize)
+
+func writeZeros(w io.Writer, blocks uint64) error {
+	for i := uint64(0); i < blocks; i++ {
+		if _, err := w.Write(zeroBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePadding(w io.Writer, size uint64) error {
+	if rem := size % blockSize; rem != 0 {
+		_, err := w.Write(zeroBlock[:blockSize-rem])
+		return err
+	}
+	return nil
+}
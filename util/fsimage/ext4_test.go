@@ -0,0 +1,150 @@
+package fsimage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/containerd/continuity/sysx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteExt4(t *testing.T) {
+	if _, err := exec.LookPath("e2fsck"); err != nil {
+		t.Skip("e2fsck not found")
+	}
+
+	tmpdir, err := ioutil.TempDir("", "fsimagetest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	src := filepath.Join(tmpdir, "src")
+	big := testTree(t, src)
+
+	img := filepath.Join(tmpdir, "img")
+	f, err := os.Create(img)
+	assert.NoError(t, err)
+	err = WriteExt4(context.TODO(), f, src, Opt{Label: "test"})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	out, err := exec.Command("e2fsck", "-fn", img).CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	if _, err := exec.LookPath("debugfs"); err == nil {
+		assert.Equal(t, "foo", debugfs(t, img, "cat /foo"))
+		assert.Equal(t, big, debugfs(t, img, "cat /dir/big"))
+		assert.Equal(t, "foo", debugfs(t, img, "cat /hardlink"))
+		assert.Contains(t, debugfs(t, img, "stat /hardlink"), "Links: 2")
+		assert.Contains(t, debugfs(t, img, "stat /symlink"), `Fast link dest: "foo"`)
+		assert.Equal(t, strings.Repeat("a", 100), strings.TrimRight(debugfs(t, img, "cat /longlink"), "\x00"))
+		assert.Contains(t, debugfs(t, img, "ls /many"), "file-199")
+
+		sparse := debugfs(t, img, "cat /sparse")
+		assert.Equal(t, 1<<20+3, len(sparse))
+		assert.True(t, strings.HasSuffix(sparse, "end"))
+		// one data and one indirect block
+		assert.Contains(t, debugfs(t, img, "stat /sparse"), "Blockcount: 16")
+
+		xattrs := debugfs(t, img, "ea_list /hardlink")
+		assert.Contains(t, xattrs, `user.foo (3) = "bar"`)
+		if os.Getuid() == 0 {
+			assert.Contains(t, xattrs, "security.capability (20)")
+		}
+	}
+}
+
+func TestWriteExt4Size(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "fsimagetest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	src := filepath.Join(tmpdir, "src")
+	testTree(t, src)
+
+	buf := bytes.NewBuffer(nil)
+	err = WriteExt4(context.TODO(), buf, src, Opt{Size: 64 << 20, Inodes: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, 64<<20, buf.Len())
+
+	err = WriteExt4(context.TODO(), buf, src, Opt{Size: 1 << 20})
+	assert.Error(t, err)
+
+	err = WriteExt4(context.TODO(), buf, src, Opt{Inodes: 5})
+	assert.Error(t, err)
+}
+
+func TestWriteExt4Deterministic(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "fsimagetest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	src := filepath.Join(tmpdir, "src")
+	testTree(t, src)
+
+	epoch := time.Unix(1500000000, 0)
+	var outs [][]byte
+	for i := 0; i < 2; i++ {
+		buf := bytes.NewBuffer(nil)
+		err := WriteExt4(context.TODO(), buf, src, Opt{Epoch: &epoch})
+		assert.NoError(t, err)
+		outs = append(outs, buf.Bytes())
+
+		// touching files must not change the output with a fixed epoch
+		assert.NoError(t, os.Chtimes(filepath.Join(src, "foo"), time.Now(), time.Now()))
+	}
+	assert.True(t, bytes.Equal(outs[0], outs[1]))
+}
+
+// testTree creates a tree with all supported file types and returns the
+// contents of the large file
+func testTree(t *testing.T, dir string) string {
+	write := func(p, dt string) {
+		p = filepath.Join(dir, p)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, ioutil.WriteFile(p, []byte(dt), 0644))
+	}
+
+	write("foo", "foo")
+	write("empty", "")
+	// large enough to need double indirect blocks
+	big := strings.Repeat("0123456789abcdef", 5<<16)
+	write("dir/big", big)
+	for i := 0; i < 200; i++ {
+		write(fmt.Sprintf("many/%s-%d", "file", i), strings.Repeat("x", i))
+	}
+	assert.NoError(t, os.Link(filepath.Join(dir, "foo"), filepath.Join(dir, "hardlink")))
+	assert.NoError(t, os.Symlink("foo", filepath.Join(dir, "symlink")))
+	assert.NoError(t, os.Symlink(strings.Repeat("a", 100), filepath.Join(dir, "longlink")))
+	assert.NoError(t, syscall.Mkfifo(filepath.Join(dir, "fifo"), 0600))
+	if os.Getuid() == 0 {
+		assert.NoError(t, syscall.Mknod(filepath.Join(dir, "null"), syscall.S_IFCHR|0666, 1<<8|3))
+		// cap_net_raw+ep
+		assert.NoError(t, sysx.LSetxattr(filepath.Join(dir, "foo"), "security.capability", []byte{1, 0, 0, 2, 0, 0x20, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, 0))
+	}
+	if err := sysx.LSetxattr(filepath.Join(dir, "foo"), "user.foo", []byte("bar"), 0); err != syscall.ENOTSUP {
+		assert.NoError(t, err)
+	}
+
+	// only the last block has data
+	f, err := os.Create(filepath.Join(dir, "sparse"))
+	assert.NoError(t, err)
+	_, err = f.WriteAt([]byte("end"), 1<<20)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	return big
+}
+
+func debugfs(t *testing.T, img, cmd string) string {
+	out, err := exec.Command("debugfs", "-R", cmd, img).Output()
+	assert.NoError(t, err)
+	return string(out)
+}
@@ -0,0 +1,53 @@
+package fsimage
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// fileHoles returns the blocks of a file that are not backed by data
+func fileHoles(path string, size int64) ([]blockRange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var holes []blockRange
+	add := func(start, end int64) {
+		first := uint64((start + blockSize - 1) / blockSize)
+		last := uint64(end / blockSize)
+		if end >= size {
+			last = uint64((size + blockSize - 1) / blockSize)
+		}
+		if last > first {
+			holes = append(holes, blockRange{first, last - first})
+		}
+	}
+
+	for off := int64(0); off < size; {
+		data, err := f.Seek(off, seekData)
+		if err != nil {
+			if pe, ok := err.(*os.PathError); ok && pe.Err == syscall.ENXIO {
+				add(off, size)
+				break
+			}
+			if pe, ok := err.(*os.PathError); ok && pe.Err == syscall.EINVAL {
+				return nil, nil // holes are not reported by the filesystem
+			}
+			return nil, err
+		}
+		if data > off {
+			add(off, data)
+		}
+		if off, err = f.Seek(data, seekHole); err != nil {
+			return nil, err
+		}
+	}
+	return holes, nil
+}
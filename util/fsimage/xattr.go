@@ -0,0 +1,179 @@
+package fsimage
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/continuity/sysx"
+	"github.com/pkg/errors"
+)
+
+const (
+	xattrMagic      = 0xea020000
+	xattrHeaderSize = 32
+	xattrEntrySize  = 16
+
+	featureCompatExtAttr = 0x8
+
+	aclXattrVersion = 2
+	aclExt4Version  = 1
+	aclUserObj      = 0x01
+	aclUser         = 0x02
+	aclGroupObj     = 0x04
+	aclGroup        = 0x08
+	aclMask         = 0x10
+	aclOther        = 0x20
+)
+
+// xattrIndexes are the name prefixes stored as an index in the entries.
+// ACLs are stored without a name.
+var xattrIndexes = []struct {
+	prefix string
+	index  uint8
+	acl    bool
+}{
+	{"user.", 1, false},
+	{"system.posix_acl_access", 2, true},
+	{"system.posix_acl_default", 3, true},
+	{"trusted.", 4, false},
+	{"security.", 6, false},
+}
+
+type xattr struct {
+	index uint8
+	name  string
+	value []byte
+}
+
+// readXattrs returns the extended attributes of a path in the order they
+// are stored in an attribute block
+func readXattrs(path string) ([]xattr, error) {
+	keys, err := sysx.LListxattr(path)
+	if err != nil {
+		if err == syscall.ENOTSUP {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to list xattrs of %s", path)
+	}
+	var xattrs []xattr
+	for _, k := range keys {
+		v, err := sysx.LGetxattr(path, k)
+		if err != nil {
+			if err == sysx.ENODATA {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get xattr %s of %s", k, path)
+		}
+		x, err := newXattr(k, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unsupported xattr on %s", path)
+		}
+		xattrs = append(xattrs, x)
+	}
+	sort.Slice(xattrs, func(i, j int) bool {
+		a, b := xattrs[i], xattrs[j]
+		if a.index != b.index {
+			return a.index < b.index
+		}
+		if len(a.name) != len(b.name) {
+			return len(a.name) < len(b.name)
+		}
+		return a.name < b.name
+	})
+	return xattrs, nil
+}
+
+func newXattr(key string, value []byte) (xattr, error) {
+	for _, x := range xattrIndexes {
+		if x.acl {
+			if key != x.prefix {
+				continue
+			}
+			v, err := ext4ACL(value)
+			if err != nil {
+				return xattr{}, err
+			}
+			return xattr{index: x.index, value: v}, nil
+		}
+		if strings.HasPrefix(key, x.prefix) && len(key) > len(x.prefix) {
+			return xattr{index: x.index, name: strings.TrimPrefix(key, x.prefix), value: value}, nil
+		}
+	}
+	return xattr{}, errors.Errorf("%s", key)
+}
+
+// ext4ACL converts an ACL from the xattr format to the shorter on-disk
+// format where only named entries keep an id
+func ext4ACL(v []byte) ([]byte, error) {
+	le := binary.LittleEndian
+	if len(v) < 4 || (len(v)-4)%8 != 0 || le.Uint32(v) != aclXattrVersion {
+		return nil, errors.New("invalid acl")
+	}
+	out := make([]byte, 4, len(v))
+	le.PutUint32(out, aclExt4Version)
+	for e := v[4:]; len(e) > 0; e = e[8:] {
+		switch le.Uint16(e) {
+		case aclUserObj, aclGroupObj, aclMask, aclOther:
+			out = append(out, e[:4]...)
+		case aclUser, aclGroup:
+			out = append(out, e[:8]...)
+		default:
+			return nil, errors.Errorf("invalid acl tag %d", le.Uint16(e))
+		}
+	}
+	return out, nil
+}
+
+// xattrBlock packs the attributes of an inode into a single attribute
+// block. Entries grow from the start of the block, values from the end.
+func xattrBlock(xattrs []xattr) ([]byte, error) {
+	le := binary.LittleEndian
+	b := make([]byte, blockSize)
+	le.PutUint32(b[0:], xattrMagic)
+	le.PutUint32(b[4:], 1) // refcount
+	le.PutUint32(b[8:], 1) // blocks
+
+	var blockHash uint32
+	zeroHash := false
+	off, valueOff := xattrHeaderSize, blockSize
+	for _, x := range xattrs {
+		entryLen := (xattrEntrySize + len(x.name) + 3) &^ 3
+		valueLen := (len(x.value) + 3) &^ 3
+		// entries are terminated by 4 zero bytes
+		if off+entryLen+4 > valueOff-valueLen {
+			return nil, errors.New("extended attributes don't fit in a block")
+		}
+		e := b[off:]
+		e[0] = uint8(len(x.name))
+		e[1] = x.index
+		if len(x.value) > 0 {
+			valueOff -= valueLen
+			copy(b[valueOff:], x.value)
+			le.PutUint16(e[2:], uint16(valueOff))
+		}
+		le.PutUint32(e[8:], uint32(len(x.value)))
+		hash := xattrHash(x.name, b[valueOff:valueOff+valueLen])
+		le.PutUint32(e[12:], hash)
+		copy(e[xattrEntrySize:], x.name)
+		blockHash = blockHash<<16 ^ blockHash>>16 ^ hash
+		zeroHash = zeroHash || hash == 0
+		off += entryLen
+	}
+	if !zeroHash {
+		le.PutUint32(b[12:], blockHash)
+	}
+	return b, nil
+}
+
+func xattrHash(name string, value []byte) uint32 {
+	var hash uint32
+	for i := 0; i < len(name); i++ {
+		hash = hash<<5 ^ hash>>27 ^ uint32(name[i])
+	}
+	for v := value; len(v) >= 4; v = v[4:] {
+		hash = hash<<16 ^ hash>>16 ^ binary.LittleEndian.Uint32(v)
+	}
+	return hash
+}
@@ -0,0 +1,161 @@
+package llbsign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// Digest returns the digest a definition is signed over. Ops refer to their
+// inputs by digest so the digest of the last op covers the whole graph.
+func Digest(def [][]byte) (digest.Digest, error) {
+	if len(def) == 0 {
+		return "", errors.New("invalid empty definition")
+	}
+	return digest.FromBytes(def[len(def)-1]), nil
+}
+
+// FrontendDigest returns the digest a request built by a frontend is signed
+// over, as it has no definition. It covers the frontend name and all of its
+// options, which are encoded sorted by key.
+func FrontendDigest(frontend string, opt map[string]string) digest.Digest {
+	dt, _ := json.Marshal(struct {
+		Frontend string            `json:"frontend"`
+		Opt      map[string]string `json:"opt,omitempty"`
+	}{frontend, opt})
+	return digest.FromBytes(dt)
+}
+
+// Sign returns a detached signature for a definition. The signature is made
+// over the SHA-256 hash of the definition digest string.
+func Sign(def [][]byte, key crypto.Signer) ([]byte, error) {
+	dgst, err := Digest(def)
+	if err != nil {
+		return nil, err
+	}
+	return SignDigest(dgst, key)
+}
+
+// SignDigest returns a detached signature for a digest returned by Digest or
+// FrontendDigest
+func SignDigest(dgst digest.Digest, key crypto.Signer) ([]byte, error) {
+	return key.Sign(rand.Reader, hash(dgst), crypto.SHA256)
+}
+
+func hash(dgst digest.Digest) []byte {
+	h := sha256.Sum256([]byte(dgst.String()))
+	return h[:]
+}
+
+// Verifier checks definition signatures against a set of trusted public keys
+type Verifier struct {
+	keys map[string]crypto.PublicKey
+}
+
+// LoadVerifier reads PEM encoded ECDSA or RSA public keys. The identity of a
+// key is its file name without the extension.
+func LoadVerifier(paths []string) (*Verifier, error) {
+	v := &Verifier{keys: make(map[string]crypto.PublicKey)}
+	for _, p := range paths {
+		dt, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read key %s", p)
+		}
+		block, _ := pem.Decode(dt)
+		if block == nil {
+			return nil, errors.Errorf("no PEM data in %s", p)
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse key %s", p)
+		}
+		switch pub.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, errors.Errorf("unsupported key type %T in %s", pub, p)
+		}
+		name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		if _, ok := v.keys[name]; ok {
+			return nil, errors.Errorf("duplicate key identity %s", name)
+		}
+		v.keys[name] = pub
+	}
+	return v, nil
+}
+
+// Has returns true if a key with the identity is trusted
+func (v *Verifier) Has(name string) bool {
+	_, ok := v.keys[name]
+	return ok
+}
+
+// Verify returns the identity of the key that signed the definition
+func (v *Verifier) Verify(def [][]byte, sig []byte) (string, error) {
+	dgst, err := Digest(def)
+	if err != nil {
+		return "", err
+	}
+	return v.VerifyDigest(dgst, sig)
+}
+
+// VerifyDigest returns the identity of the key that signed the digest
+func (v *Verifier) VerifyDigest(dgst digest.Digest, sig []byte) (string, error) {
+	if len(sig) == 0 {
+		return "", errors.New("request is not signed")
+	}
+	h := hash(dgst)
+	for name, pub := range v.keys {
+		switch pub := pub.(type) {
+		case *ecdsa.PublicKey:
+			var s struct{ R, S *big.Int }
+			if _, err := asn1.Unmarshal(sig, &s); err == nil && ecdsa.Verify(pub, h, s.R, s.S) {
+				return name, nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(pub, crypto.SHA256, h, sig) == nil {
+				return name, nil
+			}
+		}
+	}
+	return "", errors.New("signature does not match any trusted key")
+}
+
+// LoadSigner reads a PEM encoded ECDSA or RSA private key
+func LoadSigner(p string) (crypto.Signer, error) {
+	dt, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read key %s", p)
+	}
+	block, _ := pem.Decode(dt)
+	if block == nil {
+		return nil, errors.Errorf("no PEM data in %s", p)
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse key %s", p)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported key type %T in %s", key, p)
+	}
+	return signer, nil
+}
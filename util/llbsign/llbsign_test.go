@@ -0,0 +1,94 @@
+package llbsign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "llbsign")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	eckey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	ecdt, err := x509.MarshalECPrivateKey(eckey)
+	assert.NoError(t, err)
+	writeKey(t, filepath.Join(tmpdir, "ci.key"), "EC PRIVATE KEY", ecdt)
+	writePublicKey(t, filepath.Join(tmpdir, "ci.pem"), eckey.Public())
+
+	rsakey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	writeKey(t, filepath.Join(tmpdir, "frontend.key"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsakey))
+	writePublicKey(t, filepath.Join(tmpdir, "frontend.pem"), rsakey.Public())
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	v, err := LoadVerifier([]string{filepath.Join(tmpdir, "ci.pem"), filepath.Join(tmpdir, "frontend.pem")})
+	assert.NoError(t, err)
+	assert.True(t, v.Has("ci"))
+	assert.False(t, v.Has("other"))
+
+	def := [][]byte{[]byte("foo"), []byte("bar")}
+
+	for _, name := range []string{"ci", "frontend"} {
+		signer, err := LoadSigner(filepath.Join(tmpdir, name+".key"))
+		assert.NoError(t, err)
+		sig, err := Sign(def, signer)
+		assert.NoError(t, err)
+
+		identity, err := v.Verify(def, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, name, identity)
+
+		_, err = v.Verify([][]byte{[]byte("foo"), []byte("baz")}, sig)
+		assert.Error(t, err)
+	}
+
+	sig, err := Sign(def, other)
+	assert.NoError(t, err)
+	_, err = v.Verify(def, sig)
+	assert.Error(t, err)
+
+	_, err = v.Verify(def, nil)
+	assert.Error(t, err)
+
+	// frontend requests are signed over the frontend and its options
+	dgst := FrontendDigest("dockerfile", map[string]string{"target": "foo", "filename": "Dockerfile"})
+	assert.Equal(t, dgst, FrontendDigest("dockerfile", map[string]string{"filename": "Dockerfile", "target": "foo"}))
+	assert.NotEqual(t, dgst, FrontendDigest("dockerfile", map[string]string{"target": "foo"}))
+	assert.NotEqual(t, dgst, FrontendDigest("other", map[string]string{"target": "foo", "filename": "Dockerfile"}))
+	sig, err = SignDigest(dgst, eckey)
+	assert.NoError(t, err)
+	identity, err := v.VerifyDigest(dgst, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", identity)
+	_, err = v.VerifyDigest(FrontendDigest("dockerfile", nil), sig)
+	assert.Error(t, err)
+
+	_, err = LoadVerifier([]string{filepath.Join(tmpdir, "ci.key")})
+	assert.Error(t, err)
+}
+
+func writeKey(t *testing.T, p, typ string, dt []byte) {
+	err := ioutil.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: dt}), 0600)
+	assert.NoError(t, err)
+}
+
+func writePublicKey(t *testing.T, p string, pub crypto.PublicKey) {
+	dt, err := x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	writeKey(t, p, "PUBLIC KEY", dt)
+}
@@ -0,0 +1,66 @@
+package peercred
+
+import (
+	"net"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// AuthInfo contains the credentials of the process on the other end of a
+// unix socket connection
+type AuthInfo struct {
+	UID uint32
+	GID uint32
+	PID int32
+}
+
+func (AuthInfo) AuthType() string {
+	return "peercred"
+}
+
+// FromContext returns the credentials of the peer of a gRPC request if the
+// server uses the credentials of NewCredentials
+func FromContext(ctx context.Context) (AuthInfo, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return AuthInfo{}, false
+	}
+	ai, ok := p.AuthInfo.(AuthInfo)
+	return ai, ok
+}
+
+// NewCredentials returns gRPC server credentials that read the credentials
+// of the peer process of unix socket connections. Connections are not
+// changed, so clients connect without transport security.
+func NewCredentials() credentials.TransportCredentials {
+	return creds{}
+}
+
+type creds struct{}
+
+func (creds) ClientHandshake(ctx context.Context, addr string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, nil, nil
+}
+
+func (creds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	ai, err := getPeerCred(conn)
+	if err != nil {
+		// not a unix socket, the peer is unknown
+		return conn, nil, nil
+	}
+	return conn, ai, nil
+}
+
+func (creds) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+func (c creds) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (creds) OverrideServerName(string) error {
+	return nil
+}
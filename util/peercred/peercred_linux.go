@@ -0,0 +1,32 @@
+package peercred
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+func getPeerCred(conn net.Conn) (AuthInfo, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return AuthInfo{}, errors.New("not a unix socket")
+	}
+	rc, err := uc.SyscallConn()
+	if err != nil {
+		return AuthInfo{}, err
+	}
+	var (
+		cred    *unix.Ucred
+		credErr error
+	)
+	if err := rc.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return AuthInfo{}, err
+	}
+	if credErr != nil {
+		return AuthInfo{}, errors.Wrap(credErr, "failed to get peer credentials")
+	}
+	return AuthInfo{UID: cred.Uid, GID: cred.Gid, PID: cred.Pid}, nil
+}
@@ -0,0 +1,37 @@
+package peercred
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPeerCred(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "peercredtest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	l, err := net.Listen("unix", filepath.Join(tmpdir, "sock"))
+	assert.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		conn, err := net.Dial("unix", filepath.Join(tmpdir, "sock"))
+		if err == nil {
+			defer conn.Close()
+			conn.Read(make([]byte, 1))
+		}
+	}()
+
+	conn, err := l.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, ai, err := NewCredentials().ServerHandshake(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, AuthInfo{UID: uint32(os.Getuid()), GID: uint32(os.Getgid()), PID: int32(os.Getpid())}, ai)
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package peercred
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+func getPeerCred(conn net.Conn) (AuthInfo, error) {
+	return AuthInfo{}, errors.New("peer credentials are not supported on this platform")
+}
@@ -0,0 +1,220 @@
+package pgzip
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// pgzip is a gzip writer that splits the input into fixed size blocks and
+// deflates them concurrently. Every block is primed with the tail of the
+// previous block as a dictionary and all but the last one end with a sync
+// flush, so the concatenated output is a single ordinary gzip member that any
+// gzip reader can decompress.
+
+const (
+	// DefaultBlockSize is the amount of uncompressed data deflated by a single
+	// worker
+	DefaultBlockSize = 1 << 20
+
+	dictSize = 32 << 10
+)
+
+var errClosed = errors.New("pgzip: write to closed writer")
+
+type Writer struct {
+	w           io.Writer
+	level       int
+	blockSize   int
+	wroteHeader bool
+	closed      bool
+
+	buf  []byte
+	dict []byte
+	crc  uint32
+	size uint32
+
+	queue chan *block
+	done  chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+type block struct {
+	data []byte
+	dict []byte
+	last bool
+	out  bytes.Buffer
+	err  error
+	done chan struct{}
+}
+
+// NewWriter returns a writer using the default compression level and one
+// worker per CPU
+func NewWriter(w io.Writer) *Writer {
+	z, _ := NewWriterLevel(w, gzip.DefaultCompression, runtime.NumCPU())
+	return z
+}
+
+// NewWriterLevel returns a writer that compresses with the given level using
+// at most concurrency blocks in flight
+func NewWriterLevel(w io.Writer, level, concurrency int) (*Writer, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return nil, errors.Errorf("pgzip: invalid compression level: %d", level)
+	}
+	if concurrency < 1 {
+		return nil, errors.Errorf("pgzip: invalid concurrency: %d", concurrency)
+	}
+	z := &Writer{
+		w:         w,
+		level:     level,
+		blockSize: DefaultBlockSize,
+		queue:     make(chan *block, concurrency-1), // flushLoop holds one more
+		done:      make(chan struct{}),
+	}
+	z.buf = make([]byte, 0, z.blockSize)
+	go z.flushLoop()
+	return z, nil
+}
+
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.closed {
+		return 0, errClosed
+	}
+	if err := z.getErr(); err != nil {
+		return 0, err
+	}
+	z.crc = crc32.Update(z.crc, crc32.IEEETable, p)
+	z.size += uint32(len(p))
+	n := 0
+	for len(p) > 0 {
+		l := z.blockSize - len(z.buf)
+		if l > len(p) {
+			l = len(p)
+		}
+		z.buf = append(z.buf, p[:l]...)
+		p = p[l:]
+		n += l
+		if len(z.buf) == z.blockSize {
+			z.dispatch(false)
+		}
+	}
+	return n, nil
+}
+
+// Close compresses any pending data, waits for all workers and writes the
+// gzip trailer. It does not close the underlying writer.
+func (z *Writer) Close() error {
+	if z.closed {
+		return nil
+	}
+	z.closed = true
+	z.dispatch(true)
+	close(z.queue)
+	<-z.done
+	if err := z.getErr(); err != nil {
+		return err
+	}
+	var trailer [8]byte
+	binary.LittleEndian.PutUint32(trailer[:4], z.crc)
+	binary.LittleEndian.PutUint32(trailer[4:], z.size)
+	_, err := z.w.Write(trailer[:])
+	return err
+}
+
+func (z *Writer) dispatch(last bool) {
+	b := &block{
+		data: z.buf,
+		dict: z.dict,
+		last: last,
+		done: make(chan struct{}),
+	}
+	if len(z.buf) >= dictSize {
+		z.dict = z.buf[len(z.buf)-dictSize:]
+	} else {
+		z.dict = append(append([]byte{}, z.dict...), z.buf...)
+		if len(z.dict) > dictSize {
+			z.dict = z.dict[len(z.dict)-dictSize:]
+		}
+	}
+	z.buf = make([]byte, 0, z.blockSize)
+	z.queue <- b // blocks when concurrency limit is reached
+	go b.compress(z.level)
+}
+
+func (b *block) compress(level int) {
+	defer close(b.done)
+	fw, err := flate.NewWriterDict(&b.out, level, b.dict)
+	if err != nil {
+		b.err = err
+		return
+	}
+	if _, err := fw.Write(b.data); err != nil {
+		b.err = err
+		return
+	}
+	if b.last {
+		b.err = fw.Close()
+	} else {
+		b.err = fw.Flush()
+	}
+}
+
+// flushLoop writes compressed blocks to the underlying writer in the same
+// order they were dispatched
+func (z *Writer) flushLoop() {
+	defer close(z.done)
+	for b := range z.queue {
+		<-b.done
+		if z.getErr() != nil {
+			continue
+		}
+		if b.err != nil {
+			z.setErr(errors.Wrap(b.err, "pgzip: failed to compress block"))
+			continue
+		}
+		if !z.wroteHeader {
+			z.wroteHeader = true
+			if _, err := z.w.Write(z.header()); err != nil {
+				z.setErr(err)
+				continue
+			}
+		}
+		if _, err := z.w.Write(b.out.Bytes()); err != nil {
+			z.setErr(err)
+		}
+	}
+}
+
+// header matches the one written by compress/gzip without a name or mtime
+func (z *Writer) header() []byte {
+	h := []byte{0x1f, 0x8b, 8, 0, 0, 0, 0, 0, 0, 255}
+	if z.level == gzip.BestCompression {
+		h[8] = 2
+	} else if z.level == gzip.BestSpeed {
+		h[8] = 4
+	}
+	return h
+}
+
+func (z *Writer) getErr() error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	return z.err
+}
+
+func (z *Writer) setErr(err error) {
+	z.mu.Lock()
+	if z.err == nil {
+		z.err = err
+	}
+	z.mu.Unlock()
+}
@@ -0,0 +1,157 @@
+package pgzip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, dictSize - 1, dictSize + 1, 3*DefaultBlockSize + 123} {
+		dt := testData(size)
+
+		buf := bytes.NewBuffer(nil)
+		z, err := NewWriterLevel(buf, gzip.DefaultCompression, 4)
+		assert.NoError(t, err)
+
+		// uneven writes to cross block boundaries
+		for p := dt; len(p) > 0; {
+			n := 7777
+			if n > len(p) {
+				n = len(p)
+			}
+			_, err := z.Write(p[:n])
+			assert.NoError(t, err)
+			p = p[n:]
+		}
+		err = z.Close()
+		assert.NoError(t, err)
+
+		gr, err := gzip.NewReader(buf)
+		assert.NoError(t, err)
+		out, err := ioutil.ReadAll(gr)
+		assert.NoError(t, err)
+		assert.Equal(t, len(dt), len(out))
+		assert.True(t, bytes.Equal(dt, out))
+		assert.NoError(t, gr.Close())
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	dt := testData(5*DefaultBlockSize + 17)
+
+	var outs [][]byte
+	for _, c := range []int{1, 2, 8} {
+		buf := bytes.NewBuffer(nil)
+		z, err := NewWriterLevel(buf, gzip.BestSpeed, c)
+		assert.NoError(t, err)
+		_, err = z.Write(dt)
+		assert.NoError(t, err)
+		assert.NoError(t, z.Close())
+		outs = append(outs, buf.Bytes())
+	}
+	assert.Equal(t, outs[0], outs[1])
+	assert.Equal(t, outs[0], outs[2])
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	for _, c := range []int{1, 3} {
+		unblock := make(chan struct{})
+		buf := bytes.NewBuffer(nil)
+		z, err := NewWriterLevel(&blockingWriter{w: buf, unblock: unblock}, gzip.BestSpeed, c)
+		assert.NoError(t, err)
+		z.blockSize = 1024
+
+		dt := testData(10 * z.blockSize)
+		var written int32
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for p := dt; len(p) > 0; p = p[z.blockSize:] {
+				_, err := z.Write(p[:z.blockSize])
+				assert.NoError(t, err)
+				atomic.AddInt32(&written, 1)
+			}
+		}()
+
+		// every write fills a block, the next one waits for a free slot
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(c), atomic.LoadInt32(&written))
+
+		close(unblock)
+		<-done
+		assert.NoError(t, z.Close())
+
+		gr, err := gzip.NewReader(buf)
+		assert.NoError(t, err)
+		out, err := ioutil.ReadAll(gr)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(dt, out))
+	}
+}
+
+// blockingWriter blocks writes until unblock is closed
+type blockingWriter struct {
+	w       io.Writer
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	return w.w.Write(p)
+}
+
+func TestInvalidOptions(t *testing.T) {
+	_, err := NewWriterLevel(ioutil.Discard, 10, 1)
+	assert.Error(t, err)
+	_, err = NewWriterLevel(ioutil.Discard, gzip.BestSpeed, 0)
+	assert.Error(t, err)
+
+	z := NewWriter(ioutil.Discard)
+	assert.NoError(t, z.Close())
+	_, err = z.Write([]byte("foo"))
+	assert.Error(t, err)
+}
+
+func BenchmarkGzip(b *testing.B) {
+	dt := testData(32 << 20)
+	b.SetBytes(int64(len(dt)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z := gzip.NewWriter(ioutil.Discard)
+		z.Write(dt)
+		z.Close()
+	}
+}
+
+func BenchmarkPgzip(b *testing.B) {
+	dt := testData(32 << 20)
+	b.SetBytes(int64(len(dt)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z := NewWriter(ioutil.Discard)
+		z.Write(dt)
+		z.Close()
+	}
+}
+
+// testData returns compressible data that isn't trivially repetitive
+func testData(size int) []byte {
+	r := rand.New(rand.NewSource(int64(size)))
+	words := []string{"foo", "bar", "baz", "buildkit", "layer", "snapshot", "\n", " "}
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	for buf.Len() < size {
+		buf.WriteString(words[r.Intn(len(words))])
+		if r.Intn(10) == 0 {
+			buf.WriteByte(byte(r.Intn(256)))
+		}
+	}
+	return buf.Bytes()[:size]
+}
@@ -0,0 +1,215 @@
+package rebase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/util/convert"
+)
+
+// Rebase replaces the base layers of an image. The layers of oldBase must be
+// the first layers of img. The layers of img on top of them are reused as
+// is on top of the layers of newBase. The new manifest and config are
+// written to the content store.
+func Rebase(ctx context.Context, cs content.Store, img, oldBase, newBase ocispec.Descriptor) (ocispec.Descriptor, error) {
+	im, err := readImage(ctx, cs, img)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	ob, err := readImage(ctx, cs, oldBase)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	nb, err := readImage(ctx, cs, newBase)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if err := checkPrefix(ob, im); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if nb.config.OS != im.config.OS || nb.config.Architecture != im.config.Architecture {
+		return ocispec.Descriptor{}, errors.Errorf("new base is %s/%s, image is %s/%s", nb.config.OS, nb.config.Architecture, im.config.OS, im.config.Architecture)
+	}
+
+	n := len(ob.manifest.Layers)
+	layers := append(append([]ocispec.Descriptor{}, nb.manifest.Layers...), im.manifest.Layers[n:]...)
+
+	rootfs := ocispec.RootFS{
+		Type:    "layers",
+		DiffIDs: append(append([]digest.Digest{}, nb.config.RootFS.DiffIDs...), im.config.RootFS.DiffIDs[n:]...),
+	}
+	history := append([]ocispec.History{}, nb.config.History...)
+	if len(im.config.History) >= len(ob.config.History) {
+		history = append(history, im.config.History[len(ob.config.History):]...)
+	}
+
+	// unknown fields of the image config and manifest are kept
+	configDesc := im.manifest.Config
+	if err := setFields(im.rawConfig, map[string]interface{}{
+		"rootfs":  rootfs,
+		"history": history,
+	}); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if configDesc, err = writeJSON(ctx, cs, configDesc.MediaType, im.rawConfig); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if err := setFields(im.rawManifest, map[string]interface{}{
+		"config": configDesc,
+		"layers": layers,
+	}); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return writeJSON(ctx, cs, img.MediaType, im.rawManifest)
+}
+
+type image struct {
+	manifest    ocispec.Manifest
+	config      ocispec.Image
+	rawManifest map[string]*json.RawMessage
+	rawConfig   map[string]*json.RawMessage
+}
+
+func readImage(ctx context.Context, p content.Provider, desc ocispec.Descriptor) (*image, error) {
+	switch desc.MediaType {
+	case images.MediaTypeDockerSchema2Manifest, ocispec.MediaTypeImageManifest:
+	default:
+		return nil, errors.Errorf("unsupported manifest type %s", desc.MediaType)
+	}
+	var im image
+	dt, err := content.ReadBlob(ctx, p, desc.Digest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest %s", desc.Digest)
+	}
+	if err := json.Unmarshal(dt, &im.manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest %s", desc.Digest)
+	}
+	if err := json.Unmarshal(dt, &im.rawManifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest %s", desc.Digest)
+	}
+	dt, err = content.ReadBlob(ctx, p, im.manifest.Config.Digest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config %s", im.manifest.Config.Digest)
+	}
+	if err := json.Unmarshal(dt, &im.config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config %s", im.manifest.Config.Digest)
+	}
+	if err := json.Unmarshal(dt, &im.rawConfig); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config %s", im.manifest.Config.Digest)
+	}
+	if len(im.config.RootFS.DiffIDs) != len(im.manifest.Layers) {
+		return nil, errors.Errorf("mismatched rootfs and manifest layers in %s", desc.Digest)
+	}
+	return &im, nil
+}
+
+func checkPrefix(base, im *image) error {
+	if len(base.manifest.Layers) > len(im.manifest.Layers) {
+		return errors.Errorf("old base has %d layers, image only %d", len(base.manifest.Layers), len(im.manifest.Layers))
+	}
+	for i, l := range base.manifest.Layers {
+		if l.Digest != im.manifest.Layers[i].Digest || base.config.RootFS.DiffIDs[i] != im.config.RootFS.DiffIDs[i] {
+			return errors.Errorf("layer %d of the image is %s, old base has %s", i, im.manifest.Layers[i].Digest, l.Digest)
+		}
+	}
+	return nil
+}
+
+func setFields(m map[string]*json.RawMessage, fields map[string]interface{}) error {
+	for k, v := range fields {
+		dt, err := json.Marshal(v)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s", k)
+		}
+		raw := json.RawMessage(dt)
+		m[k] = &raw
+	}
+	return nil
+}
+
+func writeJSON(ctx context.Context, cs content.Ingester, mediaType string, v interface{}) (ocispec.Descriptor, error) {
+	dt, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(dt),
+		Size:      int64(len(dt)),
+	}
+	if err := content.WriteBlob(ctx, cs, "rebase-"+desc.Digest.String(), bytes.NewReader(dt), desc.Size, desc.Digest); err != nil {
+		return ocispec.Descriptor{}, errors.Wrapf(err, "failed to write %s", desc.Digest)
+	}
+	return desc, nil
+}
+
+// Fetch resolves an image reference and stores its manifest and config in
+// the content store. The layers are only fetched if layers is set.
+func Fetch(ctx context.Context, cs content.Store, resolver remotes.Resolver, ref string, layers bool) (ocispec.Descriptor, error) {
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	children := images.ChildrenHandler(cs)
+	handler := images.Handlers(
+		remotes.FetchHandler(cs, fetcher),
+		images.HandlerFunc(func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+			switch desc.MediaType {
+			case images.MediaTypeDockerSchema2Manifest, ocispec.MediaTypeImageManifest:
+			default:
+				return nil, nil
+			}
+			descs, err := children(ctx, desc)
+			if err != nil {
+				return nil, err
+			}
+			if !layers {
+				descs = descs[:1]
+			}
+			return descs, nil
+		}),
+	)
+	if err := images.Dispatch(ctx, handler, desc); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// RebaseRemote fetches the images, rebases image onto newBase and pushes the
+// result to target if it is set. The layers of the new base are always
+// fetched, the layers of the image only when they are pushed.
+func RebaseRemote(ctx context.Context, cs content.Store, resolver remotes.Resolver, image, oldBase, newBase, target string) (ocispec.Descriptor, error) {
+	var descs [3]ocispec.Descriptor
+	for i, ref := range []string{image, oldBase, newBase} {
+		layers := i == 2 || target != "" && i == 0
+		desc, err := Fetch(ctx, cs, resolver, ref, layers)
+		if err != nil {
+			return ocispec.Descriptor{}, errors.Wrapf(err, "failed to fetch %s", ref)
+		}
+		descs[i] = desc
+	}
+
+	desc, err := Rebase(ctx, cs, descs[0], descs[1], descs[2])
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if target != "" {
+		if err := convert.Push(ctx, cs, resolver, target, desc); err != nil {
+			return ocispec.Descriptor{}, errors.Wrapf(err, "failed to push %s", target)
+		}
+	}
+	return desc, nil
+}
@@ -0,0 +1,167 @@
+package rebase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebase(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "rebase")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cs, err := content.NewStore(tmpdir)
+	assert.NoError(t, err)
+
+	oldBase := writeImage(t, cs, []string{"base1", "base2"}, nil)
+	newBase := writeImage(t, cs, []string{"base1", "base2-patched", "base3"}, nil)
+	img := writeImage(t, cs, []string{"base1", "base2", "app1", "app2"}, map[string]interface{}{"Env": []string{"FOO=bar"}})
+
+	desc, err := Rebase(ctx, cs, img, oldBase, newBase)
+	assert.NoError(t, err)
+	assert.Equal(t, images.MediaTypeDockerSchema2Manifest, desc.MediaType)
+
+	im, err := readImage(ctx, cs, desc)
+	assert.NoError(t, err)
+	var layers []digest.Digest
+	for _, l := range im.manifest.Layers {
+		layers = append(layers, l.Digest)
+	}
+	assert.Equal(t, []digest.Digest{blob("base1"), blob("base2-patched"), blob("base3"), blob("app1"), blob("app2")}, layers)
+	assert.Equal(t, []digest.Digest{diffID("base1"), diffID("base2-patched"), diffID("base3"), diffID("app1"), diffID("app2")}, im.config.RootFS.DiffIDs)
+	assert.Equal(t, 5, len(im.config.History))
+	assert.Equal(t, "app1", im.config.History[3].CreatedBy)
+	assert.Equal(t, []string{"FOO=bar"}, im.config.Config.Env)
+	assert.Equal(t, `"value"`, string(*im.rawConfig["unknown"]))
+
+	_, err = Rebase(ctx, cs, img, newBase, oldBase)
+	assert.Error(t, err)
+	_, err = Rebase(ctx, cs, oldBase, img, newBase)
+	assert.Error(t, err)
+}
+
+func TestRebaseRemote(t *testing.T) {
+	ctx := context.TODO()
+	tmpdir, err := ioutil.TempDir("", "rebase")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	registry, err := content.NewStore(filepath.Join(tmpdir, "registry"))
+	assert.NoError(t, err)
+	r := &testResolver{cs: registry, refs: map[string]ocispec.Descriptor{
+		"old": writeImage(t, registry, []string{"base1", "base2"}, nil),
+		"new": writeImage(t, registry, []string{"base1", "base2-patched", "base3"}, nil),
+		"img": writeImage(t, registry, []string{"base1", "base2", "app1", "app2"}, nil),
+	}}
+
+	cs, err := content.NewStore(filepath.Join(tmpdir, "local"))
+	assert.NoError(t, err)
+	_, err = RebaseRemote(ctx, cs, r, "img", "old", "new", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(r.pushed))
+	for _, l := range []string{"base1", "base2-patched", "base3"} {
+		_, err := cs.Info(ctx, blob(l))
+		assert.NoError(t, err, l)
+	}
+	_, err = cs.Info(ctx, blob("app1"))
+	assert.Error(t, err)
+
+	desc, err := RebaseRemote(ctx, cs, r, "img", "old", "new", "target")
+	assert.NoError(t, err)
+	im, err := readImage(ctx, cs, desc)
+	assert.NoError(t, err)
+	expected := []digest.Digest{im.manifest.Config.Digest, blob("base1"), blob("base2-patched"), blob("base3"), blob("app1"), blob("app2"), desc.Digest}
+	assert.Equal(t, len(expected), len(r.pushed))
+	for _, dgst := range expected {
+		assert.Contains(t, r.pushed, dgst)
+	}
+}
+
+// testResolver serves the images of a content store and records pushes
+type testResolver struct {
+	cs     content.Store
+	refs   map[string]ocispec.Descriptor
+	pushed []digest.Digest
+}
+
+func (r *testResolver) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	desc, ok := r.refs[ref]
+	if !ok {
+		return "", ocispec.Descriptor{}, errors.Errorf("%s not found", ref)
+	}
+	return ref, desc, nil
+}
+
+func (r *testResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return remotes.FetcherFunc(func(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+		return r.cs.Reader(ctx, desc.Digest)
+	}), nil
+}
+
+func (r *testResolver) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return r, nil
+}
+
+func (r *testResolver) Push(ctx context.Context, desc ocispec.Descriptor, rd io.Reader) error {
+	if _, err := io.Copy(ioutil.Discard, rd); err != nil {
+		return err
+	}
+	r.pushed = append(r.pushed, desc.Digest)
+	return nil
+}
+
+func blob(name string) digest.Digest {
+	return digest.FromString("blob-" + name)
+}
+
+func diffID(name string) digest.Digest {
+	return digest.FromString("diff-" + name)
+}
+
+func writeImage(t *testing.T, cs content.Store, layers []string, config map[string]interface{}) ocispec.Descriptor {
+	var (
+		diffIDs []digest.Digest
+		history []ocispec.History
+		descs   []ocispec.Descriptor
+	)
+	for _, l := range layers {
+		diffIDs = append(diffIDs, diffID(l))
+		history = append(history, ocispec.History{CreatedBy: l})
+		dt := []byte("blob-" + l)
+		assert.NoError(t, content.WriteBlob(context.TODO(), cs, "layer-"+l, bytes.NewReader(dt), int64(len(dt)), blob(l)))
+		descs = append(descs, ocispec.Descriptor{MediaType: images.MediaTypeDockerSchema2LayerGzip, Digest: blob(l), Size: int64(len(dt))})
+	}
+	raw := json.RawMessage(`"value"`)
+	configDesc, err := writeJSON(context.TODO(), cs, images.MediaTypeDockerSchema2Config, map[string]interface{}{
+		"architecture": "amd64",
+		"os":           "linux",
+		"config":       config,
+		"rootfs":       ocispec.RootFS{Type: "layers", DiffIDs: diffIDs},
+		"history":      history,
+		"unknown":      &raw,
+	})
+	assert.NoError(t, err)
+	desc, err := writeJSON(context.TODO(), cs, images.MediaTypeDockerSchema2Manifest, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     images.MediaTypeDockerSchema2Manifest,
+		"config":        configDesc,
+		"layers":        descs,
+	})
+	assert.NoError(t, err)
+	return desc
+}
@@ -0,0 +1,60 @@
+package resourceusage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
+)
+
+// Usage is the resource usage of an exec step sampled from its cgroup
+type Usage struct {
+	Vertex digest.Digest
+	// CPU is the total cpu time of the processes of the step
+	CPU time.Duration
+	// Memory is the peak memory usage in bytes
+	Memory uint64
+}
+
+func (u Usage) String() string {
+	return fmt.Sprintf("cpu time %v, peak memory %.1f MiB", u.CPU, float64(u.Memory)/(1<<20))
+}
+
+type Recorder interface {
+	RecordUsage(Usage)
+}
+
+type recorderKeyT string
+
+var recorderKey = recorderKeyT("buildkit/util/resourceusage")
+
+// WithRecorder sets the recorder the usage of the exec steps run with the
+// context is sent to
+func WithRecorder(ctx context.Context, r Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey, r)
+}
+
+// Record sends the usage of a step to the recorder of the context. If the
+// vertex is empty the usage is sent for every warnings vertex of the
+// context.
+func Record(ctx context.Context, u Usage) {
+	r, ok := ctx.Value(recorderKey).(Recorder)
+	if !ok {
+		return
+	}
+	if u.Vertex != "" {
+		r.RecordUsage(u)
+		return
+	}
+	dgsts := warnings.Vertices(ctx)
+	if len(dgsts) == 0 {
+		r.RecordUsage(u)
+		return
+	}
+	for _, dgst := range dgsts {
+		u.Vertex = dgst
+		r.RecordUsage(u)
+	}
+}
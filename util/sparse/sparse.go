@@ -0,0 +1,112 @@
+package sparse
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/containerd/containerd/archive"
+)
+
+// blockSize is the granularity of the holes that are restored
+const blockSize = 4096
+
+// paxSparse marks the tar entries of files that were sparse when they were
+// written
+const paxSparse = "BUILDKIT.sparse"
+
+// Mark marks the tar header of a sparse file so that Apply restores its
+// holes
+func Mark(hdr *tar.Header) {
+	if hdr.PAXRecords == nil {
+		hdr.PAXRecords = map[string]string{}
+	}
+	hdr.PAXRecords[paxSparse] = "1"
+}
+
+// Apply extracts a layer tar stream with archive.Apply. Tar streams don't
+// keep holes, so runs of zero blocks in the files marked with Mark are
+// turned back into holes afterwards and sparse files keep their allocated
+// size when they go through a layer. Files that were not sparse stay fully
+// allocated.
+func Apply(ctx context.Context, root string, r io.Reader) (int64, error) {
+	pr, pw := io.Pipe()
+	done := make(chan map[string][]hole, 1)
+	go func() {
+		holes, err := scanHoles(pr)
+		if err != nil {
+			holes = nil
+		}
+		// archive.Apply can stop before the end of the stream
+		io.Copy(ioutil.Discard, pr)
+		done <- holes
+	}()
+
+	n, err := archive.Apply(ctx, root, io.TeeReader(r, pw))
+	pw.CloseWithError(err)
+	holes := <-done
+	if err != nil {
+		return n, err
+	}
+
+	for name, hs := range holes {
+		if err := punchHoles(root, name, hs); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+type hole struct {
+	off, len int64
+}
+
+// scanHoles returns the aligned runs of zero blocks of the marked regular
+// files in a tar stream. Later entries replace earlier ones with the same
+// name.
+func scanHoles(r io.Reader) (map[string][]hole, error) {
+	holes := map[string][]hole{}
+	tr := tar.NewReader(r)
+	buf := make([]byte, blockSize)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return holes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Clean(hdr.Name)
+		delete(holes, name)
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA || hdr.Size < blockSize || hdr.PAXRecords[paxSparse] == "" {
+			continue
+		}
+
+		var hs []hole
+		for off := int64(0); off+blockSize <= hdr.Size; off += blockSize {
+			if _, err := io.ReadFull(tr, buf); err != nil {
+				return nil, err
+			}
+			if !isZero(buf) {
+				continue
+			}
+			if l := len(hs); l > 0 && hs[l-1].off+hs[l-1].len == off {
+				hs[l-1].len += blockSize
+			} else {
+				hs = append(hs, hole{off: off, len: blockSize})
+			}
+		}
+		if len(hs) > 0 {
+			holes[name] = hs
+		}
+	}
+}
+
+var zeroBlock = make([]byte, blockSize)
+
+func isZero(b []byte) bool {
+	return bytes.Equal(b, zeroBlock)
+}
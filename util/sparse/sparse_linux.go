@@ -0,0 +1,83 @@
+package sparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// IsSparse returns true if fewer blocks are allocated for a regular file
+// than its size needs
+func IsSparse(fi os.FileInfo) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && fi.Mode().IsRegular() && st.Blocks*512 < fi.Size()
+}
+
+// punchHoles deallocates the holes of a file extracted to root. The
+// modification time of the file is kept.
+func punchHoles(root, name string, holes []hole) error {
+	p, err := safePath(root, name)
+	if err != nil || p == "" {
+		return err
+	}
+	fi, err := os.Lstat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // removed by a later whiteout
+		}
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil
+	}
+	st := fi.Sys().(*syscall.Stat_t)
+
+	fd, err := unix.Open(p, unix.O_WRONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", name)
+	}
+	for _, h := range holes {
+		if err := unix.Fallocate(fd, unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, h.off, h.len); err != nil {
+			unix.Close(fd)
+			if err == unix.EOPNOTSUPP {
+				return nil // the filesystem can't store holes
+			}
+			return errors.Wrapf(err, "failed to punch hole in %s", name)
+		}
+	}
+	if err := unix.Close(fd); err != nil {
+		return err
+	}
+	atime := time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec))
+	return os.Chtimes(p, atime, fi.ModTime())
+}
+
+// safePath returns the path of name under root, or an empty path if one of
+// its parents is a symlink
+func safePath(root, name string) (string, error) {
+	name = strings.TrimPrefix(filepath.Clean("/"+name), "/")
+	if name == "" {
+		return "", nil
+	}
+	p := root
+	parts := strings.Split(name, "/")
+	for _, part := range parts[:len(parts)-1] {
+		p = filepath.Join(p, part)
+		fi, err := os.Lstat(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", nil
+			}
+			return "", err
+		}
+		if !fi.IsDir() {
+			return "", nil
+		}
+	}
+	return filepath.Join(p, parts[len(parts)-1]), nil
+}
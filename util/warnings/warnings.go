@@ -0,0 +1,104 @@
+package warnings
+
+import (
+	"context"
+	"fmt"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Warning is a problem found during a build that does not fail it
+type Warning struct {
+	Vertex   digest.Digest
+	Severity Severity
+	Message  string
+	// Filename and Line optionally point to the source the vertex was
+	// generated from
+	Filename string
+	Line     int
+}
+
+type Writer interface {
+	Warn(Warning)
+}
+
+type writerKeyT string
+type vertexKeyT string
+
+var (
+	writerKey = writerKeyT("buildkit/util/warnings")
+	vertexKey = vertexKeyT("buildkit/util/warnings/vertex")
+)
+
+func WithWriter(ctx context.Context, w Writer) context.Context {
+	return context.WithValue(ctx, writerKey, w)
+}
+
+// WithVertex associates warnings written with the context to a vertex. A
+// vertex that stands for several vertices of the definition is set with all
+// their digests.
+func WithVertex(ctx context.Context, dgsts ...digest.Digest) context.Context {
+	return context.WithValue(ctx, vertexKey, dgsts)
+}
+
+// Warn sends a warning for the current vertex. It is a no-op if there is no
+// writer in the context.
+func Warn(ctx context.Context, s Severity, format string, args ...interface{}) {
+	Write(ctx, Warning{Severity: s, Message: fmt.Sprintf(format, args...)})
+}
+
+// Write sends a warning, setting the vertex from the context if empty. The
+// warning is sent for every vertex set in the context.
+func Write(ctx context.Context, w Warning) {
+	wr, ok := ctx.Value(writerKey).(Writer)
+	if !ok {
+		return
+	}
+	if w.Vertex != "" {
+		wr.Warn(w)
+		return
+	}
+	dgsts := Vertices(ctx)
+	if len(dgsts) == 0 {
+		wr.Warn(w)
+		return
+	}
+	for _, dgst := range dgsts {
+		w.Vertex = dgst
+		wr.Warn(w)
+	}
+}
+
+// Vertex returns the first vertex set with WithVertex
+func Vertex(ctx context.Context) digest.Digest {
+	if dgsts := Vertices(ctx); len(dgsts) > 0 {
+		return dgsts[0]
+	}
+	return ""
+}
+
+// Vertices returns the vertices set with WithVertex
+func Vertices(ctx context.Context) []digest.Digest {
+	dgsts, _ := ctx.Value(vertexKey).([]digest.Digest)
+	return dgsts
+}
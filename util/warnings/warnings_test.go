@@ -0,0 +1,45 @@
+package warnings
+
+import (
+	"context"
+	"testing"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+type collector []Warning
+
+func (c *collector) Warn(w Warning) {
+	*c = append(*c, w)
+}
+
+func TestWarn(t *testing.T) {
+	ctx := context.TODO()
+	Warn(ctx, SeverityWarning, "no writer")
+
+	var c collector
+	ctx = WithWriter(ctx, &c)
+	Warn(ctx, SeverityInfo, "no vertex")
+
+	dgst := digest.FromBytes([]byte("foo"))
+	ctx = WithVertex(ctx, dgst)
+	Warn(ctx, SeverityWarning, "%s is not pinned", "busybox")
+	Write(ctx, Warning{Vertex: "sha256:bar", Severity: SeverityCritical, Message: "other", Filename: "Dockerfile", Line: 3})
+
+	assert.Equal(t, 3, len(c))
+	assert.Equal(t, Warning{Severity: SeverityInfo, Message: "no vertex"}, c[0])
+	assert.Equal(t, Warning{Vertex: dgst, Severity: SeverityWarning, Message: "busybox is not pinned"}, c[1])
+	assert.Equal(t, digest.Digest("sha256:bar"), c[2].Vertex)
+	assert.Equal(t, "critical", c[2].Severity.String())
+
+	// merged vertices get a warning each
+	c = nil
+	other := digest.FromBytes([]byte("bar"))
+	ctx = WithVertex(ctx, dgst, other)
+	Warn(ctx, SeverityWarning, "merged")
+	assert.Equal(t, 2, len(c))
+	assert.Equal(t, dgst, c[0].Vertex)
+	assert.Equal(t, other, c[1].Vertex)
+	assert.Equal(t, dgst, Vertex(ctx))
+}
@@ -0,0 +1,58 @@
+package oci
+
+import (
+	"os"
+	"syscall"
+
+	specs "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// addDevice creates the host device p in the container and allows it in the
+// device cgroup
+func addDevice(s *specs.Spec, p string) error {
+	var st syscall.Stat_t
+	if err := syscall.Stat(p, &st); err != nil {
+		return errors.Wrapf(err, "failed to stat device %s", p)
+	}
+	var typ string
+	switch st.Mode & syscall.S_IFMT {
+	case syscall.S_IFCHR:
+		typ = "c"
+	case syscall.S_IFBLK:
+		typ = "b"
+	default:
+		return errors.Errorf("%s is not a device", p)
+	}
+	major, minor := int64(devMajor(uint64(st.Rdev))), int64(devMinor(uint64(st.Rdev)))
+	mode := os.FileMode(st.Mode &^ syscall.S_IFMT)
+
+	s.Linux.Devices = append(s.Linux.Devices, specs.LinuxDevice{
+		Path:     p,
+		Type:     typ,
+		Major:    major,
+		Minor:    minor,
+		FileMode: &mode,
+		UID:      &st.Uid,
+		GID:      &st.Gid,
+	})
+	if s.Linux.Resources == nil {
+		s.Linux.Resources = &specs.LinuxResources{}
+	}
+	s.Linux.Resources.Devices = append(s.Linux.Resources.Devices, specs.LinuxDeviceCgroup{
+		Allow:  true,
+		Type:   typ,
+		Major:  &major,
+		Minor:  &minor,
+		Access: "rwm",
+	})
+	return nil
+}
+
+func devMajor(dev uint64) uint64 {
+	return (dev>>8)&0xfff | (dev>>32)&^0xfff
+}
+
+func devMinor(dev uint64) uint64 {
+	return dev&0xff | (dev>>12)&^0xff
+}
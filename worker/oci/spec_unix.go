@@ -20,6 +20,12 @@ func GenerateSpec(meta worker.Meta, mounts map[string]cache.Mountable) (*specs.S
 	s.Process.Cwd = meta.Cwd
 	// TODO: User
 
+	for _, p := range meta.Devices {
+		if err := addDevice(s, p); err != nil {
+			return nil, err
+		}
+	}
+
 	for dest, m := range mounts {
 		if dest == "/" {
 			continue
@@ -0,0 +1,114 @@
+package runcworker
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	runc "github.com/containerd/go-runc"
+	"github.com/pkg/errors"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"golang.org/x/net/context"
+)
+
+// statsInterval is how often the cgroup stats of a running container are
+// sampled
+const statsInterval = 500 * time.Millisecond
+
+// cgroupsPath returns the cgroup of the container with the id under the
+// parent. A parent in the systemd form slice[:prefix] places the container
+// in the scope prefix-id.scope of the slice, like runc does for a
+// slice:prefix:name cgroups path. As runc uses the cgroupfs driver the scope
+// is expanded to its cgroupfs path.
+func cgroupsPath(parent, id string) (string, error) {
+	if parent == "" {
+		return "", nil
+	}
+	parts := strings.Split(parent, ":")
+	if !strings.HasSuffix(parts[0], ".slice") || strings.Contains(parts[0], "/") {
+		if len(parts) > 1 {
+			return "", errors.Errorf("invalid cgroup parent %q, expected a cgroup path or slice[:prefix]", parent)
+		}
+		return path.Join(parent, "buildkit-"+id), nil
+	}
+	if len(parts) > 2 {
+		return "", errors.Errorf("invalid cgroup parent %q, expected a cgroup path or slice[:prefix]", parent)
+	}
+	prefix := "buildkit"
+	if len(parts) == 2 && parts[1] != "" {
+		prefix = parts[1]
+	}
+	p, err := expandSlice(parts[0])
+	if err != nil {
+		return "", err
+	}
+	return path.Join(p, prefix+"-"+id+".scope"), nil
+}
+
+// expandSlice returns the cgroupfs path of a systemd slice. Every dash
+// starts a child slice, a-b.slice is /a.slice/a-b.slice.
+func expandSlice(slice string) (string, error) {
+	name := strings.TrimSuffix(slice, ".slice")
+	if name == "-" {
+		return "/", nil
+	}
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") || strings.Contains(name, "--") {
+		return "", errors.Errorf("invalid slice name %q", slice)
+	}
+	p := "/"
+	var parent string
+	for _, c := range strings.Split(name, "-") {
+		if parent != "" {
+			parent += "-"
+		}
+		parent += c
+		p = path.Join(p, parent+".slice")
+	}
+	return p, nil
+}
+
+// addStats adds a sample of the stats of a container to its usage
+func addStats(u *resourceusage.Usage, s *runc.Stats) {
+	if cpu := time.Duration(s.Cpu.Usage.Total); cpu > u.CPU {
+		u.CPU = cpu
+	}
+	for _, m := range []uint64{s.Memory.Usage.Usage, s.Memory.Usage.Max} {
+		if m > u.Memory {
+			u.Memory = m
+		}
+	}
+}
+
+// sampleUsage reads the stats of the container until the returned function
+// is called. The function returns the usage, or nil if the container was
+// never sampled. The cgroup is removed when the container exits, so the
+// usage after the last sample is missing.
+func (w *runcworker) sampleUsage(ctx context.Context, id string) func() *resourceusage.Usage {
+	var u *resourceusage.Usage
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(statsInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			// fails until the container has been created
+			if s, err := w.runc.Stats(ctx, id); err == nil {
+				if u == nil {
+					u = &resourceusage.Usage{}
+				}
+				addStats(u, s)
+			}
+		}
+	}()
+	return func() *resourceusage.Usage {
+		cancel()
+		<-done
+		return u
+	}
+}
@@ -0,0 +1,45 @@
+package runcworker
+
+import (
+	"testing"
+	"time"
+
+	runc "github.com/containerd/go-runc"
+	"github.com/stretchr/testify/assert"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+)
+
+func TestCgroupsPath(t *testing.T) {
+	for parent, expected := range map[string]string{
+		"":                      "",
+		"/buildkit":             "/buildkit/buildkit-id",
+		"buildkit":              "buildkit/buildkit-id",
+		"/system.slice/foo":     "/system.slice/foo/buildkit-id",
+		"buildkit.slice":        "/buildkit.slice/buildkit-id.scope",
+		"build-kit.slice:steps": "/build.slice/build-kit.slice/steps-id.scope",
+		"-.slice:steps":         "/steps-id.scope",
+	} {
+		p, err := cgroupsPath(parent, "id")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, p, parent)
+	}
+
+	for _, parent := range []string{"buildkit.slice:a:b", "/buildkit:a", "-buildkit.slice", "build--kit.slice", ".slice"} {
+		_, err := cgroupsPath(parent, "id")
+		assert.Error(t, err, parent)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	u := &resourceusage.Usage{}
+	s := &runc.Stats{}
+	s.Cpu.Usage.Total = uint64(time.Second)
+	s.Memory.Usage.Usage = 3 << 20
+	s.Memory.Usage.Max = 4 << 20
+	addStats(u, s)
+	s = &runc.Stats{}
+	s.Cpu.Usage.Total = uint64(2 * time.Second)
+	s.Memory.Usage.Usage = 1 << 20
+	addStats(u, s)
+	assert.Equal(t, "cpu time 2s, peak memory 4.0 MiB", u.String())
+}
@@ -0,0 +1,114 @@
+package runcworker
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// proxyNamespace is a network namespace that only has a loopback interface.
+// The egress proxy listens inside it so it is the only way out of the
+// container.
+type proxyNamespace struct {
+	handle   *os.File // keeps the namespace alive
+	listener net.Listener
+}
+
+func newProxyNamespace(addr string) (*proxyNamespace, error) {
+	type result struct {
+		ns  *proxyNamespace
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		// the thread is not unlocked if switching back fails so that it
+		// exits with the goroutine instead of running other code
+		runtime.LockOSThread()
+		ns, restored, err := createProxyNamespace(addr)
+		if restored {
+			runtime.UnlockOSThread()
+		}
+		ch <- result{ns, err}
+	}()
+	r := <-ch
+	return r.ns, r.err
+}
+
+func createProxyNamespace(addr string) (ns *proxyNamespace, restored bool, retErr error) {
+	origin, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()))
+	if err != nil {
+		return nil, true, errors.Wrap(err, "failed to open network namespace")
+	}
+	defer origin.Close()
+
+	if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+		return nil, true, errors.Wrap(err, "failed to create network namespace")
+	}
+	defer func() {
+		if err := unix.Setns(int(origin.Fd()), unix.CLONE_NEWNET); err != nil {
+			if ns != nil {
+				ns.Close()
+				ns = nil
+			}
+			retErr = errors.Wrap(err, "failed to restore network namespace")
+			return
+		}
+		restored = true
+	}()
+
+	handle, err := os.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()))
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to open network namespace")
+	}
+	if err := loopbackUp(); err != nil {
+		handle.Close()
+		return nil, false, err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		handle.Close()
+		return nil, false, errors.Wrapf(err, "failed to listen on %s", addr)
+	}
+	return &proxyNamespace{handle: handle, listener: l}, false, nil
+}
+
+// Path can be used as the network namespace path in the OCI spec
+func (ns *proxyNamespace) Path() string {
+	return fmt.Sprintf("/proc/%d/fd/%d", os.Getpid(), ns.handle.Fd())
+}
+
+func (ns *proxyNamespace) Close() error {
+	err := ns.listener.Close()
+	if err1 := ns.handle.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+func loopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to create socket")
+	}
+	defer unix.Close(fd)
+
+	var ifr struct {
+		name  [unix.IFNAMSIZ]byte
+		flags uint16
+		_     [22]byte
+	}
+	copy(ifr.name[:], "lo")
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCGIFFLAGS, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return errors.Wrap(errno, "failed to get loopback flags")
+	}
+	ifr.flags |= unix.IFF_UP
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return errors.Wrap(errno, "failed to bring up loopback")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package runcworker
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyNamespace(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+
+	ns, err := newProxyNamespace(proxyAddr)
+	assert.NoError(t, err)
+	defer ns.Close()
+
+	_, err = os.Stat(ns.Path())
+	assert.NoError(t, err)
+
+	// the listener is only reachable from inside the namespace
+	host, err := os.Readlink(fmt.Sprintf("/proc/self/task/%d/ns/net", os.Getpid()))
+	assert.NoError(t, err)
+	inner, err := os.Readlink(ns.Path())
+	assert.NoError(t, err)
+	assert.NotEqual(t, host, inner)
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err == nil {
+		conn.Close()
+	}
+	assert.Error(t, err)
+}
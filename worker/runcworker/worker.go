@@ -5,27 +5,58 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"syscall"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/containerd/containerd/mount"
 	runc "github.com/containerd/go-runc"
+	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 	"github.com/tonistiigi/buildkit_poc/cache"
+	"github.com/tonistiigi/buildkit_poc/util/egressproxy"
+	"github.com/tonistiigi/buildkit_poc/util/filetrace"
+	"github.com/tonistiigi/buildkit_poc/util/resourceusage"
+	"github.com/tonistiigi/buildkit_poc/util/warnings"
 	"github.com/tonistiigi/buildkit_poc/worker"
 	"github.com/tonistiigi/buildkit_poc/worker/oci"
 	"golang.org/x/net/context"
 )
 
+type Opt struct {
+	Root string
+	// CgroupParent is the cgroup all containers are created under. Limits set
+	// on it apply to all builds together. A systemd slice can be set as
+	// slice[:prefix], e.g. buildkit.slice, to create a scope per container.
+	// The resource usage of every step is reported if it is set.
+	CgroupParent string
+	// EgressAllow enables network access through a proxy that only reaches
+	// the listed hosts. Containers have no network if it is empty. Solves
+	// with an egressproxy.Scope use the hosts of the scope instead.
+	EgressAllow []string
+	// HTTPCache enables the proxy with caching of plain HTTP downloads. In
+	// record mode all hosts can be reached if EgressAllow is empty. In
+	// replay mode no hosts need to be allowed.
+	HTTPCache *egressproxy.Cache
+}
+
+// proxyAddr is where the egress proxy listens inside the containers
+const proxyAddr = "127.0.0.1:3128"
+
 type runcworker struct {
-	runc *runc.Runc
-	root string
+	runc         *runc.Runc
+	root         string
+	cgroupParent string
+	egressAllow  []string
+	httpCache    *egressproxy.Cache
 }
 
-func New(root string) (worker.Worker, error) {
+func New(opt Opt) (worker.Worker, error) {
+	root := opt.Root
 	if err := os.MkdirAll(root, 0700); err != nil {
 		return nil, errors.Wrapf(err, "failed to create %s", root)
 	}
@@ -36,6 +67,10 @@ func New(root string) (worker.Worker, error) {
 	}
 	// TODO: check that root is not symlink to fail early
 
+	if _, err := cgroupsPath(opt.CgroupParent, ""); err != nil {
+		return nil, err
+	}
+
 	runtime := &runc.Runc{
 		Log:          filepath.Join(root, "runc-log.json"),
 		LogFormat:    runc.JSON,
@@ -43,8 +78,11 @@ func New(root string) (worker.Worker, error) {
 	}
 
 	w := &runcworker{
-		runc: runtime,
-		root: root,
+		runc:         runtime,
+		root:         root,
+		cgroupParent: opt.CgroupParent,
+		egressAllow:  opt.EgressAllow,
+		httpCache:    opt.HTTPCache,
 	}
 	return w, nil
 }
@@ -87,6 +125,24 @@ func (w *runcworker) Exec(ctx context.Context, meta worker.Meta, mounts map[stri
 	}
 	defer mount.Unmount(rootFSPath, 0)
 	spec.Root.Path = rootFSPath
+	// every container gets its own cgroup so usage stays per step
+	if spec.Linux.CgroupsPath, err = cgroupsPath(w.cgroupParent, id); err != nil {
+		return err
+	}
+
+	ns, release, err := w.proxy(ctx)
+	if err != nil {
+		return err
+	}
+	if ns != nil {
+		defer release()
+		for i, n := range spec.Linux.Namespaces {
+			if n.Type == specs.NetworkNamespace {
+				spec.Linux.Namespaces[i].Path = ns.Path()
+			}
+		}
+		spec.Process.Env = append(spec.Process.Env, egressproxy.Env(proxyAddr)...)
+	}
 	if _, ok := root.(cache.ImmutableRef); ok {
 		spec.Root.Readonly = true
 	}
@@ -95,12 +151,30 @@ func (w *runcworker) Exec(ctx context.Context, meta worker.Meta, mounts map[stri
 		return err
 	}
 
+	var tracer *fileTracer
+	if filetrace.Enabled(ctx) {
+		tracer = startTracer(ctx, id, rootFSPath, mounts)
+	}
+
+	var stopSampling func() *resourceusage.Usage
+	if w.cgroupParent != "" {
+		stopSampling = w.sampleUsage(ctx, id)
+	}
+
 	logrus.Debugf("> running %s %v", id, meta.Args)
 
 	status, err := w.runc.Run(ctx, id, bundle, &runc.CreateOpts{
 		IO: &forwardIO{stdout: stdout, stderr: stderr},
 	})
 	logrus.Debugf("< completed %s %v %v", id, status, err)
+	if stopSampling != nil {
+		if u := stopSampling(); u != nil {
+			resourceusage.Record(ctx, *u)
+		}
+	}
+	if tracer != nil {
+		tracer.stop(ctx)
+	}
 	if status != 0 {
 		return errors.Errorf("exit code %d", status)
 	}
@@ -108,6 +182,127 @@ func (w *runcworker) Exec(ctx context.Context, meta worker.Meta, mounts map[stri
 	return err
 }
 
+type fileTracer struct {
+	*filetrace.Tracer
+	untraced []string
+}
+
+// startTracer traces the root and the bind mounts. Other mounts are created
+// by runc so they can't be traced. Only the processes in the cgroup of the
+// container, which is named after its id, are traced so that concurrent
+// steps using the same filesystems are not mixed up. Failing to trace
+// doesn't fail the exec.
+func startTracer(ctx context.Context, id, rootFSPath string, mounts map[string]cache.Mountable) *fileTracer {
+	dirs := map[string]string{"/": rootFSPath}
+	var untraced []string
+	for dest, m := range mounts {
+		if dest == "/" {
+			continue
+		}
+		mnts, err := m.Mount()
+		if err == nil && len(mnts) == 1 && mnts[0].Type == "bind" {
+			dirs[dest] = mnts[0].Source
+		} else {
+			untraced = append(untraced, dest)
+		}
+	}
+	t, err := filetrace.Start(dirs, filetrace.InCgroup(id))
+	if err != nil {
+		warnings.Warn(ctx, warnings.SeverityInfo, "file access could not be traced: %v", err)
+		return nil
+	}
+	return &fileTracer{Tracer: t, untraced: untraced}
+}
+
+func (t *fileTracer) stop(ctx context.Context) {
+	res, err := t.Stop()
+	if err != nil {
+		warnings.Warn(ctx, warnings.SeverityInfo, "file access could not be traced: %v", err)
+		return
+	}
+	for dest, paths := range res {
+		filetrace.Record(ctx, filetrace.Access{Mount: dest, Paths: paths, Traced: true})
+	}
+	for _, dest := range t.untraced {
+		filetrace.Record(ctx, filetrace.Access{Mount: dest})
+	}
+}
+
+// proxy returns the network namespace of the egress proxy for an exec. The
+// proxy is shared by all exec steps of the solve and stopped when the solve
+// ends. Without a scope in the context a proxy is started for the exec only
+// and stopped with release. The namespace is nil if containers have no
+// network.
+func (w *runcworker) proxy(ctx context.Context) (*proxyNamespace, func() error, error) {
+	release := func() error { return nil }
+	s := egressproxy.GetScope(ctx)
+	if s == nil {
+		s = egressproxy.NewScope(w.egressAllow)
+		release = s.Close
+	}
+	if len(s.Allow) == 0 && w.httpCache == nil {
+		return nil, nil, nil
+	}
+	p, err := s.Proxy(func() (io.Closer, error) {
+		// warnings of the shared proxy belong to the solve, not to the step
+		// that started it
+		return w.startProxy(warnings.WithVertex(ctx), s.Allow)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.(*proxyServer).ns, release, nil
+}
+
+// proxyServer is an egress proxy serving in its own network namespace
+type proxyServer struct {
+	ns     *proxyNamespace
+	server *http.Server
+	proxy  *egressproxy.Proxy
+}
+
+func (s *proxyServer) Close() error {
+	err := s.server.Close()
+	// the server doesn't close the hijacked connections of tunnels
+	s.proxy.Close()
+	s.ns.Close()
+	return err
+}
+
+// startProxy serves the egress proxy in a new network namespace. Denied
+// hosts and URLs missing from the replay cache are reported as warnings,
+// once each.
+func (w *runcworker) startProxy(ctx context.Context, allow []string) (*proxyServer, error) {
+	ns, err := newProxyNamespace(proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	reported := make(map[string]struct{})
+	warnOnce := func(key, format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := reported[key]; ok {
+			return
+		}
+		reported[key] = struct{}{}
+		warnings.Warn(ctx, warnings.SeverityWarning, format, args...)
+	}
+	p := egressproxy.New(egressproxy.Opt{
+		Allow: allow,
+		Denied: func(host string) {
+			warnOnce(host, "network access to %s was denied", host)
+		},
+		Cache: w.httpCache,
+		NotRecorded: func(url, reason string) {
+			warnOnce(url, "%s is not served from the download cache: %s", url, reason)
+		},
+	})
+	srv := &http.Server{Handler: p}
+	go srv.Serve(ns.listener)
+	return &proxyServer{ns: ns, server: srv, proxy: p}, nil
+}
+
 type forwardIO struct {
 	stdout, stderr io.WriteCloser
 }
@@ -13,6 +13,8 @@ type Meta struct {
 	User string
 	Cwd  string
 	Tty  bool
+	// Devices are host device paths made available in the container
+	Devices []string
 	// DisableNetworking bool
 }
 